@@ -0,0 +1,251 @@
+package cloudcraft
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// AnnotationKey is the property of the nodes of a blueprint holding their
+// NodeAnnotation, namespaced so it does not clash with the properties set
+// by Cloudcraft.
+const AnnotationKey = "x-cloudcraft-go-annotation"
+
+// DataClassification is the sensitivity of the data handled by a node.
+type DataClassification string
+
+const (
+	ClassificationPublic       DataClassification = "public"
+	ClassificationInternal     DataClassification = "internal"
+	ClassificationConfidential DataClassification = "confidential"
+	ClassificationRestricted   DataClassification = "restricted"
+)
+
+var classificationRanks = map[DataClassification]int{
+	ClassificationPublic:       1,
+	ClassificationInternal:     2,
+	ClassificationConfidential: 3,
+	ClassificationRestricted:   4,
+}
+
+// Valid reports whether c is a known DataClassification.
+func (c DataClassification) Valid() bool {
+	_, ok := classificationRanks[c]
+	return ok
+}
+
+// AtLeast reports whether c is as sensitive as min.
+func (c DataClassification) AtLeast(min DataClassification) bool {
+	return classificationRanks[c] >= classificationRanks[min]
+}
+
+// NodeAnnotation is the compliance metadata of a node of a blueprint, such
+// as the classification of its data and the compliance regimes, e.g. "pci",
+// it is in scope of.
+type NodeAnnotation struct {
+	Classification DataClassification `json:"classification,omitempty"`
+	Scopes         []string           `json:"scopes,omitempty"`
+	Owner          string             `json:"owner,omitempty"`
+	Labels         map[string]string  `json:"labels,omitempty"`
+}
+
+func (d NodeAnnotation) String() string {
+	return Stringify(d)
+}
+
+// InScope reports whether the node is in the compliance scope, compared
+// case-insensitively.
+func (d *NodeAnnotation) InScope(scope string) bool {
+	for _, s := range d.Scopes {
+		if strings.EqualFold(s, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *NodeAnnotation) validate() error {
+	if d.Classification != "" && !d.Classification.Valid() {
+		return NewArgError("annotation.Classification", "is not a known classification")
+	}
+	return nil
+}
+
+// node returns the node of the given id.
+func (d *BlueprintData) node(id string) (map[string]interface{}, bool) {
+	for _, node := range d.Nodes {
+		if node["id"] == id {
+			return node, true
+		}
+	}
+	return nil, false
+}
+
+// Annotate sets the annotation of the node of the given id, replacing its
+// previous annotation.
+func (d *BlueprintData) Annotate(nodeId string, annotation *NodeAnnotation) error {
+	if nodeId == "" {
+		return NewArgError("nodeId", "cannot be empty")
+	}
+	if annotation == nil {
+		return NewArgError("annotation", "cannot be nil")
+	}
+	if err := annotation.validate(); err != nil {
+		return err
+	}
+
+	node, ok := d.node(nodeId)
+	if !ok {
+		return NewArgError("nodeId", "is not a node of the blueprint")
+	}
+
+	// The annotation is stored as decoded JSON, like the rest of the data.
+	raw, err := json.Marshal(annotation)
+	if err != nil {
+		return err
+	}
+	var value map[string]interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return err
+	}
+
+	node[AnnotationKey] = value
+	return nil
+}
+
+// Annotation returns the annotation of the node of the given id, and
+// whether the node is annotated.
+func (d *BlueprintData) Annotation(nodeId string) (*NodeAnnotation, bool) {
+	node, ok := d.node(nodeId)
+	if !ok {
+		return nil, false
+	}
+	return annotationOf(node)
+}
+
+// RemoveAnnotation removes the annotation of the node of the given id. It
+// reports whether the node was annotated.
+func (d *BlueprintData) RemoveAnnotation(nodeId string) bool {
+	node, ok := d.node(nodeId)
+	if !ok {
+		return false
+	}
+	if _, ok := node[AnnotationKey]; !ok {
+		return false
+	}
+
+	delete(node, AnnotationKey)
+	return true
+}
+
+// annotationOf decodes the annotation of node.
+func annotationOf(node map[string]interface{}) (*NodeAnnotation, bool) {
+	value, ok := node[AnnotationKey]
+	if !ok || value == nil {
+		return nil, false
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	var annotation NodeAnnotation
+	if err := json.Unmarshal(raw, &annotation); err != nil {
+		return nil, false
+	}
+	return &annotation, true
+}
+
+// AnnotationQuery selects nodes by their annotations, the empty fields
+// matching any node. Unannotated nodes only match the empty query, or a
+// query with Unannotated set.
+type AnnotationQuery struct {
+	// MinClassification matches the nodes classified at least as sensitive.
+	MinClassification DataClassification
+
+	Scope string
+	Owner string
+
+	// Labels matches the nodes having all the labels.
+	Labels map[string]string
+
+	// Unannotated matches the nodes without annotation only, e.g. to audit
+	// a diagram for nodes nobody classified.
+	Unannotated bool
+}
+
+func (q *AnnotationQuery) matches(annotation *NodeAnnotation) bool {
+	if q.Unannotated {
+		return annotation == nil
+	}
+	if annotation == nil {
+		return q.MinClassification == "" && q.Scope == "" && q.Owner == "" && len(q.Labels) == 0
+	}
+
+	if q.MinClassification != "" && !annotation.Classification.AtLeast(q.MinClassification) {
+		return false
+	}
+	if q.Scope != "" && !annotation.InScope(q.Scope) {
+		return false
+	}
+	if q.Owner != "" && !strings.EqualFold(annotation.Owner, q.Owner) {
+		return false
+	}
+	for k, v := range q.Labels {
+		if annotation.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// AnnotatedNode is a node matched by FindNodes.
+type AnnotatedNode struct {
+	Id         string          `json:"id"`
+	Type       string          `json:"type,omitempty"`
+	Annotation *NodeAnnotation `json:"annotation,omitempty"`
+}
+
+// FindNodes returns the nodes matching query, in the order of the
+// blueprint. A nil query matches every node.
+func (d *BlueprintData) FindNodes(query *AnnotationQuery) []AnnotatedNode {
+	if query == nil {
+		query = &AnnotationQuery{}
+	}
+
+	nodes := []AnnotatedNode{}
+	for _, node := range d.Nodes {
+		annotation, _ := annotationOf(node)
+		if !query.matches(annotation) {
+			continue
+		}
+
+		nodes = append(nodes, AnnotatedNode{
+			Id:         elementString(node, "id"),
+			Type:       elementString(node, "type"),
+			Annotation: annotation,
+		})
+	}
+	return nodes
+}
+
+// ComplianceScopes returns the number of nodes in each compliance scope,
+// keyed by the lower-cased scope.
+func (d *BlueprintData) ComplianceScopes() map[string]int {
+	scopes := make(map[string]int)
+	for _, node := range d.Nodes {
+		annotation, ok := annotationOf(node)
+		if !ok {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, s := range annotation.Scopes {
+			s = strings.ToLower(s)
+			if !seen[s] {
+				seen[s] = true
+				scopes[s]++
+			}
+		}
+	}
+	return scopes
+}
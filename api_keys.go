@@ -0,0 +1,152 @@
+package cloudcraft
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const apiKeyBasePath = "apiKey"
+
+// ApiKeysService is an interface for interfacing with the API key
+// endpoints of the Cloudcraft API. Which operations are permitted depends on
+// the plan and the role of the API key used by the client.
+type ApiKeysService interface {
+	List(context.Context, ...RequestOpt) ([]ApiKey, *Response, error)
+	Create(context.Context, *ApiKeyCreateRequest, ...RequestOpt) (*ApiKey, *Response, error)
+	Rotate(context.Context, string, ...RequestOpt) (*ApiKey, *Response, error)
+	Revoke(context.Context, string, ...RequestOpt) (*Response, error)
+}
+
+// ApiKeysServiceOp handles communication with the API key related methods of
+// the Cloudcraft API.
+type ApiKeysServiceOp struct {
+	client *Client
+}
+
+var _ ApiKeysService = &ApiKeysServiceOp{}
+
+// ApiKey represents a Cloudcraft API key. The secret Key is only returned
+// when the key is created or rotated.
+type ApiKey struct {
+	Id         string    `json:"id,omitempty"`
+	Name       string    `json:"name,omitempty"`
+	Key        string    `json:"key,omitempty"`
+	Role       Role      `json:"role,omitempty"`
+	CreatedAt  Timestamp `json:"createdAt,omitempty"`
+	UpdatedAt  Timestamp `json:"updatedAt,omitempty"`
+	LastUsedAt Timestamp `json:"lastUsedAt,omitempty"`
+	CreatorId  string    `json:"CreatorId,omitempty"`
+}
+
+// Convert ApiKey to a string
+func (d ApiKey) String() string {
+	return Stringify(d)
+}
+
+type ApiKeysRoot struct {
+	ApiKeys []ApiKey `json:"apiKeys"`
+}
+
+// ApiKeyCreateRequest represents a request to create an ApiKey.
+type ApiKeyCreateRequest struct {
+	Name string `json:"name"`
+	Role Role   `json:"role,omitempty"`
+}
+
+func (d ApiKeyCreateRequest) String() string {
+	return Stringify(d)
+}
+
+// List all ApiKeys.
+func (s *ApiKeysServiceOp) List(ctx context.Context, opts ...RequestOpt) ([]ApiKey, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, apiKeyBasePath, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := new(ApiKeysRoot)
+	resp, err := s.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, resp, err
+	}
+	return root.ApiKeys, resp, err
+}
+
+// Create ApiKey
+func (s *ApiKeysServiceOp) Create(ctx context.Context, createRequest *ApiKeyCreateRequest, opts ...RequestOpt) (*ApiKey, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if createRequest == nil {
+		return nil, nil, NewArgError("createRequest", "cannot be nil")
+	}
+
+	if err := ValidateRole("createRequest.Role", createRequest.Role); err != nil {
+		return nil, nil, err
+	}
+
+	path := apiKeyBasePath
+
+	req, err := s.client.NewRequest(ctx, http.MethodPost, path, createRequest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	apiKey := new(ApiKey)
+	resp, err := s.client.Do(ctx, req, apiKey)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return apiKey, resp, err
+}
+
+// Rotate ApiKey, invalidating the current secret and returning a new one.
+func (s *ApiKeysServiceOp) Rotate(ctx context.Context, apiKeyID string, opts ...RequestOpt) (*ApiKey, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if apiKeyID == "" {
+		return nil, nil, NewArgError("apiKeyID", "cannot be empty")
+	}
+
+	path := fmt.Sprintf("%s/%s/rotate", apiKeyBasePath, apiKeyID)
+
+	req, err := s.client.NewRequest(ctx, http.MethodPost, path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	apiKey := new(ApiKey)
+	resp, err := s.client.Do(ctx, req, apiKey)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return apiKey, resp, err
+}
+
+// Revoke ApiKey.
+func (s *ApiKeysServiceOp) Revoke(ctx context.Context, apiKeyID string, opts ...RequestOpt) (*Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if apiKeyID == "" {
+		return nil, NewArgError("apiKeyID", "cannot be empty")
+	}
+
+	path := fmt.Sprintf("%s/%s", apiKeyBasePath, apiKeyID)
+
+	req, err := s.client.NewRequest(ctx, http.MethodDelete, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+
+	return resp, err
+}
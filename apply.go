@@ -0,0 +1,213 @@
+package cloudcraft
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// BlueprintSpec is the desired state of a Blueprint, built with the methods
+// of BlueprintData or decoded from a JSON file, see Blueprints.Apply.
+type BlueprintSpec struct {
+	// Id is the id of the Blueprint. If empty, the Blueprint is the one
+	// named like Data, created if there is none.
+	Id string `json:"id,omitempty"`
+
+	// Data is the whole desired content of the Blueprint: the elements of
+	// the Blueprint missing from Data are removed. An empty grid or link key
+	// leaves those of the Blueprint unchanged.
+	Data *BlueprintData `json:"data"`
+}
+
+func (d BlueprintSpec) String() string {
+	return Stringify(d)
+}
+
+// ApplyAction is what Blueprints.Apply did to converge a Blueprint.
+type ApplyAction string
+
+const (
+	ApplyCreated   ApplyAction = "created"
+	ApplyUpdated   ApplyAction = "updated"
+	ApplyUnchanged ApplyAction = "unchanged"
+)
+
+// ApplyOptions specifies the optional parameters of Blueprints.Apply.
+type ApplyOptions struct {
+	// DryRun reports the changes without making them, like a plan.
+	DryRun bool
+}
+
+// ApplyReport is the outcome of Blueprints.Apply.
+type ApplyReport struct {
+	// BlueprintId is empty for the dry runs of a creation.
+	BlueprintId string            `json:"blueprintId,omitempty"`
+	Action      ApplyAction       `json:"action"`
+	Changes     []BlueprintChange `json:"changes"`
+	DryRun      bool              `json:"dryRun,omitempty"`
+
+	// Blueprint is the converged Blueprint, nil for dry runs.
+	Blueprint *Blueprint `json:"-"`
+}
+
+func (d ApplyReport) String() string {
+	return Stringify(d)
+}
+
+// Apply converges a Blueprint to the desired spec: it creates the Blueprint
+// if it does not exist, or else patches the fields and collections of the
+// Blueprint that differ from the spec, leaving the others untouched. It
+// returns the changes made, none if the Blueprint already matches.
+func (s *BlueprintsServiceOp) Apply(ctx context.Context, desired *BlueprintSpec, opt *ApplyOptions, opts ...RequestOpt) (*ApplyReport, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if desired == nil || desired.Data == nil {
+		return nil, nil, NewArgError("desired.Data", "cannot be nil")
+	}
+	if opt == nil {
+		opt = &ApplyOptions{}
+	}
+
+	// Patch bypasses the naming policy Create and Update check.
+	if err := s.checkName(ctx, desired.Data); err != nil {
+		return nil, nil, err
+	}
+
+	// The data is compared with the data fetched from the API, whose
+	// numbers are all decoded as float64.
+	data, err := normalizeData(desired.Data)
+	if err != nil {
+		return nil, nil, &EncodeError{Op: "applying blueprint " + desired.Id, Err: err}
+	}
+
+	blueprintId := desired.Id
+	if blueprintId == "" {
+		id, resp, err := s.findByName(ctx, data.Name)
+		if err != nil {
+			return nil, resp, err
+		}
+		blueprintId = id
+	}
+
+	if blueprintId == "" {
+		report := &ApplyReport{Action: ApplyCreated, Changes: Diff(nil, data).Changes, DryRun: opt.DryRun}
+		if opt.DryRun {
+			return report, nil, nil
+		}
+
+		blueprint, resp, err := s.Create(ctx, &BlueprintCreateRequest{Data: data})
+		if err != nil {
+			return nil, resp, err
+		}
+		report.BlueprintId = blueprint.Id
+		report.Blueprint = blueprint
+		return report, resp, nil
+	}
+
+	current, resp, err := s.Get(ctx, blueprintId)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	changes, patch := planApply(current.Data, data)
+	report := &ApplyReport{BlueprintId: blueprintId, Action: ApplyUpdated, Changes: changes, DryRun: opt.DryRun}
+	if len(changes) == 0 {
+		report.Action = ApplyUnchanged
+		if !opt.DryRun {
+			report.Blueprint = current
+		}
+		return report, resp, nil
+	}
+	if opt.DryRun {
+		return report, resp, nil
+	}
+
+	doc, err := json.Marshal(patch)
+	if err != nil {
+		return nil, nil, &EncodeError{Op: "applying blueprint " + blueprintId, Err: err}
+	}
+
+	blueprint, resp, err := s.Patch(ctx, blueprintId, doc)
+	if err != nil {
+		return nil, resp, err
+	}
+	report.Blueprint = blueprint
+	return report, resp, nil
+}
+
+// findByName returns the id of the Blueprint named name, empty if there is
+// none.
+func (s *BlueprintsServiceOp) findByName(ctx context.Context, name string) (string, *Response, error) {
+	if name == "" {
+		return "", nil, NewArgError("desired.Data.Name", "cannot be empty without desired.Id")
+	}
+
+	blueprints, resp, err := s.List(ctx)
+	if err != nil {
+		return "", resp, err
+	}
+
+	var ids []string
+	for _, blueprint := range blueprints {
+		if blueprint.Name == name {
+			ids = append(ids, blueprint.Id)
+		}
+	}
+	if len(ids) > 1 {
+		return "", resp, NewArgError("desired.Id", fmt.Sprintf("must be set, %d blueprints are named %q", len(ids), name))
+	}
+	if len(ids) == 0 {
+		return "", resp, nil
+	}
+	return ids[0], resp, nil
+}
+
+// normalizeData returns a copy of data round-tripped through JSON, holding
+// the same types as the data decoded from the API.
+func normalizeData(data *BlueprintData) (*BlueprintData, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := &BlueprintData{}
+	if err := json.Unmarshal(b, normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// planApply returns the changes turning current into desired, and the merge
+// patch replacing the top-level fields and the collections that change.
+func planApply(current, desired *BlueprintData) ([]BlueprintChange, map[string]interface{}) {
+	if current == nil {
+		current = &BlueprintData{}
+	}
+
+	// Fields left empty in the spec are kept.
+	target := *desired
+	if target.Grid == "" {
+		target.Grid = current.Grid
+	}
+	if target.LinkKey == "" {
+		target.LinkKey = current.LinkKey
+	}
+
+	changes := Diff(current, &target).Changes
+	patch := make(map[string]interface{})
+	for _, change := range changes {
+		if change.Collection == "" {
+			patch[change.Field] = change.New
+			continue
+		}
+
+		// Merge patches replace arrays whole; null removes them.
+		if elements := collectionOf(&target, change.Collection); len(elements) > 0 {
+			patch[change.Collection] = elements
+		} else {
+			patch[change.Collection] = nil
+		}
+	}
+	return changes, patch
+}
@@ -0,0 +1,32 @@
+package cloudcraft
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPlanApplyUnchanged(t *testing.T) {
+	desired := &BlueprintData{Name: "web"}
+	if _, err := desired.AddText(&Text{Id: "t1", Text: "Title", TextSize: 24, MapPos: At(1, 2)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The current data is decoded from the API, with float64 numbers.
+	var current BlueprintData
+	b, err := json.Marshal(desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &current); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := normalizeData(desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, patch := planApply(&current, data)
+	if len(changes) != 0 || len(patch) != 0 {
+		t.Errorf("planApply() = %v, %v, want no changes", changes, patch)
+	}
+}
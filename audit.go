@@ -0,0 +1,89 @@
+package cloudcraft
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+const auditBasePath = "audit"
+
+// AuditService is an interface for interfacing with the organization audit
+// log endpoints of the Cloudcraft API
+type AuditService interface {
+	List(context.Context, *AuditListOptions, ...RequestOpt) ([]AuditEvent, *Response, error)
+}
+
+// AuditServiceOp handles communication with the audit log related methods of
+// the Cloudcraft API.
+type AuditServiceOp struct {
+	client *Client
+}
+
+var _ AuditService = &AuditServiceOp{}
+
+// AuditEvent represents an entry of the Cloudcraft organization audit log,
+// e.g. a blueprint being exported, edited or deleted.
+type AuditEvent struct {
+	Id           string                 `json:"id,omitempty"`
+	Action       string                 `json:"action,omitempty"`
+	ActorId      string                 `json:"actorId,omitempty"`
+	ResourceType string                 `json:"resourceType,omitempty"`
+	ResourceId   string                 `json:"resourceId,omitempty"`
+	Details      map[string]interface{} `json:"details,omitempty"`
+	CreatedAt    Timestamp              `json:"createdAt,omitempty"`
+}
+
+// Convert AuditEvent to a string
+func (d AuditEvent) String() string {
+	return Stringify(d)
+}
+
+type AuditEventsRoot struct {
+	Events []AuditEvent `json:"events"`
+	Meta   *Meta        `json:"meta,omitempty"`
+}
+
+// AuditListOptions specifies the optional parameters to AuditService.List.
+// Since and Until restrict the events to a time range.
+type AuditListOptions struct {
+	ListOptions
+
+	Since time.Time
+	Until time.Time
+
+	// Restrict events to an action, e.g. "blueprint.export".
+	Action string
+
+	// Restrict events to a user.
+	ActorId string
+}
+
+// List the audit events of the organization.
+func (s *AuditServiceOp) List(ctx context.Context, opt *AuditListOptions, opts ...RequestOpt) ([]AuditEvent, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if opt != nil && !opt.Since.IsZero() && !opt.Until.IsZero() && opt.Until.Before(opt.Since) {
+		return nil, nil, NewArgError("opt.Until", "cannot be before opt.Since")
+	}
+
+	path, err := addQuery(auditBasePath, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := new(AuditEventsRoot)
+	resp, err := s.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, resp, err
+	}
+	resp.Meta = root.Meta
+
+	return root.Events, resp, err
+}
@@ -4,8 +4,8 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/http"
-	"time"
 )
 
 const awsAccountBasePath = "aws/account"
@@ -14,13 +14,15 @@ const awsAccountBasePath = "aws/account"
 // endpoints of the Cloudcraft API
 // See: https://developers.cloudcraft.co/#dbc3d135-6447-47f2-b043-bae65b722246
 type AwsAccountsService interface {
-	List(context.Context) ([]AwsAccount, *Response, error)
-	Get(context.Context, string) (*AwsAccount, *Response, error)
-	Create(context.Context, *AwsAccountCreateOrUpdateRequest) (*AwsAccount, *Response, error)
-	Update(context.Context, string, *AwsAccountCreateOrUpdateRequest) (*AwsAccount, *Response, error)
-	Delete(context.Context, string) (*Response, error)
-	Snapshot(context.Context, string, *AwsAccountSnapshotRequest) (*AwsAccountSnapshot, *Response, error)
-	IamParameters(context.Context) (*AwsAccountIamParameters, *Response, error)
+	List(context.Context, ...RequestOpt) ([]AwsAccount, *Response, error)
+	Get(context.Context, string, ...RequestOpt) (*AwsAccount, *Response, error)
+	Create(context.Context, *AwsAccountCreateOrUpdateRequest, ...RequestOpt) (*AwsAccount, *Response, error)
+	Update(context.Context, string, *AwsAccountCreateOrUpdateRequest, ...RequestOpt) (*AwsAccount, *Response, error)
+	Delete(context.Context, string, ...RequestOpt) (*Response, error)
+	TransferOwnership(context.Context, string, *OwnershipTransferRequest, ...RequestOpt) (*AwsAccount, *Response, error)
+	Snapshot(context.Context, string, *AwsAccountSnapshotRequest, ...RequestOpt) (*AwsAccountSnapshot, *Response, error)
+	SnapshotTo(context.Context, string, *AwsAccountSnapshotRequest, io.WriterAt, *DownloadOptions, ...RequestOpt) (int64, *Response, error)
+	IamParameters(context.Context, ...RequestOpt) (*AwsAccountIamParameters, *Response, error)
 }
 
 // AwsAccountsServiceOp handles communication with the AwsAccount related methods of the
@@ -32,8 +34,8 @@ type AwsAccountsServiceOp struct {
 var _ AwsAccountsService = &AwsAccountsServiceOp{}
 
 type AwsAccountDataTextMapPos struct {
-	RelTo  string `json:"relTo,omitempty"`
-	offset []int  `json:"offset,omitempty"`
+	RelTo  string    `json:"relTo,omitempty"`
+	Offset []float64 `json:"offset,omitempty"`
 }
 
 type AwsAccountDataText struct {
@@ -74,28 +76,28 @@ type AwsAccountData struct {
 
 // AwsAccount represents a Cloudcraft AwsAccount
 type AwsAccount struct {
-	CreatedAt  time.Time `json:"createdAt,omitempty"`
+	CreatedAt  Timestamp `json:"createdAt,omitempty"`
 	CreatorId  string    `json:"CreatorId,omitempty"`
 	ExternalId string    `json:"externalId"`
 	Id         string    `json:"id,omitempty"`
 	Name       string    `json:"name,omitempty"`
 	RoleArn    string    `json:"roleArn,omitempty"`
-	UpdatedAt  time.Time `json:"createdAt,omitempty"`
+	UpdatedAt  Timestamp `json:"updatedAt,omitempty"`
 }
 
 type AwsAccountSnapshotParameters struct {
-	Autoconnect bool     `url:"autoconnect,omitempty"`
-	Exclude     []string `url:"exclude,omitempty,comma"`
-	Filter      string   `url:"filter,omitempty"`
-	Grid        bool     `url:"grid,omitempty"`
-	Height      int      `url:"height,omitempty"`
-	Label       bool     `url:"label,omitempty"`
-	Landscape   bool     `url:"landscape,omitempty"`
-	PaperSize   string   `url:"paperSize,omitempty"`
-	Projection  string   `url:"projection,omitempty"`
-	Scale       float32  `url:"scale,omitempty"`
-	Transparent bool     `url:"transparent,omitempty"`
-	Width       int      `url:"width,omitempty"`
+	Autoconnect bool
+	Exclude     []string
+	Filter      string
+	Grid        bool
+	Height      int
+	Label       bool
+	Landscape   bool
+	PaperSize   string
+	Projection  string
+	Scale       float32
+	Transparent bool
+	Width       int
 }
 
 type AwsAccountSnapshot struct {
@@ -104,6 +106,14 @@ type AwsAccountSnapshot struct {
 	SnapshotParameters *AwsAccountSnapshotParameters
 }
 
+// Write implements io.Writer so the raw snapshot is stored in Content.
+func (s *AwsAccountSnapshot) Write(p []byte) (int, error) {
+	if s.Content == nil {
+		s.Content = new(bytes.Buffer)
+	}
+	return s.Content.Write(p)
+}
+
 // Convert AwsAccount to a string
 func (d AwsAccount) String() string {
 	return Stringify(d)
@@ -126,6 +136,9 @@ type AwsAccountSnapshotRequest struct {
 	Format             string
 	Region             string
 	SnapshotParameters *AwsAccountSnapshotParameters
+
+	// Optional function reporting the progress of the download.
+	Progress ProgressFunc
 }
 
 func (d AwsAccountSnapshotRequest) String() string {
@@ -143,7 +156,10 @@ func (d AwsAccountIamParameters) String() string {
 }
 
 // List all AwsAccounts.
-func (s *AwsAccountsServiceOp) List(ctx context.Context) ([]AwsAccount, *Response, error) {
+func (s *AwsAccountsServiceOp) List(ctx context.Context, opts ...RequestOpt) ([]AwsAccount, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
 	req, err := s.client.NewRequest(ctx, http.MethodGet, awsAccountBasePath, nil)
 	if err != nil {
 		return nil, nil, err
@@ -158,7 +174,10 @@ func (s *AwsAccountsServiceOp) List(ctx context.Context) ([]AwsAccount, *Respons
 }
 
 // Get individual AwsAccount.
-func (s *AwsAccountsServiceOp) Get(ctx context.Context, awsAccountID string) (*AwsAccount, *Response, error) {
+func (s *AwsAccountsServiceOp) Get(ctx context.Context, awsAccountID string, opts ...RequestOpt) (*AwsAccount, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
 	if awsAccountID == "" {
 		return nil, nil, NewArgError("awsAccountID", "cannot be empty")
 	}
@@ -180,7 +199,10 @@ func (s *AwsAccountsServiceOp) Get(ctx context.Context, awsAccountID string) (*A
 }
 
 // Create AwsAccount
-func (s *AwsAccountsServiceOp) Create(ctx context.Context, createRequest *AwsAccountCreateOrUpdateRequest) (*AwsAccount, *Response, error) {
+func (s *AwsAccountsServiceOp) Create(ctx context.Context, createRequest *AwsAccountCreateOrUpdateRequest, opts ...RequestOpt) (*AwsAccount, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
 	if createRequest == nil {
 		return nil, nil, NewArgError("createRequest", "cannot be nil")
 	}
@@ -202,7 +224,10 @@ func (s *AwsAccountsServiceOp) Create(ctx context.Context, createRequest *AwsAcc
 }
 
 // Update AwsAccount
-func (s *AwsAccountsServiceOp) Update(ctx context.Context, awsAccountID string, updateRequest *AwsAccountCreateOrUpdateRequest) (*AwsAccount, *Response, error) {
+func (s *AwsAccountsServiceOp) Update(ctx context.Context, awsAccountID string, updateRequest *AwsAccountCreateOrUpdateRequest, opts ...RequestOpt) (*AwsAccount, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
 	if awsAccountID == "" {
 		return nil, nil, NewArgError("awsAccountID", "cannot be empty")
 	}
@@ -228,7 +253,10 @@ func (s *AwsAccountsServiceOp) Update(ctx context.Context, awsAccountID string,
 }
 
 // Delete AwsAccount.
-func (s *AwsAccountsServiceOp) Delete(ctx context.Context, awsAccountID string) (*Response, error) {
+func (s *AwsAccountsServiceOp) Delete(ctx context.Context, awsAccountID string, opts ...RequestOpt) (*Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
 	if awsAccountID == "" {
 		return nil, NewArgError("awsAccountID", "cannot be empty")
 	}
@@ -245,36 +273,91 @@ func (s *AwsAccountsServiceOp) Delete(ctx context.Context, awsAccountID string)
 	return resp, err
 }
 
-// valid PaperSizes: "Letter", "Legal", "Tabloid", "Ledger", "A0", "A1", "A2", "A3", "A4", "A5"
-// Format: One of "json", "svg", "png", "pdf", "mxGraph"
+// TransferOwnership reassigns the owner of an AwsAccount to another user or team.
+func (s *AwsAccountsServiceOp) TransferOwnership(ctx context.Context, awsAccountID string, transferRequest *OwnershipTransferRequest, opts ...RequestOpt) (*AwsAccount, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
 
-// Snapshot AwsAccount.
-func (s *AwsAccountsServiceOp) Snapshot(ctx context.Context, awsAccountID string, snapshotRequest *AwsAccountSnapshotRequest) (*AwsAccountSnapshot, *Response, error) {
 	if awsAccountID == "" {
 		return nil, nil, NewArgError("awsAccountID", "cannot be empty")
 	}
 
-	path, err := addOptions(fmt.Sprintf("%s/%s/%s/%s", awsAccountBasePath, awsAccountID, snapshotRequest.Region, snapshotRequest.Format), snapshotRequest.SnapshotParameters)
+	if err := transferRequest.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	path := fmt.Sprintf("%s/%s/owner", awsAccountBasePath, awsAccountID)
+
+	req, err := s.client.NewRequest(ctx, http.MethodPut, path, transferRequest)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	req, err := s.client.NewRequest(ctx, http.MethodGet, path, nil)
+	awsAccount := new(AwsAccount)
+	resp, err := s.client.Do(ctx, req, awsAccount)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return awsAccount, resp, err
+}
+
+// valid PaperSizes: "Letter", "Legal", "Tabloid", "Ledger", "A0", "A1", "A2", "A3", "A4", "A5"
+// Format: One of "json", "svg", "png", "pdf", "mxGraph"
+
+// Snapshot AwsAccount.
+func (s *AwsAccountsServiceOp) Snapshot(ctx context.Context, awsAccountID string, snapshotRequest *AwsAccountSnapshotRequest, opts ...RequestOpt) (*AwsAccountSnapshot, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	req, err := s.newSnapshotRequest(ctx, awsAccountID, snapshotRequest)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	awsAccountSnapshot := new(AwsAccountSnapshot)
-	resp, err := s.client.Do(ctx, req, awsAccountSnapshot)
+	awsAccountSnapshot := &AwsAccountSnapshot{SnapshotParameters: snapshotRequest.SnapshotParameters}
+	resp, err := s.client.Do(ctx, req, withProgress(awsAccountSnapshot, snapshotRequest.Progress))
 	if err != nil {
 		return nil, resp, err
 	}
+	awsAccountSnapshot.ContentType = resp.Header.Get("Content-Type")
 
 	return awsAccountSnapshot, resp, err
 }
 
+// newSnapshotRequest creates the request snapshotting an AwsAccount.
+func (s *AwsAccountsServiceOp) newSnapshotRequest(ctx context.Context, awsAccountID string, snapshotRequest *AwsAccountSnapshotRequest) (*http.Request, error) {
+	if awsAccountID == "" {
+		return nil, NewArgError("awsAccountID", "cannot be empty")
+	}
+
+	if snapshotRequest == nil {
+		return nil, NewArgError("snapshotRequest", "cannot be nil")
+	}
+
+	if err := snapshotRequest.validate(); err != nil {
+		return nil, err
+	}
+
+	path, err := addQuery(fmt.Sprintf("%s/%s/%s/%s", awsAccountBasePath, awsAccountID, snapshotRequest.Region, snapshotRequest.Format), snapshotRequest.SnapshotParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", formatMediaType(snapshotRequest.Format))
+
+	return req, nil
+}
+
 // Get AwsAccount IAM Parameters.
-func (s *AwsAccountsServiceOp) IamParameters(ctx context.Context) (*AwsAccountIamParameters, *Response, error) {
+func (s *AwsAccountsServiceOp) IamParameters(ctx context.Context, opts ...RequestOpt) (*AwsAccountIamParameters, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
 	path := fmt.Sprintf("%s/iamParameters", awsAccountBasePath)
 
 	req, err := s.client.NewRequest(ctx, http.MethodGet, path, nil)
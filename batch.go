@@ -0,0 +1,264 @@
+package cloudcraft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBatchConcurrency = 4
+	defaultBatchRetryWait   = time.Second
+)
+
+// BatchOperation is an operation of a Batch, typically a closure calling the
+// API. It must honor ctx.
+type BatchOperation func(ctx context.Context) error
+
+// BatchOptions specifies the optional parameters of a Batch.
+type BatchOptions struct {
+	// Number of operations run at the same time, 4 if zero.
+	Concurrency int
+
+	// Operations started per second, unlimited if zero. Burst operations
+	// may start at once, 1 if zero.
+	RateLimit float64
+	Burst     int
+
+	// Number of times an operation failing with a 429, a 5xx status or a
+	// network error is run again.
+	Retries int
+}
+
+// Batch runs operations with bounded concurrency under a shared rate limit.
+// A 429 response pauses all of its operations for the delay the API asks
+// for. It is the building block of bulk workflows such as BatchService.
+type Batch struct {
+	opt     BatchOptions
+	limiter *batchLimiter
+
+	mu  sync.Mutex
+	ops []batchItem
+}
+
+type batchItem struct {
+	name string
+	op   BatchOperation
+}
+
+// NewBatch returns an empty Batch.
+func NewBatch(opt *BatchOptions) *Batch {
+	b := &Batch{}
+	if opt != nil {
+		b.opt = *opt
+	}
+	if b.opt.Concurrency <= 0 {
+		b.opt.Concurrency = defaultBatchConcurrency
+	}
+	b.limiter = newBatchLimiter(b.opt.RateLimit, b.opt.Burst)
+	return b
+}
+
+// Add queues op under name, used to report its failure.
+func (b *Batch) Add(name string, op BatchOperation) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.ops = append(b.ops, batchItem{name: name, op: op})
+}
+
+// Run runs the queued operations and empties the queue. It waits for all of
+// them and returns a *BatchError listing the failed ones, or nil. A done ctx
+// fails the operations not started yet.
+func (b *Batch) Run(ctx context.Context) error {
+	b.mu.Lock()
+	ops := b.ops
+	b.ops = nil
+	b.mu.Unlock()
+
+	var (
+		mu     sync.Mutex
+		failed []*BatchItemError
+		wg     sync.WaitGroup
+	)
+	work := make(chan batchItem)
+	for i := 0; i < b.opt.Concurrency && i < len(ops); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range work {
+				if err := b.run(ctx, item.op); err != nil {
+					mu.Lock()
+					failed = append(failed, &BatchItemError{Name: item.name, Err: err})
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, item := range ops {
+		work <- item
+	}
+	close(work)
+	wg.Wait()
+
+	if len(failed) == 0 {
+		return nil
+	}
+	return &BatchError{Errors: failed}
+}
+
+// run runs op, retrying it as configured.
+func (b *Batch) run(ctx context.Context, op BatchOperation) error {
+	for attempt := 0; ; attempt++ {
+		if err := b.limiter.wait(ctx); err != nil {
+			return err
+		}
+
+		err := op(ctx)
+		if err == nil {
+			return nil
+		}
+
+		var errResp *ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusTooManyRequests {
+			b.limiter.pause(retryAfter(errResp.Response, defaultBatchRetryWait<<uint(attempt)))
+		}
+
+		if attempt >= b.opt.Retries || ctx.Err() != nil || !isRetryableError(err) {
+			return err
+		}
+
+		timer := time.NewTimer(defaultBatchRetryWait << uint(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// BatchItemError is the failure of an operation of a Batch.
+type BatchItemError struct {
+	Name string
+	Err  error
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Name, e.Err)
+}
+
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError lists the failed operations of a Batch, in no particular order.
+type BatchError struct {
+	Errors []*BatchItemError
+}
+
+func (e *BatchError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("cloudcraft: %d operations failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// isRetryableError reports whether an operation failing with err may
+// succeed if run again.
+func isRetryableError(err error) bool {
+	var errResp *ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil {
+		return isRetryableFailure(errResp.Response, nil)
+	}
+
+	var transportErr *TransportError
+	return errors.As(err, &transportErr)
+}
+
+// retryAfter returns the delay asked for by the Retry-After header of resp,
+// or def.
+func retryAfter(resp *http.Response, def time.Duration) time.Duration {
+	if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		return d
+	}
+	return def
+}
+
+// batchLimiter is a token bucket shared by the operations of a Batch, which
+// can be paused as a whole.
+type batchLimiter struct {
+	mu       sync.Mutex
+	rate     float64
+	burst    float64
+	tokens   float64
+	last     time.Time
+	resumeAt time.Time
+}
+
+func newBatchLimiter(rate float64, burst int) *batchLimiter {
+	if burst <= 0 {
+		burst = 1
+	}
+	return &batchLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// wait blocks until an operation may start.
+func (l *batchLimiter) wait(ctx context.Context) error {
+	for {
+		delay := l.reserve()
+		if delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token and returns zero, or returns how long to wait
+// before trying again.
+func (l *batchLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(l.resumeAt) {
+		return l.resumeAt.Sub(now)
+	}
+	if l.rate <= 0 {
+		return 0
+	}
+
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// pause stops operations from starting for d.
+func (l *batchLimiter) pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if resumeAt := time.Now().Add(d); resumeAt.After(l.resumeAt) {
+		l.resumeAt = resumeAt
+	}
+}
@@ -0,0 +1,134 @@
+package cloudcraft
+
+import "context"
+
+// BatchService runs common fleet operations across many resources, one Batch
+// operation per resource.
+type BatchService interface {
+	ExportBlueprints(context.Context, func(Blueprint) bool, *BlueprintExportRequest, *BatchOptions, ...RequestOpt) ([]BlueprintExportResult, error)
+	SnapshotAwsAccounts(context.Context, func(AwsAccount) bool, []string, *AwsAccountSnapshotRequest, *BatchOptions, ...RequestOpt) ([]AwsAccountSnapshotResult, error)
+}
+
+// BatchServiceOp implements BatchService on top of the other services of the
+// client.
+type BatchServiceOp struct {
+	client *Client
+}
+
+var _ BatchService = &BatchServiceOp{}
+
+// BlueprintExportResult is the outcome of the export of a Blueprint by
+// BatchService.ExportBlueprints. Exactly one of Image and Err is set.
+type BlueprintExportResult struct {
+	Blueprint Blueprint
+	Image     *BlueprintImage
+	Err       error
+}
+
+// AwsAccountSnapshotResult is the outcome of the snapshot of a region of an
+// AwsAccount by BatchService.SnapshotAwsAccounts. Exactly one of Snapshot and
+// Err is set.
+type AwsAccountSnapshotResult struct {
+	AwsAccount AwsAccount
+	Region     string
+	Snapshot   *AwsAccountSnapshot
+	Err        error
+}
+
+// ExportBlueprints exports every Blueprint accepted by filter, or every
+// Blueprint if filter is nil. The results are in the order of
+// Blueprints.List. The returned error is only set when listing fails.
+func (s *BatchServiceOp) ExportBlueprints(ctx context.Context, filter func(Blueprint) bool, exportRequest *BlueprintExportRequest, opt *BatchOptions, opts ...RequestOpt) ([]BlueprintExportResult, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if exportRequest == nil {
+		return nil, NewArgError("exportRequest", "cannot be nil")
+	}
+
+	blueprints, _, err := s.client.Blueprints.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []BlueprintExportResult
+	for _, blueprint := range blueprints {
+		if filter == nil || filter(blueprint) {
+			results = append(results, BlueprintExportResult{Blueprint: blueprint})
+		}
+	}
+
+	batch := NewBatch(opt)
+	for i := range results {
+		result := &results[i]
+		batch.Add(result.Blueprint.Id, func(ctx context.Context) error {
+			image, _, err := s.client.Blueprints.Export(ctx, result.Blueprint.Id, exportRequest)
+			result.Image, result.Err = image, err
+			return err
+		})
+	}
+	_ = batch.Run(ctx)
+
+	// Operations not started by a cancelled batch set no result.
+	for i := range results {
+		if results[i].Image == nil && results[i].Err == nil {
+			results[i].Err = ctx.Err()
+		}
+	}
+
+	return results, nil
+}
+
+// SnapshotAwsAccounts snapshots every region of regions of every AwsAccount
+// accepted by filter, or of every AwsAccount if filter is nil. The Region of
+// snapshotRequest is ignored. The results are in the order of
+// AwsAccounts.List, then of regions. The returned error is only set when
+// listing fails.
+func (s *BatchServiceOp) SnapshotAwsAccounts(ctx context.Context, filter func(AwsAccount) bool, regions []string, snapshotRequest *AwsAccountSnapshotRequest, opt *BatchOptions, opts ...RequestOpt) ([]AwsAccountSnapshotResult, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if len(regions) == 0 {
+		return nil, NewArgError("regions", "cannot be empty")
+	}
+
+	if snapshotRequest == nil {
+		return nil, NewArgError("snapshotRequest", "cannot be nil")
+	}
+
+	accounts, _, err := s.client.AwsAccounts.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []AwsAccountSnapshotResult
+	for _, account := range accounts {
+		if filter != nil && !filter(account) {
+			continue
+		}
+		for _, region := range regions {
+			results = append(results, AwsAccountSnapshotResult{AwsAccount: account, Region: region})
+		}
+	}
+
+	batch := NewBatch(opt)
+	for i := range results {
+		result := &results[i]
+		request := *snapshotRequest
+		request.Region = result.Region
+		batch.Add(result.AwsAccount.Id+"/"+result.Region, func(ctx context.Context) error {
+			snapshot, _, err := s.client.AwsAccounts.Snapshot(ctx, result.AwsAccount.Id, &request)
+			result.Snapshot, result.Err = snapshot, err
+			return err
+		})
+	}
+	_ = batch.Run(ctx)
+
+	for i := range results {
+		if results[i].Snapshot == nil && results[i].Err == nil {
+			results[i].Err = ctx.Err()
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,240 @@
+package cloudcraft
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CachedBlueprint is a Blueprint response stored by a BlueprintCache along
+// with the validators used to revalidate it.
+type CachedBlueprint struct {
+	ETag         string          `json:"etag,omitempty"`
+	LastModified string          `json:"lastModified,omitempty"`
+	Body         json.RawMessage `json:"body"`
+
+	// StoredAt is the time the Blueprint was received, zero if unknown.
+	StoredAt time.Time `json:"storedAt,omitempty"`
+}
+
+// BlueprintCache stores the Blueprints fetched by Blueprints.Get, keyed by
+// id. Cached Blueprints are revalidated with a conditional GET, so repeated
+// gets of an unchanged Blueprint cost no transfer of its content.
+//
+// The client deletes the entry of a Blueprint it updates, patches, transfers
+// or deletes. Entries may also be deleted explicitly, e.g. when a webhook
+// notifies a change. Get returns a nil entry for ids not cached. Errors of
+// the cache never fail requests, they are treated as cache misses.
+type BlueprintCache interface {
+	Get(id string) (*CachedBlueprint, error)
+	Put(id string, entry *CachedBlueprint) error
+	Delete(id string) error
+}
+
+// SetBlueprintCache is a client option caching the Blueprints fetched with
+// Blueprints.Get in cache.
+func SetBlueprintCache(cache BlueprintCache) ClientOpt {
+	return func(c *Client) error {
+		if cache == nil {
+			return NewArgError("cache", "cannot be nil")
+		}
+
+		c.blueprintCache = cache
+		return nil
+	}
+}
+
+// getCachedBlueprint gets a Blueprint through the blueprint cache of the
+// client.
+func (s *BlueprintsServiceOp) getCachedBlueprint(ctx context.Context, req *http.Request, blueprintId string) (*Blueprint, *Response, error) {
+	cache := s.client.blueprintCache
+
+	entry, err := cache.Get(blueprintId)
+	if err != nil {
+		entry = nil
+	}
+	if entry != nil {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	body := new(bytes.Buffer)
+	resp, err := s.client.Do(ctx, req, body)
+	if entry != nil && resp != nil && resp.StatusCode == http.StatusNotModified {
+		blueprint := new(Blueprint)
+		if err := s.client.codec.Decode(bytes.NewReader(entry.Body), blueprint); err != nil {
+			return nil, resp, &DecodeError{Op: "decoding cached blueprint " + blueprintId, Err: err}
+		}
+
+		revalidated := *entry
+		revalidated.StoredAt = time.Now().UTC()
+		_ = cache.Put(blueprintId, &revalidated)
+		return blueprint, resp, nil
+	}
+
+	// During an outage, the cached Blueprint is returned stale, see
+	// SetStaleOnError.
+	if err != nil && entry != nil && s.client.staleCache != nil && degradesGracefully(ctx, err) && !s.client.tooOld(entry.StoredAt) {
+		blueprint := new(Blueprint)
+		if s.client.codec.Decode(bytes.NewReader(entry.Body), blueprint) == nil {
+			stale := &Staleness{StoredAt: entry.StoredAt, Err: err}
+			return blueprint, staleResponse(req, http.StatusOK, mediaType, len(entry.Body), stale), nil
+		}
+	}
+	if err != nil {
+		return nil, resp, err
+	}
+
+	blueprint := new(Blueprint)
+	if err := s.client.codec.Decode(bytes.NewReader(body.Bytes()), blueprint); err != nil {
+		return nil, resp, &DecodeError{Op: requestOp(req), Err: err}
+	}
+
+	entry = &CachedBlueprint{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Body:         body.Bytes(),
+		StoredAt:     time.Now().UTC(),
+	}
+	if entry.ETag != "" || entry.LastModified != "" {
+		_ = cache.Put(blueprintId, entry)
+	} else {
+		_ = cache.Delete(blueprintId)
+	}
+
+	return blueprint, resp, nil
+}
+
+// invalidateBlueprint deletes the cached Blueprint of the given id.
+func (c *Client) invalidateBlueprint(blueprintId string) {
+	if c.blueprintCache != nil {
+		_ = c.blueprintCache.Delete(blueprintId)
+	}
+}
+
+// MemoryBlueprintCache is a BlueprintCache held in memory. It is safe for
+// concurrent use.
+type MemoryBlueprintCache struct {
+	mu      sync.RWMutex
+	entries map[string]*CachedBlueprint
+}
+
+var _ BlueprintCache = &MemoryBlueprintCache{}
+
+// NewMemoryBlueprintCache returns an empty MemoryBlueprintCache.
+func NewMemoryBlueprintCache() *MemoryBlueprintCache {
+	return &MemoryBlueprintCache{entries: make(map[string]*CachedBlueprint)}
+}
+
+// Get implements BlueprintCache.
+func (m *MemoryBlueprintCache) Get(id string) (*CachedBlueprint, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.entries[id], nil
+}
+
+// Put implements BlueprintCache.
+func (m *MemoryBlueprintCache) Put(id string, entry *CachedBlueprint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[id] = entry
+	return nil
+}
+
+// Delete implements BlueprintCache.
+func (m *MemoryBlueprintCache) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, id)
+	return nil
+}
+
+// DirBlueprintCache is a BlueprintCache storing one JSON file per Blueprint
+// in a directory, so the cache outlives the process.
+type DirBlueprintCache struct {
+	dir string
+}
+
+var _ BlueprintCache = &DirBlueprintCache{}
+
+// NewDirBlueprintCache returns a DirBlueprintCache storing its files in dir,
+// which is created if needed.
+func NewDirBlueprintCache(dir string) (*DirBlueprintCache, error) {
+	if dir == "" {
+		return nil, NewArgError("dir", "cannot be empty")
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("cloudcraft: creating cache directory: %w", err)
+	}
+	return &DirBlueprintCache{dir: dir}, nil
+}
+
+func (d *DirBlueprintCache) path(id string) string {
+	return filepath.Join(d.dir, url.PathEscape(id)+".json")
+}
+
+// Get implements BlueprintCache.
+func (d *DirBlueprintCache) Get(id string) (*CachedBlueprint, error) {
+	content, err := ioutil.ReadFile(d.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entry := new(CachedBlueprint)
+	if err := json.Unmarshal(content, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Put implements BlueprintCache. The file is replaced atomically.
+func (d *DirBlueprintCache) Put(id string, entry *CachedBlueprint) error {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(d.dir, ".blueprint-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), d.path(id))
+}
+
+// Delete implements BlueprintCache.
+func (d *DirBlueprintCache) Delete(id string) error {
+	err := os.Remove(d.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
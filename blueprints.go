@@ -3,8 +3,11 @@ package cloudcraft
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -14,12 +17,23 @@ const blueprintBasePath = "blueprint"
 // endpoints of the Cloudcraft API
 // See: https://developers.cloudcraft.co/#dbc3d135-6447-47f2-b043-bae65b722246
 type BlueprintsService interface {
-	List(context.Context) ([]Blueprint, *Response, error)
-	Get(context.Context, string) (*Blueprint, *Response, error)
-	Create(context.Context, *BlueprintCreateRequest) (*Blueprint, *Response, error)
-	Update(context.Context, string, *BlueprintUpdateRequest) (*Blueprint, *Response, error)
-	Delete(context.Context, string) (*Response, error)
-	Export(context.Context, string, *BlueprintExportRequest) (*BlueprintImage, *Response, error)
+	List(context.Context, ...RequestOpt) ([]Blueprint, *Response, error)
+	Get(context.Context, string, ...RequestOpt) (*Blueprint, *Response, error)
+	Create(context.Context, *BlueprintCreateRequest, ...RequestOpt) (*Blueprint, *Response, error)
+	Update(context.Context, string, *BlueprintUpdateRequest, ...RequestOpt) (*Blueprint, *Response, error)
+	Patch(context.Context, string, []byte, ...RequestOpt) (*Blueprint, *Response, error)
+	Delete(context.Context, string, ...RequestOpt) (*Response, error)
+	TransferOwnership(context.Context, string, *OwnershipTransferRequest, ...RequestOpt) (*Blueprint, *Response, error)
+	AttachImage(context.Context, string, *ImageAsset, ...RequestOpt) (*Blueprint, *Response, error)
+	Export(context.Context, string, *BlueprintExportRequest, ...RequestOpt) (*BlueprintImage, *Response, error)
+	ExportTo(context.Context, string, *BlueprintExportRequest, io.WriterAt, *DownloadOptions, ...RequestOpt) (int64, *Response, error)
+	ExportMxGraph(context.Context, string, *BlueprintExportParameters, ...RequestOpt) (*MxGraphModel, *Response, error)
+	Budget(context.Context, string, *BlueprintBudgetRequest, ...RequestOpt) (*BlueprintBudget, *Response, error)
+	BudgetWait(context.Context, string, *BlueprintBudgetRequest, *BudgetWaitOptions, ...RequestOpt) (*BlueprintBudget, *Response, error)
+	Watch(context.Context, string, time.Duration, ...RequestOpt) (<-chan BlueprintWatchEvent, error)
+	Summary(context.Context, string, ...RequestOpt) (*BlueprintSummary, *Response, error)
+	ScanNames(context.Context, *NamingPolicy, func(Blueprint) []string, ...RequestOpt) ([]NamingViolation, *Response, error)
+	Apply(context.Context, *BlueprintSpec, *ApplyOptions, ...RequestOpt) (*ApplyReport, *Response, error)
 }
 
 // BlueprintsServiceOp handles communication with the Blueprint related methods of the
@@ -49,21 +63,27 @@ type BlueprintData struct {
 type Blueprint struct {
 	Id         string         `json:"id,omitempty"`
 	Name       string         `json:"name,omitempty"`
-	CreatedAt  time.Time      `json:"createdAt,omitempty"`
-	UpdatedAt  time.Time      `json:"createdAt,omitempty"`
+	CreatedAt  Timestamp      `json:"createdAt,omitempty"`
+	UpdatedAt  Timestamp      `json:"updatedAt,omitempty"`
 	CreatorId  string         `json:"CreatorId,omitempty"`
 	LastUserId string         `json:"LastUserId,omitempty"`
-	Data       *BlueprintData `json:data,omitempty`
+	Data       *BlueprintData `json:"data,omitempty"`
 }
 
 type BlueprintExportParameters struct {
-	Grid        bool    `url:"grid"`
-	Height      int     `url:"height"`
-	Landscape   bool    `url:"landscape"`
-	PaperSize   string  `url:"paperSize"`
-	Scale       float32 `url:"scale"`
-	Transparent bool    `url:"transparent"`
-	Width       int     `url:"width"`
+	Grid        bool
+	Height      int
+	Landscape   bool
+	PaperSize   string
+	Scale       float32
+	Transparent bool
+	Width       int
+
+	// Appearance of the export, the defaults of the blueprint if empty.
+	Theme      ExportTheme
+	Background string // hex color such as "#ffffff"
+	Projection Projection
+	Label      bool
 }
 
 type BlueprintImage struct {
@@ -72,6 +92,34 @@ type BlueprintImage struct {
 	ExportParameters *BlueprintExportParameters
 }
 
+// Write implements io.Writer so the raw export is stored in Content.
+func (i *BlueprintImage) Write(p []byte) (int, error) {
+	if i.Content == nil {
+		i.Content = new(bytes.Buffer)
+	}
+	return i.Content.Write(p)
+}
+
+type BlueprintBudgetParameters struct {
+	Currency string
+	Period   string
+	Rate     string
+}
+
+type BlueprintBudget struct {
+	ContentType      string
+	Content          *bytes.Buffer
+	BudgetParameters *BlueprintBudgetParameters
+}
+
+// Write implements io.Writer so the raw budget is stored in Content.
+func (b *BlueprintBudget) Write(p []byte) (int, error) {
+	if b.Content == nil {
+		b.Content = new(bytes.Buffer)
+	}
+	return b.Content.Write(p)
+}
+
 // Convert Blueprint to a string
 func (d Blueprint) String() string {
 	return Stringify(d)
@@ -102,14 +150,34 @@ func (d BlueprintUpdateRequest) String() string {
 type BlueprintExportRequest struct {
 	Format           string
 	ExportParameters *BlueprintExportParameters
+
+	// Optional function reporting the progress of the download.
+	Progress ProgressFunc
+
+	// Optional document information set on PDF exports.
+	PDFMetadata *PDFMetadata
 }
 
 func (d BlueprintExportRequest) String() string {
 	return Stringify(d)
 }
 
+// BlueprintBudgetRequest represents a request to export the budget of a
+// Blueprint. Format is one of "csv" or "xlsx".
+type BlueprintBudgetRequest struct {
+	Format           string
+	BudgetParameters *BlueprintBudgetParameters
+}
+
+func (d BlueprintBudgetRequest) String() string {
+	return Stringify(d)
+}
+
 // List all Blueprints.
-func (s *BlueprintsServiceOp) List(ctx context.Context) ([]Blueprint, *Response, error) {
+func (s *BlueprintsServiceOp) List(ctx context.Context, opts ...RequestOpt) ([]Blueprint, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
 	req, err := s.client.NewRequest(ctx, http.MethodGet, blueprintBasePath, nil)
 	if err != nil {
 		return nil, nil, err
@@ -124,7 +192,10 @@ func (s *BlueprintsServiceOp) List(ctx context.Context) ([]Blueprint, *Response,
 }
 
 // Get individual Blueprint.
-func (s *BlueprintsServiceOp) Get(ctx context.Context, blueprintId string) (*Blueprint, *Response, error) {
+func (s *BlueprintsServiceOp) Get(ctx context.Context, blueprintId string, opts ...RequestOpt) (*Blueprint, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
 	if blueprintId == "" {
 		return nil, nil, NewArgError("blueprintId", "cannot be empty")
 	}
@@ -136,6 +207,10 @@ func (s *BlueprintsServiceOp) Get(ctx context.Context, blueprintId string) (*Blu
 		return nil, nil, err
 	}
 
+	if s.client.blueprintCache != nil {
+		return s.getCachedBlueprint(ctx, req, blueprintId)
+	}
+
 	blueprint := new(Blueprint)
 	resp, err := s.client.Do(ctx, req, blueprint)
 	if err != nil {
@@ -146,11 +221,18 @@ func (s *BlueprintsServiceOp) Get(ctx context.Context, blueprintId string) (*Blu
 }
 
 // Create Blueprint
-func (s *BlueprintsServiceOp) Create(ctx context.Context, createRequest *BlueprintCreateRequest) (*Blueprint, *Response, error) {
+func (s *BlueprintsServiceOp) Create(ctx context.Context, createRequest *BlueprintCreateRequest, opts ...RequestOpt) (*Blueprint, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
 	if createRequest == nil {
 		return nil, nil, NewArgError("createRequest", "cannot be nil")
 	}
 
+	if err := s.checkName(ctx, createRequest.Data); err != nil {
+		return nil, nil, err
+	}
+
 	path := blueprintBasePath
 
 	req, err := s.client.NewRequest(ctx, http.MethodPost, path, createRequest)
@@ -168,7 +250,10 @@ func (s *BlueprintsServiceOp) Create(ctx context.Context, createRequest *Bluepri
 }
 
 // Update Blueprint
-func (s *BlueprintsServiceOp) Update(ctx context.Context, blueprintId string, updateRequest *BlueprintUpdateRequest) (*Blueprint, *Response, error) {
+func (s *BlueprintsServiceOp) Update(ctx context.Context, blueprintId string, updateRequest *BlueprintUpdateRequest, opts ...RequestOpt) (*Blueprint, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
 	if blueprintId == "" {
 		return nil, nil, NewArgError("blueprintId", "cannot be empty")
 	}
@@ -177,15 +262,28 @@ func (s *BlueprintsServiceOp) Update(ctx context.Context, blueprintId string, up
 		return nil, nil, NewArgError("updateRequest", "cannot be nil")
 	}
 
-	path := blueprintBasePath
+	if updateRequest.Data != nil {
+		if err := s.checkName(ctx, updateRequest.Data); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return s.update(ctx, blueprintId, updateRequest)
+}
+
+// update sends body, the data of the Blueprint, as its update to
+// blueprint/{id}.
+func (s *BlueprintsServiceOp) update(ctx context.Context, blueprintId string, body interface{}) (*Blueprint, *Response, error) {
+	path := fmt.Sprintf("%s/%s", blueprintBasePath, blueprintId)
 
-	req, err := s.client.NewRequest(ctx, http.MethodPut, path, updateRequest)
+	req, err := s.client.NewRequest(ctx, http.MethodPut, path, body)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	blueprint := new(Blueprint)
 	resp, err := s.client.Do(ctx, req, blueprint)
+	s.client.invalidateBlueprint(blueprintId)
 	if err != nil {
 		return nil, resp, err
 	}
@@ -193,8 +291,86 @@ func (s *BlueprintsServiceOp) Update(ctx context.Context, blueprintId string, up
 	return blueprint, resp, err
 }
 
+// Patch Blueprint data with an RFC 7386 JSON merge patch. The current
+// Blueprint is fetched, the patch is applied to its JSON data, keeping the
+// keys BlueprintData does not model, and the result is sent back as an
+// update, conditional on the Blueprint being unchanged since
+// it was fetched: with If-Match and its ETag, or else If-Unmodified-Since and
+// its updatedAt, which cannot tell changes within the same second apart. An
+// update rejected by the API because of a concurrent change returns an
+// error matched by IsConflict; the API may also ignore the precondition, in
+// which case a concurrent change is overwritten.
+func (s *BlueprintsServiceOp) Patch(ctx context.Context, blueprintId string, patch []byte, opts ...RequestOpt) (*Blueprint, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if blueprintId == "" {
+		return nil, nil, NewArgError("blueprintId", "cannot be empty")
+	}
+
+	if len(patch) == 0 {
+		return nil, nil, NewArgError("patch", "cannot be empty")
+	}
+
+	return s.patch(ctx, blueprintId, func(json.RawMessage) ([]byte, error) {
+		return patch, nil
+	})
+}
+
+// patch fetches Blueprint blueprintId and sends its data back merged with
+// the patch mkPatch returns for the current data, under the preconditions
+// of Patch.
+func (s *BlueprintsServiceOp) patch(ctx context.Context, blueprintId string, mkPatch func(current json.RawMessage) ([]byte, error)) (*Blueprint, *Response, error) {
+	path := fmt.Sprintf("%s/%s", blueprintBasePath, blueprintId)
+	req, err := s.client.NewRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var current struct {
+		UpdatedAt Timestamp       `json:"updatedAt,omitempty"`
+		Data      json.RawMessage `json:"data,omitempty"`
+	}
+	resp, err := s.client.Do(ctx, req, &current)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	patch, err := mkPatch(current.Data)
+	if err != nil {
+		return nil, nil, err
+	}
+	patched, err := MergePatch(current.Data, patch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data := new(BlueprintData)
+	if err := json.Unmarshal(patched, data); err != nil {
+		return nil, nil, &DecodeError{Op: "patching blueprint " + blueprintId, Err: err}
+	}
+	if err := s.checkName(ctx, data); err != nil {
+		return nil, nil, err
+	}
+
+	// If-Match compares ETags strongly, weak ones never match.
+	var preconditions []RequestOpt
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		preconditions = append(preconditions, Header("If-Match", etag))
+	} else if !current.UpdatedAt.IsZero() {
+		preconditions = append(preconditions, Header("If-Unmodified-Since", current.UpdatedAt.UTC().Format(http.TimeFormat)))
+	}
+
+	return s.update(WithRequestOpts(ctx, preconditions...), blueprintId, &struct {
+		Data json.RawMessage `json:"data"`
+	}{Data: patched})
+}
+
 // Delete Blueprint.
-func (s *BlueprintsServiceOp) Delete(ctx context.Context, blueprintId string) (*Response, error) {
+func (s *BlueprintsServiceOp) Delete(ctx context.Context, blueprintId string, opts ...RequestOpt) (*Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
 	if blueprintId == "" {
 		return nil, NewArgError("blueprintId", "cannot be empty")
 	}
@@ -207,20 +383,131 @@ func (s *BlueprintsServiceOp) Delete(ctx context.Context, blueprintId string) (*
 	}
 
 	resp, err := s.client.Do(ctx, req, nil)
+	s.client.invalidateBlueprint(blueprintId)
 
 	return resp, err
 }
 
+// TransferOwnership reassigns the owner of a Blueprint to another user or team.
+func (s *BlueprintsServiceOp) TransferOwnership(ctx context.Context, blueprintId string, transferRequest *OwnershipTransferRequest, opts ...RequestOpt) (*Blueprint, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if blueprintId == "" {
+		return nil, nil, NewArgError("blueprintId", "cannot be empty")
+	}
+
+	if err := transferRequest.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	path := fmt.Sprintf("%s/%s/owner", blueprintBasePath, blueprintId)
+
+	req, err := s.client.NewRequest(ctx, http.MethodPut, path, transferRequest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blueprint := new(Blueprint)
+	resp, err := s.client.Do(ctx, req, blueprint)
+	s.client.invalidateBlueprint(blueprintId)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return blueprint, resp, err
+}
+
 // var validPaperSizes = []string{"Letter", "Legal", "Tabloid", "Ledger", "A0", "A1", "A2", "A3", "A4", "A5"}
 
 // imageMediaType = "image/svg+xml, image/png, application/pdf, application/xml, application/json"
 // Export Blueprint.
-func (s *BlueprintsServiceOp) Export(ctx context.Context, blueprintId string, exportRequest *BlueprintExportRequest) (*BlueprintImage, *Response, error) {
+func (s *BlueprintsServiceOp) Export(ctx context.Context, blueprintId string, exportRequest *BlueprintExportRequest, opts ...RequestOpt) (*BlueprintImage, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if s.client.exportCache != nil {
+		return s.exportCached(ctx, blueprintId, exportRequest)
+	}
+
+	return s.export(ctx, blueprintId, exportRequest)
+}
+
+// export exports a Blueprint without going through the export cache.
+func (s *BlueprintsServiceOp) export(ctx context.Context, blueprintId string, exportRequest *BlueprintExportRequest) (*BlueprintImage, *Response, error) {
+	req, err := s.newExportRequest(ctx, blueprintId, exportRequest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blueprintImage := &BlueprintImage{ExportParameters: exportRequest.ExportParameters}
+	resp, err := s.client.Do(ctx, req, withProgress(blueprintImage, exportRequest.Progress))
+	if err != nil {
+		return nil, resp, err
+	}
+	blueprintImage.ContentType = resp.Header.Get("Content-Type")
+
+	if exportRequest.PDFMetadata != nil && exportRequest.Format == string(ExportFormatPdf) {
+		var content []byte
+		if blueprintImage.Content != nil {
+			content = blueprintImage.Content.Bytes()
+		}
+		pdf, err := SetPDFMetadata(content, exportRequest.PDFMetadata)
+		if err != nil {
+			return nil, resp, err
+		}
+		blueprintImage.Content = bytes.NewBuffer(pdf)
+	}
+
+	return blueprintImage, resp, err
+}
+
+// newExportRequest creates the request exporting a Blueprint.
+func (s *BlueprintsServiceOp) newExportRequest(ctx context.Context, blueprintId string, exportRequest *BlueprintExportRequest) (*http.Request, error) {
+	if blueprintId == "" {
+		return nil, NewArgError("blueprintId", "cannot be empty")
+	}
+
+	if exportRequest == nil {
+		return nil, NewArgError("exportRequest", "cannot be nil")
+	}
+
+	if err := exportRequest.validate(); err != nil {
+		return nil, err
+	}
+
+	path, err := addQuery(fmt.Sprintf("%s/%s/%s", blueprintBasePath, blueprintId, exportRequest.Format), exportRequest.ExportParameters)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", formatMediaType(exportRequest.Format))
+
+	return req, nil
+}
+
+// Budget exports the budget of a Blueprint.
+func (s *BlueprintsServiceOp) Budget(ctx context.Context, blueprintId string, budgetRequest *BlueprintBudgetRequest, opts ...RequestOpt) (*BlueprintBudget, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
 	if blueprintId == "" {
 		return nil, nil, NewArgError("blueprintId", "cannot be empty")
 	}
 
-	path, err := addOptions(fmt.Sprintf("%s/%s/%s", blueprintBasePath, blueprintId, exportRequest.Format), exportRequest.ExportParameters)
+	if budgetRequest == nil {
+		return nil, nil, NewArgError("budgetRequest", "cannot be nil")
+	}
+
+	if err := budgetRequest.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	path, err := addQuery(fmt.Sprintf("%s/%s/budget/%s", blueprintBasePath, blueprintId, budgetRequest.Format), budgetRequest.BudgetParameters)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -229,12 +516,14 @@ func (s *BlueprintsServiceOp) Export(ctx context.Context, blueprintId string, ex
 	if err != nil {
 		return nil, nil, err
 	}
+	req.Header.Set("Accept", formatMediaType(budgetRequest.Format))
 
-	blueprintImage := new(BlueprintImage)
-	resp, err := s.client.Do(ctx, req, blueprintImage)
+	blueprintBudget := &BlueprintBudget{BudgetParameters: budgetRequest.BudgetParameters}
+	resp, err := s.client.Do(ctx, req, blueprintBudget)
 	if err != nil {
 		return nil, resp, err
 	}
+	blueprintBudget.ContentType = resp.Header.Get("Content-Type")
 
-	return blueprintImage, resp, err
+	return blueprintBudget, resp, err
 }
@@ -0,0 +1,178 @@
+package cloudcraft
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBlueprintsPatchPrecondition(t *testing.T) {
+	tests := []struct {
+		name   string
+		etag   string
+		header string
+		want   string
+	}{
+		{"etag", `"v1"`, "If-Match", `"v1"`},
+		{"weak etag", `W/"v1"`, "If-Unmodified-Since", "Thu, 04 Mar 2021 05:06:07 GMT"},
+		{"no etag", "", "If-Unmodified-Since", "Thu, 04 Mar 2021 05:06:07 GMT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", mediaType)
+				switch r.Method {
+				case http.MethodGet:
+					if tt.etag != "" {
+						w.Header().Set("ETag", tt.etag)
+					}
+					w.Write([]byte(`{"id": "b", "updatedAt": "2021-03-04T05:06:07Z", "data": {"name": "web"}}`))
+				case http.MethodPut:
+					if got := r.Header.Get(tt.header); got != tt.want {
+						t.Errorf("%s = %q, want %q", tt.header, got, tt.want)
+					}
+					w.WriteHeader(http.StatusPreconditionFailed)
+					w.Write([]byte(`{"error": "modified", "code": 412}`))
+				}
+			}))
+			defer server.Close()
+
+			client, err := New(nil, SetBaseURL(server.URL+"/"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, _, err = client.Blueprints.Patch(context.Background(), "b", []byte(`{"name": "api"}`), Retry(NoRetry))
+			if !IsConflict(err) {
+				t.Errorf("Patch() error = %v, want a conflict", err)
+			}
+		})
+	}
+}
+
+func TestBlueprintsPatchKeepsUnknownKeys(t *testing.T) {
+	var put map[string]map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", mediaType)
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"id": "b", "data": {"name": "web", "version": 4, "theme": {"base": "dark"}}}`))
+		case http.MethodPut:
+			if err := json.NewDecoder(r.Body).Decode(&put); err != nil {
+				t.Error(err)
+			}
+			w.Write([]byte(`{"id": "b", "data": {"name": "api"}}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(nil, SetBaseURL(server.URL+"/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blueprint, _, err := client.Blueprints.Patch(context.Background(), "b", []byte(`{"name": "api", "layout": {"grid": "infinite"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blueprint.Data.Name != "api" {
+		t.Errorf("Patch() name = %q, want api", blueprint.Data.Name)
+	}
+
+	want := map[string]interface{}{
+		"name":    "api",
+		"version": float64(4),
+		"theme":   map[string]interface{}{"base": "dark"},
+		"layout":  map[string]interface{}{"grid": "infinite"},
+	}
+	if !reflect.DeepEqual(put["data"], want) {
+		t.Errorf("PUT data = %v, want %v", put["data"], want)
+	}
+}
+
+func TestBlueprintsAttachImage(t *testing.T) {
+	var (
+		put     map[string]map[string]interface{}
+		ifMatch string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", mediaType)
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte(`{"id": "b", "data": {"name": "web", "version": 4, "images": [{"id": "old", "type": "image", "url": "https://example.com/a.png", "mapPos": [0, 0]}]}}`))
+		case http.MethodPut:
+			ifMatch = r.Header.Get("If-Match")
+			if err := json.NewDecoder(r.Body).Decode(&put); err != nil {
+				t.Error(err)
+			}
+			w.Write([]byte(`{"id": "b", "data": {"name": "web"}}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := New(nil, SetBaseURL(server.URL+"/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	image := &ImageAsset{Id: "new", URL: "https://example.com/b.png", MapPos: [2]float64{2, 3}}
+	if _, _, err := client.Blueprints.AttachImage(context.Background(), "b", image); err != nil {
+		t.Fatal(err)
+	}
+
+	if ifMatch != `"v1"` {
+		t.Errorf("If-Match = %q, want %q", ifMatch, `"v1"`)
+	}
+	want := map[string]interface{}{
+		"name":    "web",
+		"version": float64(4),
+		"images": []interface{}{
+			map[string]interface{}{"id": "old", "type": "image", "url": "https://example.com/a.png", "mapPos": []interface{}{float64(0), float64(0)}},
+			map[string]interface{}{"id": "new", "type": "image", "url": "https://example.com/b.png", "mapPos": []interface{}{float64(2), float64(3)}},
+		},
+	}
+	if !reflect.DeepEqual(put["data"], want) {
+		t.Errorf("PUT data = %v, want %v", put["data"], want)
+	}
+}
+
+func TestBlueprintsBudgetWaitClampsRetryAfter(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		if polls == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write([]byte("service,cost\n"))
+	}))
+	defer server.Close()
+
+	client, err := New(nil, SetBaseURL(server.URL+"/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var progress []BudgetProgress
+	opt := &BudgetWaitOptions{
+		MaxInterval: 10 * time.Millisecond,
+		Progress:    func(p BudgetProgress) { progress = append(progress, p) },
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, _, err := client.Blueprints.BudgetWait(ctx, "b", &BlueprintBudgetRequest{Format: "csv"}, opt); err != nil {
+		t.Fatal(err)
+	}
+	if len(progress) != 1 || !progress[0].RetryAfter || progress[0].Next != opt.MaxInterval {
+		t.Errorf("BudgetWait() progress = %v, want one Retry-After wait of %v", progress, opt.MaxInterval)
+	}
+}
@@ -0,0 +1,150 @@
+// Package budget parses the budgets exported by the Cloudcraft API and builds
+// cost reports from them.
+package budget
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/updater/cloudcraft-go"
+)
+
+// Item is a line of a budget, the cost of one component of a blueprint.
+type Item struct {
+	Service  string  `json:"service"`
+	Resource string  `json:"resource"`
+	Region   string  `json:"region,omitempty"`
+	Cost     float64 `json:"cost"`
+}
+
+// Budget is the parsed budget of a blueprint.
+type Budget struct {
+	Currency string  `json:"currency,omitempty"`
+	Period   string  `json:"period,omitempty"`
+	Items    []Item  `json:"items"`
+	Total    float64 `json:"total"`
+}
+
+// columnAliases maps the Item fields to the budget CSV headers they are read
+// from, compared case-insensitively.
+var columnAliases = map[string][]string{
+	"service":  {"service", "category", "group"},
+	"resource": {"resource", "component", "name", "id"},
+	"region":   {"region"},
+	"cost":     {"cost", "total cost", "price", "total"},
+}
+
+// Parse reads a budget exported in CSV format. The currency and period the
+// budget was requested with are recorded as is.
+func Parse(r io.Reader, currency, period string) (*Budget, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("budget: reading header: %w", err)
+	}
+
+	columns := mapColumns(header)
+	if _, ok := columns["cost"]; !ok {
+		return nil, fmt.Errorf("budget: no cost column in header %q", strings.Join(header, ","))
+	}
+
+	b := &Budget{Currency: currency, Period: period, Items: []Item{}}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("budget: line %d: %w", line, err)
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		costField := field("cost")
+		if costField == "" {
+			continue
+		}
+
+		cost, err := parseAmount(costField)
+		if err != nil {
+			return nil, fmt.Errorf("budget: line %d: %w", line, err)
+		}
+
+		item := Item{Service: field("service"), Resource: field("resource"), Region: field("region"), Cost: cost}
+
+		// Budgets end with a summary line which is recomputed instead.
+		if strings.EqualFold(item.Service, "total") || strings.EqualFold(item.Resource, "total") {
+			continue
+		}
+
+		b.Items = append(b.Items, item)
+		b.Total += cost
+	}
+
+	return b, nil
+}
+
+func mapColumns(header []string) map[string]int {
+	columns := make(map[string]int)
+	for field, aliases := range columnAliases {
+		for _, alias := range aliases {
+			for i, h := range header {
+				if _, ok := columns[field]; !ok && strings.EqualFold(strings.TrimSpace(h), alias) {
+					columns[field] = i
+				}
+			}
+		}
+	}
+	return columns
+}
+
+// parseAmount parses a cost such as "$1,234.56" or "1234.56 USD".
+func parseAmount(s string) (float64, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || r == '.' || r == '-' {
+			return r
+		}
+		return -1
+	}, s)
+
+	v, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return v, nil
+}
+
+// Fetch exports the budget of a blueprint in CSV format and parses it.
+func Fetch(ctx context.Context, client *cloudcraft.Client, blueprintID string, params *cloudcraft.BlueprintBudgetParameters) (*Budget, error) {
+	budget, _, err := client.Blueprints.Budget(ctx, blueprintID, &cloudcraft.BlueprintBudgetRequest{
+		Format:           "csv",
+		BudgetParameters: params,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var currency, period string
+	if params != nil {
+		currency, period = params.Currency, params.Period
+	}
+
+	if budget.Content == nil {
+		return &Budget{Currency: currency, Period: period, Items: []Item{}}, nil
+	}
+
+	return Parse(budget.Content, currency, period)
+}
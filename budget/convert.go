@@ -0,0 +1,165 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// RateProvider provides the exchange rates of currencies, such as the
+// rates published by a finance team or an exchange rate API.
+type RateProvider interface {
+	// Rate returns the amount of currency to one unit of currency from.
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
+
+// RateProviderFunc is a function implementing RateProvider.
+type RateProviderFunc func(ctx context.Context, from, to string) (float64, error)
+
+// Rate calls f.
+func (f RateProviderFunc) Rate(ctx context.Context, from, to string) (float64, error) {
+	return f(ctx, from, to)
+}
+
+// StaticRates is a RateProvider of fixed rates, given as the amount of each
+// currency to one unit of a common base currency, e.g.
+// StaticRates{"USD": 1, "EUR": 0.92}.
+type StaticRates map[string]float64
+
+// Rate returns the rate of from to to, derived from their rates to the base.
+func (r StaticRates) Rate(_ context.Context, from, to string) (float64, error) {
+	f, ok := r[strings.ToUpper(from)]
+	if !ok || f <= 0 {
+		return 0, fmt.Errorf("budget: no rate for currency %q", from)
+	}
+	t, ok := r[strings.ToUpper(to)]
+	if !ok || t <= 0 {
+		return 0, fmt.Errorf("budget: no rate for currency %q", to)
+	}
+	return t / f, nil
+}
+
+// Rounding is the rounding rule of converted costs.
+type Rounding int
+
+const (
+	// RoundNone keeps costs as computed.
+	RoundNone Rounding = iota
+
+	// RoundHalfUp rounds half away from zero, e.g. 0.125 to 0.13.
+	RoundHalfUp
+
+	// RoundHalfEven rounds half to the even digit, e.g. 0.125 to 0.12, as
+	// used by accounting.
+	RoundHalfEven
+
+	// RoundUp rounds towards positive infinity.
+	RoundUp
+
+	// RoundDown rounds towards negative infinity.
+	RoundDown
+)
+
+// round rounds v to places decimals according to r.
+func (r Rounding) round(v float64, places int) float64 {
+	if r == RoundNone {
+		return v
+	}
+
+	scale := math.Pow(10, float64(places))
+	switch r {
+	case RoundHalfUp:
+		return math.Round(v*scale) / scale
+	case RoundHalfEven:
+		return math.RoundToEven(v*scale) / scale
+	case RoundUp:
+		return math.Ceil(v*scale) / scale
+	case RoundDown:
+		return math.Floor(v*scale) / scale
+	default:
+		return v
+	}
+}
+
+// Multiplier scales the cost of the items it matches, e.g. by 1.2 for a
+// 20% tax or markup. Empty fields match any item, matching is
+// case-insensitive.
+type Multiplier struct {
+	Service  string  `json:"service,omitempty"`
+	Resource string  `json:"resource,omitempty"`
+	Region   string  `json:"region,omitempty"`
+	Factor   float64 `json:"factor"`
+}
+
+func (m Multiplier) matches(item Item) bool {
+	return (m.Service == "" || strings.EqualFold(m.Service, item.Service)) &&
+		(m.Resource == "" || strings.EqualFold(m.Resource, item.Resource)) &&
+		(m.Region == "" || strings.EqualFold(m.Region, item.Region))
+}
+
+// ConvertOptions configures Convert.
+type ConvertOptions struct {
+	// Currency the costs are converted to. The costs are kept in the
+	// currency of the budget if empty or the same.
+	Currency string
+
+	// Rates provides the exchange rate from the currency of the budget to
+	// Currency. It is required when they differ.
+	Rates RateProvider
+
+	// Multipliers are applied to the items they match after conversion,
+	// in order, several multipliers matching an item compounding.
+	Multipliers []Multiplier
+
+	// Rounding is applied to the cost of each item, to Places decimals. The
+	// total is the sum of the rounded costs, as on an invoice.
+	Rounding Rounding
+	Places   int
+}
+
+// Convert returns a copy of b with its costs converted to the currency of
+// opts, scaled by the multipliers of opts and rounded.
+func Convert(ctx context.Context, b *Budget, opts *ConvertOptions) (*Budget, error) {
+	if opts == nil {
+		opts = &ConvertOptions{}
+	}
+	if opts.Places < 0 {
+		return nil, fmt.Errorf("budget: negative rounding places %d", opts.Places)
+	}
+
+	rate := 1.0
+	currency := b.Currency
+	if opts.Currency != "" && !strings.EqualFold(opts.Currency, b.Currency) {
+		if b.Currency == "" {
+			return nil, fmt.Errorf("budget: cannot convert a budget of unknown currency to %s", opts.Currency)
+		}
+		if opts.Rates == nil {
+			return nil, fmt.Errorf("budget: no rate provider to convert %s to %s", b.Currency, opts.Currency)
+		}
+
+		var err error
+		rate, err = opts.Rates.Rate(ctx, b.Currency, opts.Currency)
+		if err != nil {
+			return nil, err
+		}
+		currency = opts.Currency
+	}
+
+	converted := &Budget{Currency: currency, Period: b.Period, Items: make([]Item, 0, len(b.Items))}
+	for _, item := range b.Items {
+		item.Cost *= rate
+		for _, m := range opts.Multipliers {
+			if m.matches(item) {
+				item.Cost *= m.Factor
+			}
+		}
+		item.Cost = opts.Rounding.round(item.Cost, opts.Places)
+
+		converted.Items = append(converted.Items, item)
+		converted.Total += item.Cost
+	}
+	converted.Total = opts.Rounding.round(converted.Total, opts.Places)
+
+	return converted, nil
+}
@@ -0,0 +1,179 @@
+package budget
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// DiffOptions configures Compare. A change is reported when it reaches both
+// thresholds, zero thresholds reporting every change.
+type DiffOptions struct {
+	// Threshold is the minimum absolute change reported, in the currency of
+	// the budgets.
+	Threshold float64
+
+	// PercentThreshold is the minimum change reported relative to the
+	// previous cost, e.g. 10 for 10%. Added and removed costs always reach
+	// it.
+	PercentThreshold float64
+}
+
+// Delta is the change of a cost between two budgets. Resource and Region are
+// empty for the deltas of services.
+type Delta struct {
+	Service  string  `json:"service"`
+	Resource string  `json:"resource,omitempty"`
+	Region   string  `json:"region,omitempty"`
+	Old      float64 `json:"old"`
+	New      float64 `json:"new"`
+	Change   float64 `json:"change"`
+
+	// Percent is the change relative to Old, zero when Old is zero.
+	Percent float64 `json:"percent"`
+
+	Added   bool `json:"added,omitempty"`
+	Removed bool `json:"removed,omitempty"`
+}
+
+// Diff is the comparison of two budgets, such as the budgets of a blueprint
+// before and after a design change.
+type Diff struct {
+	Currency  string  `json:"currency,omitempty"`
+	Resources []Delta `json:"resources"`
+	Services  []Delta `json:"services"`
+	Total     Delta   `json:"total"`
+}
+
+// Increased reports whether any reported resource or service got more
+// expensive, e.g. to fail a CI job on cost regressions.
+func (d *Diff) Increased() bool {
+	for _, deltas := range [][]Delta{d.Resources, d.Services} {
+		for _, delta := range deltas {
+			if delta.Change > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Compare compares the budgets before and after, which must be in the same
+// currency, and reports the per-resource and per-service changes reaching
+// the thresholds of opts, largest first. The total change is always
+// reported.
+func Compare(before, after *Budget, opts *DiffOptions) (*Diff, error) {
+	if opts == nil {
+		opts = &DiffOptions{}
+	}
+
+	if before.Currency != "" && after.Currency != "" && !strings.EqualFold(before.Currency, after.Currency) {
+		return nil, fmt.Errorf("budget: cannot compare a budget in %s to one in %s, see Convert", before.Currency, after.Currency)
+	}
+
+	currency := after.Currency
+	if currency == "" {
+		currency = before.Currency
+	}
+
+	type key struct{ service, resource, region string }
+	resources := make(map[key]*Delta)
+	services := make(map[string]*Delta)
+
+	add := func(item Item, cost func(*Delta) *float64) {
+		k := key{item.Service, item.Resource, item.Region}
+		d, ok := resources[k]
+		if !ok {
+			d = &Delta{Service: item.Service, Resource: item.Resource, Region: item.Region}
+			resources[k] = d
+		}
+		*cost(d) += item.Cost
+
+		s, ok := services[item.Service]
+		if !ok {
+			s = &Delta{Service: item.Service}
+			services[item.Service] = s
+		}
+		*cost(s) += item.Cost
+	}
+	for _, item := range before.Items {
+		add(item, func(d *Delta) *float64 { return &d.Old })
+	}
+	for _, item := range after.Items {
+		add(item, func(d *Delta) *float64 { return &d.New })
+	}
+
+	diff := &Diff{Currency: currency, Resources: []Delta{}, Services: []Delta{}}
+	for _, d := range resources {
+		if d.compute(opts) {
+			diff.Resources = append(diff.Resources, *d)
+		}
+	}
+	for _, d := range services {
+		if d.compute(opts) {
+			diff.Services = append(diff.Services, *d)
+		}
+	}
+	sortDeltas(diff.Resources)
+	sortDeltas(diff.Services)
+
+	diff.Total = Delta{Old: before.Total, New: after.Total}
+	diff.Total.compute(opts)
+
+	return diff, nil
+}
+
+// compute fills the change of d from its costs and reports whether it
+// reaches the thresholds of opts.
+func (d *Delta) compute(opts *DiffOptions) bool {
+	d.Change = d.New - d.Old
+	d.Added = d.Old == 0 && d.New != 0
+	d.Removed = d.Old != 0 && d.New == 0
+	if d.Old != 0 {
+		d.Percent = d.Change / math.Abs(d.Old) * 100
+	}
+
+	if d.Change == 0 || math.Abs(d.Change) < opts.Threshold {
+		return false
+	}
+	return d.Old == 0 || math.Abs(d.Percent) >= opts.PercentThreshold
+}
+
+func sortDeltas(deltas []Delta) {
+	sort.Slice(deltas, func(i, j int) bool {
+		a, b := deltas[i], deltas[j]
+		if math.Abs(a.Change) != math.Abs(b.Change) {
+			return math.Abs(a.Change) > math.Abs(b.Change)
+		}
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		return a.Region < b.Region
+	})
+}
+
+// WriteCSV writes the reported resource changes as CSV, one line per
+// resource.
+func (d *Diff) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write([]string{"service", "resource", "region", "old", "new", "change", "percent", "currency"}); err != nil {
+		return err
+	}
+	for _, delta := range d.Resources {
+		record := []string{delta.Service, delta.Resource, delta.Region,
+			formatAmount(delta.Old), formatAmount(delta.New), formatAmount(delta.Change), formatAmount(delta.Percent), d.Currency}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
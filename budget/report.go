@@ -0,0 +1,174 @@
+package budget
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/updater/cloudcraft-go"
+)
+
+// Source is a blueprint to include in a Report. Labels such as "team" or
+// "environment" are used to aggregate the costs. AWS accounts are reported
+// through the blueprints holding their snapshots.
+type Source struct {
+	BlueprintId string            `json:"blueprintId"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+// ReportOptions configures NewReport.
+type ReportOptions struct {
+	// Currency all budgets are requested in, so their costs can be added up.
+	Currency string
+
+	// Period of the budgets, e.g. "m" for monthly.
+	Period string
+
+	// Rate of the budgets, e.g. "ondemand".
+	Rate string
+
+	// GroupBy is the label key the costs are aggregated by.
+	GroupBy string
+
+	// Convert, if set, is applied to each budget before aggregation, e.g. to
+	// report in the currency and with the markup used for chargeback.
+	Convert *ConvertOptions
+}
+
+// Entry is the budget of one Source.
+type Entry struct {
+	Source
+	Total  float64 `json:"total"`
+	Budget *Budget `json:"budget"`
+}
+
+// Group is the aggregated cost of the entries sharing a label value.
+type Group struct {
+	Value   string  `json:"value"`
+	Entries int     `json:"entries"`
+	Total   float64 `json:"total"`
+}
+
+// Report is a cost summary across several blueprints.
+type Report struct {
+	Currency string  `json:"currency,omitempty"`
+	Period   string  `json:"period,omitempty"`
+	GroupBy  string  `json:"groupBy,omitempty"`
+	Entries  []Entry `json:"entries"`
+	Groups   []Group `json:"groups,omitempty"`
+	Total    float64 `json:"total"`
+}
+
+// NewReport fetches the budgets of sources and aggregates them.
+func NewReport(ctx context.Context, client *cloudcraft.Client, sources []Source, opts *ReportOptions) (*Report, error) {
+	if opts == nil {
+		opts = &ReportOptions{}
+	}
+
+	params := &cloudcraft.BlueprintBudgetParameters{Currency: opts.Currency, Period: opts.Period, Rate: opts.Rate}
+
+	entries := make([]Entry, 0, len(sources))
+	for _, source := range sources {
+		b, err := Fetch(ctx, client, source.BlueprintId, params)
+		if err != nil {
+			return nil, fmt.Errorf("budget of blueprint %s: %w", source.BlueprintId, err)
+		}
+
+		if opts.Convert != nil {
+			b, err = Convert(ctx, b, opts.Convert)
+			if err != nil {
+				return nil, fmt.Errorf("budget of blueprint %s: %w", source.BlueprintId, err)
+			}
+		}
+
+		entries = append(entries, Entry{Source: source, Total: b.Total, Budget: b})
+	}
+
+	return Aggregate(entries, opts), nil
+}
+
+// Aggregate builds a Report from already fetched entries.
+func Aggregate(entries []Entry, opts *ReportOptions) *Report {
+	if opts == nil {
+		opts = &ReportOptions{}
+	}
+
+	r := &Report{Currency: opts.Currency, Period: opts.Period, GroupBy: opts.GroupBy, Entries: entries}
+	if opts.Convert != nil && opts.Convert.Currency != "" {
+		r.Currency = opts.Convert.Currency
+	}
+
+	groups := make(map[string]*Group)
+	for _, e := range entries {
+		r.Total += e.Total
+
+		if opts.GroupBy == "" {
+			continue
+		}
+
+		value := e.Labels[opts.GroupBy]
+		g, ok := groups[value]
+		if !ok {
+			g = &Group{Value: value}
+			groups[value] = g
+		}
+		g.Entries++
+		g.Total += e.Total
+	}
+
+	for _, g := range groups {
+		r.Groups = append(r.Groups, *g)
+	}
+	sort.Slice(r.Groups, func(i, j int) bool {
+		if r.Groups[i].Total != r.Groups[j].Total {
+			return r.Groups[i].Total > r.Groups[j].Total
+		}
+		return r.Groups[i].Value < r.Groups[j].Value
+	})
+
+	return r
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV writes the report as CSV: one line per group if the report is
+// grouped, one line per entry otherwise.
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	if r.GroupBy != "" {
+		if err := cw.Write([]string{r.GroupBy, "entries", "total", "currency"}); err != nil {
+			return err
+		}
+		for _, g := range r.Groups {
+			if err := cw.Write([]string{g.Value, strconv.Itoa(g.Entries), formatAmount(g.Total), r.Currency}); err != nil {
+				return err
+			}
+		}
+	} else {
+		if err := cw.Write([]string{"blueprintId", "total", "currency"}); err != nil {
+			return err
+		}
+		for _, e := range r.Entries {
+			if err := cw.Write([]string{e.BlueprintId, formatAmount(e.Total), r.Currency}); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
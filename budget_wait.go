@@ -0,0 +1,110 @@
+package cloudcraft
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultBudgetPollInterval    = time.Second
+	defaultBudgetMaxPollInterval = 30 * time.Second
+)
+
+// BudgetWaitOptions specifies the optional parameters of
+// Blueprints.BudgetWait.
+type BudgetWaitOptions struct {
+	// Interval is the delay before the first poll of a budget still being
+	// generated, 1s if zero. It doubles after every poll, up to MaxInterval,
+	// 30s if zero. A Retry-After sent by the API takes precedence, bounded
+	// by MaxInterval as well.
+	Interval    time.Duration
+	MaxInterval time.Duration
+
+	// Progress, if set, is called after every 202 Accepted response, before
+	// waiting to poll again.
+	Progress func(BudgetProgress)
+}
+
+// BudgetProgress reports the wait for a budget being generated, see
+// BudgetWaitOptions.
+type BudgetProgress struct {
+	// Polls is the number of requests answered with 202 Accepted so far.
+	Polls int
+
+	// Elapsed is the time since the first request, and Next the delay
+	// before the next one.
+	Elapsed time.Duration
+	Next    time.Duration
+
+	// RetryAfter reports whether Next was asked by the API.
+	RetryAfter bool
+}
+
+func (p BudgetProgress) String() string {
+	return Stringify(p)
+}
+
+// BudgetWait gets the budget of a Blueprint like Budget, waiting for large
+// budgets the API generates asynchronously: while the API answers 202
+// Accepted, the request is sent again after the delay of its Retry-After
+// header, or else after an exponential backoff. The wait is bounded by ctx,
+// or by the Timeout request option.
+func (s *BlueprintsServiceOp) BudgetWait(ctx context.Context, blueprintId string, budgetRequest *BlueprintBudgetRequest, opt *BudgetWaitOptions, opts ...RequestOpt) (*BlueprintBudget, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if opt == nil {
+		opt = &BudgetWaitOptions{}
+	}
+	if opt.Interval < 0 || opt.MaxInterval < 0 {
+		return nil, nil, NewArgError("opt.Interval", "cannot be negative")
+	}
+
+	interval := opt.Interval
+	if interval == 0 {
+		interval = defaultBudgetPollInterval
+	}
+	maxInterval := opt.MaxInterval
+	if maxInterval == 0 {
+		maxInterval = defaultBudgetMaxPollInterval
+	}
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+
+	// Budget validates the request, and returns the 202 responses instead
+	// of sending the request again at once.
+	pollCtx := WithRequestOpts(ctx, ReturnAccepted())
+	start := time.Now()
+	for polls := 1; ; polls++ {
+		budget, resp, err := s.Budget(pollCtx, blueprintId, budgetRequest)
+		if err != nil || resp.StatusCode != http.StatusAccepted {
+			return budget, resp, err
+		}
+
+		next, retryAfter := resp.RetryAfter()
+		if !retryAfter {
+			next = interval
+			interval *= 2
+			if interval > maxInterval {
+				interval = maxInterval
+			}
+		} else if next > maxInterval {
+			next = maxInterval
+		}
+
+		if opt.Progress != nil {
+			opt.Progress(BudgetProgress{Polls: polls, Elapsed: time.Since(start), Next: next, RetryAfter: retryAfter})
+		}
+
+		timer := time.NewTimer(next)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, resp, fmt.Errorf("cloudcraft: waiting for the budget of blueprint %s: %w", blueprintId, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// Package cleanup finds the blueprints of an organization nobody updated for
+// a while and removes them in bulk, after archiving an export of each, to
+// keep large organizations tidy.
+package cleanup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/updater/cloudcraft-go"
+	"github.com/updater/cloudcraft-go/sink"
+)
+
+// Options configures Find.
+type Options struct {
+	// MaxAge is the time since their last update after which blueprints are
+	// stale.
+	MaxAge time.Duration
+
+	// InactiveCreators restricts the stale blueprints to those whose creator
+	// has not been active for MaxAge either, or is not a user of the
+	// organization anymore. It requires listing the users of the
+	// organization.
+	InactiveCreators bool
+
+	// Now is the current time, time.Now if nil.
+	Now func() time.Time
+}
+
+// Stale is a blueprint found by Find.
+type Stale struct {
+	Blueprint cloudcraft.Blueprint `json:"blueprint"`
+
+	// Age is the time since the last update of the blueprint.
+	Age time.Duration `json:"age"`
+
+	// Creator is the creator of the blueprint, nil if unknown or when not
+	// cross-referenced.
+	Creator *cloudcraft.User `json:"creator,omitempty"`
+}
+
+// Find returns the stale blueprints of the organization, oldest first.
+func Find(ctx context.Context, client *cloudcraft.Client, opts *Options) ([]Stale, error) {
+	if opts == nil || opts.MaxAge <= 0 {
+		return nil, cloudcraft.NewArgError("opts.MaxAge", "must be positive")
+	}
+
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+	cutoff := now().Add(-opts.MaxAge)
+
+	blueprints, _, err := client.Blueprints.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var users map[string]*cloudcraft.User
+	if opts.InactiveCreators {
+		list, _, err := client.Users.List(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("cleanup: listing users: %w", err)
+		}
+
+		users = make(map[string]*cloudcraft.User, len(list))
+		for i := range list {
+			users[list[i].ID] = &list[i]
+		}
+	}
+
+	stale := []Stale{}
+	for _, blueprint := range blueprints {
+		updatedAt := blueprint.UpdatedAt.Time
+		if updatedAt.IsZero() {
+			updatedAt = blueprint.CreatedAt.Time
+		}
+		if updatedAt.IsZero() || updatedAt.After(cutoff) {
+			continue
+		}
+
+		s := Stale{Blueprint: blueprint, Age: now().Sub(updatedAt)}
+		if opts.InactiveCreators {
+			creator, ok := users[blueprint.CreatorId]
+			if ok && creator.LastActiveAt.After(cutoff) {
+				continue
+			}
+			s.Creator = creator
+		}
+
+		stale = append(stale, s)
+	}
+
+	sort.SliceStable(stale, func(i, j int) bool { return stale[i].Age > stale[j].Age })
+	return stale, nil
+}
+
+// RemoveOptions configures Remove.
+type RemoveOptions struct {
+	// DryRun reports what would be archived and deleted without doing it.
+	DryRun bool
+
+	// Archive, if set, receives an export of each blueprint before it is
+	// deleted, named <blueprint id>.<format>. Blueprints whose archive fails
+	// are not deleted.
+	Archive sink.Sink
+
+	// Format of the archived exports, json if empty, which holds the whole
+	// blueprint data.
+	Format string
+
+	// Batch bounds the concurrency and rate of the calls.
+	Batch *cloudcraft.BatchOptions
+}
+
+// Result is the outcome of the removal of a stale blueprint. Exactly one of
+// Deleted, DryRun and Err is set.
+type Result struct {
+	Stale
+	Archived string `json:"archived,omitempty"`
+	Deleted  bool   `json:"deleted,omitempty"`
+	DryRun   bool   `json:"dryRun,omitempty"`
+	Err      error  `json:"-"`
+}
+
+// Remove archives then deletes the stale blueprints, returning a result
+// per blueprint in the order of stale.
+func Remove(ctx context.Context, client *cloudcraft.Client, stale []Stale, opts *RemoveOptions) ([]Result, error) {
+	if opts == nil {
+		opts = &RemoveOptions{}
+	}
+
+	format := opts.Format
+	if format == "" {
+		format = "json"
+	}
+
+	results := make([]Result, len(stale))
+	if opts.DryRun {
+		for i, s := range stale {
+			results[i] = Result{Stale: s, DryRun: true}
+			if opts.Archive != nil {
+				results[i].Archived = s.Blueprint.Id + "." + format
+			}
+		}
+		return results, nil
+	}
+
+	var mu sync.Mutex
+	batch := cloudcraft.NewBatch(opts.Batch)
+	for i, s := range stale {
+		i, s := i, s
+		results[i] = Result{Stale: s}
+
+		batch.Add(s.Blueprint.Id, func(ctx context.Context) error {
+			archived, err := remove(ctx, client, s.Blueprint.Id, format, opts.Archive)
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[i].Archived = archived
+			results[i].Deleted = err == nil
+			results[i].Err = err
+			return err
+		})
+	}
+
+	// The failures are reported in the results.
+	if err := batch.Run(ctx); err != nil && ctx.Err() != nil {
+		return results, ctx.Err()
+	}
+	return results, nil
+}
+
+// remove archives and deletes a blueprint, returning the name of its
+// archive.
+func remove(ctx context.Context, client *cloudcraft.Client, blueprintId, format string, archive sink.Sink) (string, error) {
+	var name string
+	if archive != nil {
+		name = blueprintId + "." + format
+
+		var err error
+		if format == "json" {
+			err = archiveData(ctx, client, blueprintId, name, archive)
+		} else {
+			err = archiveExport(ctx, client, blueprintId, format, name, archive)
+		}
+		if err != nil {
+			return "", fmt.Errorf("cleanup: archiving blueprint %s: %w", blueprintId, err)
+		}
+	}
+
+	if _, err := client.Blueprints.Delete(ctx, blueprintId); err != nil {
+		return name, err
+	}
+	return name, nil
+}
+
+// archiveData stores the blueprint as JSON, from which it can be created
+// again.
+func archiveData(ctx context.Context, client *cloudcraft.Client, blueprintId, name string, archive sink.Sink) error {
+	blueprint, _, err := client.Blueprints.Get(ctx, blueprintId)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(blueprint, "", "  ")
+	if err != nil {
+		return err
+	}
+	return archive.Put(ctx, name, "application/json", bytes.NewReader(data))
+}
+
+// archiveExport stores an export of the blueprint in format.
+func archiveExport(ctx context.Context, client *cloudcraft.Client, blueprintId, format, name string, archive sink.Sink) error {
+	image, _, err := client.Blueprints.Export(ctx, blueprintId, &cloudcraft.BlueprintExportRequest{Format: format})
+	if err != nil {
+		return err
+	}
+	return archive.Put(ctx, name, image.ContentType, image.Content)
+}
@@ -4,14 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"reflect"
-
-	"github.com/google/go-querystring/query"
+	"os"
+	"time"
 )
 
 const (
@@ -23,11 +23,53 @@ const (
 	headerRateLimit     = "RateLimit-Limit"
 	headerRateRemaining = "RateLimit-Remaining"
 	headerRateReset     = "RateLimit-Reset"
+	headerRequestID     = "X-Request-ID"
+)
+
+const (
+	// EnvAPIKey is the environment variable NewFromEnv reads the API key from.
+	EnvAPIKey = "CLOUDCRAFT_API_KEY"
+
+	// EnvBaseURL is the environment variable NewFromEnv reads an optional
+	// base URL from.
+	EnvBaseURL = "CLOUDCRAFT_BASE_URL"
 )
 
+// ErrNoAPIKey is returned by NewFromEnv when no API key is set.
+var ErrNoAPIKey = errors.New("cloudcraft: " + EnvAPIKey + " is not set")
+
+// formatMediaTypes maps the export, snapshot and budget formats supported by
+// the API to the media type of their content.
+var formatMediaTypes = map[string]string{
+	"json":    "application/json",
+	"svg":     "image/svg+xml",
+	"png":     "image/png",
+	"pdf":     "application/pdf",
+	"mxGraph": "application/xml",
+	"csv":     "text/csv",
+	"xlsx":    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// formatMediaType returns the media type to accept for format.
+func formatMediaType(format string) string {
+	if t, ok := formatMediaTypes[format]; ok {
+		return t
+	}
+	return "*/*"
+}
+
+// Doer sends HTTP requests, like http.Client. It is implemented by
+// http.Client as well as by the clients of other HTTP stacks, such as
+// retrying or instrumented ones, and by test doubles, see SetDoer.
+type Doer interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+var _ Doer = &http.Client{}
+
 type Client struct {
 	// HTTP client used to communicate with the Cloudcraft API.
-	client *http.Client
+	client Doer
 
 	// Base URL for API requests.
 	BaseURL *url.URL
@@ -36,8 +78,12 @@ type Client struct {
 	UserAgent string
 
 	// Services used for communicating with the API
+	ApiKeys     ApiKeysService
+	Audit       AuditService
 	AwsAccounts AwsAccountsService
+	Batch       BatchService
 	Blueprints  BlueprintsService
+	Teams       TeamsService
 	Users       UsersService
 
 	// Optional function called after every successful request made to the Cloudcraft API
@@ -45,6 +91,54 @@ type Client struct {
 
 	// Optional extra HTTP headers to set on every request to the API.
 	headers map[string]string
+
+	// Transport cloned from the one of client by the transport options.
+	ownTransport *http.Transport
+
+	// Semaphore bounding the requests in flight, see SetMaxInFlight.
+	inFlight chan struct{}
+
+	// Return 202 responses instead of polling, see SetReturnAccepted.
+	returnAccepted bool
+
+	// Optional cache of the Blueprints fetched by Blueprints.Get.
+	blueprintCache BlueprintCache
+
+	// Optional cache of the exports of Blueprints.Export.
+	exportCache ExportCache
+
+	// Optional cache of the results returned stale during outages, see
+	// SetStaleOnError.
+	staleCache  ResponseCache
+	staleMaxAge time.Duration
+
+	// Codec of request and response bodies, see SetCodec, and codecs of
+	// other media types, see SetMediaTypeCodec.
+	codec  Codec
+	codecs map[string]Codec
+
+	// Duration the result of Users.Me is cached for, disabled if zero.
+	meCacheTTL time.Duration
+
+	// Maximum number of bytes read from a response body, unlimited if zero.
+	maxResponseSize int64
+
+	// Retry settings, see the SetRetry options.
+	retryMax        int
+	retryWaitMin    time.Duration
+	retryWaitMax    time.Duration
+	retryMaxElapsed time.Duration
+	retryJitter     Jitter
+	retryPolicy     RetryPolicy
+	retryBudget     *RetryBudget
+
+	// Optional journal of the mutating calls, see SetJournal.
+	journal        Journal
+	journalActor   string
+	onJournalError func(*JournalEntry, error)
+
+	// Optional naming policy of created and updated Blueprints.
+	namingPolicy *NamingPolicy
 }
 
 type RequestCompletionCallback func(*http.Request, *http.Response)
@@ -52,6 +146,31 @@ type RequestCompletionCallback func(*http.Request, *http.Response)
 // Response is a Cloudcraft response. This wraps the standard http.Response returned from Cloudcraft.
 type Response struct {
 	*http.Response
+
+	// Meta describes generic information about the response, such as the
+	// total number of items of a paginated list.
+	Meta *Meta
+
+	// Headers parsed once, see RequestID, RateLimit and RetryAfter.
+	headers responseHeaders
+
+	// Set for cached results returned during outages, see Stale.
+	stale *Staleness
+}
+
+// ListOptions specifies the optional parameters to various List methods that
+// support pagination.
+type ListOptions struct {
+	// For paginated result sets, page of results to retrieve.
+	Page int
+
+	// For paginated result sets, the number of results to include per page.
+	PerPage int
+}
+
+// Meta describes generic information about a paginated response.
+type Meta struct {
+	Total int `json:"total"`
 }
 
 // An ErrorResponse reports the error caused by an API request
@@ -64,45 +183,43 @@ type ErrorResponse struct {
 
 	// Error code
 	Code int `json:"code"`
-}
 
-func addOptions(s string, opt interface{}) (string, error) {
-	v := reflect.ValueOf(opt)
-
-	if v.Kind() == reflect.Ptr && v.IsNil() {
-		return s, nil
-	}
-
-	origURL, err := url.Parse(s)
-	if err != nil {
-		return s, err
-	}
-
-	origValues := origURL.Query()
-
-	newValues, err := query.Values(opt)
-	if err != nil {
-		return s, err
-	}
-
-	for k, v := range newValues {
-		origValues[k] = v
-	}
-
-	origURL.RawQuery = origValues.Encode()
-	return origURL.String(), nil
+	// RequestID identifies the failed request, see RequestID.
+	RequestID string `json:"-"`
 }
 
 // NewFromToken returns a new Cloudcraft API client with the given API
 // token.
 func NewFromToken(token string) *Client {
 	client, _ := New(nil, SetRequestHeaders(map[string]string{
-		"Authorization": "Bearer" + token,
+		"Authorization": "Bearer " + token,
 	}))
 
 	return client
 }
 
+// NewFromEnv returns a new Cloudcraft API client authenticated with the API
+// key of the CLOUDCRAFT_API_KEY environment variable, and using the base URL
+// of CLOUDCRAFT_BASE_URL if set. It is meant for non-interactive
+// environments such as CI jobs. The options are applied after the
+// environment.
+func NewFromEnv(opts ...ClientOpt) (*Client, error) {
+	token := os.Getenv(EnvAPIKey)
+	if token == "" {
+		return nil, ErrNoAPIKey
+	}
+
+	envOpts := []ClientOpt{SetRequestHeaders(map[string]string{
+		"Authorization": "Bearer " + token,
+	})}
+
+	if baseURL := os.Getenv(EnvBaseURL); baseURL != "" {
+		envOpts = append(envOpts, SetBaseURL(baseURL))
+	}
+
+	return New(nil, append(envOpts, opts...)...)
+}
+
 // NewClient returns a new Cloudcraft API client, using the given
 // http.Client to perform all requests.
 //
@@ -116,9 +233,13 @@ func NewClient(httpClient *http.Client) *Client {
 
 	baseURL, _ := url.Parse(defaultBaseURL)
 
-	c := &Client{client: httpClient, BaseURL: baseURL, UserAgent: userAgent}
+	c := &Client{client: httpClient, BaseURL: baseURL, UserAgent: userAgent, codec: JSONCodec{}, codecs: defaultCodecs()}
+	c.ApiKeys = &ApiKeysServiceOp{client: c}
+	c.Audit = &AuditServiceOp{client: c}
 	c.AwsAccounts = &AwsAccountsServiceOp{client: c}
+	c.Batch = &BatchServiceOp{client: c}
 	c.Blueprints = &BlueprintsServiceOp{client: c}
+	c.Teams = &TeamsServiceOp{client: c}
 	c.Users = &UsersServiceOp{client: c}
 
 	c.headers = make(map[string]string)
@@ -141,6 +262,22 @@ func New(httpClient *http.Client, opts ...ClientOpt) (*Client, error) {
 	return c, nil
 }
 
+// SetDoer is a client option sending the requests of the client with d
+// instead of the http.Client given to New. The transport options, such as
+// SetTLSConfig, require d to be an *http.Client and must then come after
+// SetDoer, other Doers being configured by their own means.
+func SetDoer(d Doer) ClientOpt {
+	return func(c *Client) error {
+		if d == nil {
+			return NewArgError("d", "cannot be nil")
+		}
+
+		c.client = d
+		c.ownTransport = nil
+		return nil
+	}
+}
+
 // SetBaseURL is a client option for setting the base URL.
 func SetBaseURL(bu string) ClientOpt {
 	return func(c *Client) error {
@@ -173,13 +310,58 @@ func SetRequestHeaders(headers map[string]string) ClientOpt {
 	}
 }
 
+// SetMeCacheTTL is a client option for memoizing the result of Users.Me for
+// the given duration. Use Users.RefreshMe to force a refresh.
+func SetMeCacheTTL(ttl time.Duration) ClientOpt {
+	return func(c *Client) error {
+		if ttl < 0 {
+			return NewArgError("ttl", "cannot be negative")
+		}
+
+		c.meCacheTTL = ttl
+		return nil
+	}
+}
+
+// SetMaxInFlight is a client option bounding the number of requests in
+// flight at the same time to n, whatever the number of goroutines sharing the
+// client. A request holds its slot from its first attempt until its response
+// body is read, including the delays between retries. Requests waiting for a
+// slot fail when their context is done.
+func SetMaxInFlight(n int) ClientOpt {
+	return func(c *Client) error {
+		if n <= 0 {
+			return NewArgError("n", "must be positive")
+		}
+
+		c.inFlight = make(chan struct{}, n)
+		return nil
+	}
+}
+
+// SetMaxResponseSize is a client option limiting the number of bytes Do reads
+// from a response body. Larger responses fail with an error matching
+// ErrResponseTooLarge.
+func SetMaxResponseSize(n int64) ClientOpt {
+	return func(c *Client) error {
+		if n <= 0 {
+			return NewArgError("n", "must be positive")
+		}
+
+		c.maxResponseSize = n
+		return nil
+	}
+}
+
 // NewRequest creates an API request. A relative URL can be provided in urlStr, which will be resolved to the
 // BaseURL of the Client. Relative URLS should always be specified without a preceding slash. If specified, the
-// value pointed to by body is JSON encoded and included in as the request body.
+// value pointed to by body is encoded with the Codec of the client and included as the request body, unless it
+// is an io.Reader whose content is sent as is.
+// Every request is identified by an X-Request-ID header, a random UUID unless set with the RequestID option.
 func (c *Client) NewRequest(ctx context.Context, method, urlStr string, body interface{}) (*http.Request, error) {
 	u, err := c.BaseURL.Parse(urlStr)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("cloudcraft: parsing URL %q: %w", urlStr, err)
 	}
 
 	var req *http.Request
@@ -187,23 +369,34 @@ func (c *Client) NewRequest(ctx context.Context, method, urlStr string, body int
 	case http.MethodGet, http.MethodHead, http.MethodOptions:
 		req, err = http.NewRequest(method, u.String(), nil)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("cloudcraft: creating %s request: %w", method, err)
 		}
 
 	default:
-		buf := new(bytes.Buffer)
-		if body != nil {
-			err = json.NewEncoder(buf).Encode(body)
-			if err != nil {
-				return nil, err
+		contentType := mediaType
+		if t := c.requestOptionsOf(ctx).contentType; t != "" {
+			contentType = t
+		}
+
+		var buf io.Reader
+		if r, ok := body.(io.Reader); ok {
+			buf = r
+		} else {
+			b := new(bytes.Buffer)
+			if body != nil {
+				err = c.codecFor(contentType).Encode(b, body)
+				if err != nil {
+					return nil, &EncodeError{Op: method + " " + u.String(), Err: err}
+				}
 			}
+			buf = b
 		}
 
 		req, err = http.NewRequest(method, u.String(), buf)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("cloudcraft: creating %s request: %w", method, err)
 		}
-		req.Header.Set("Content-Type", mediaType)
+		req.Header.Set("Content-Type", contentType)
 	}
 
 	for k, v := range c.headers {
@@ -213,6 +406,14 @@ func (c *Client) NewRequest(ctx context.Context, method, urlStr string, body int
 	req.Header.Set("Accept", mediaType)
 	req.Header.Set("User-Agent", c.UserAgent)
 
+	requestID := c.requestOptionsOf(ctx).requestID
+	if requestID == "" {
+		if requestID, err = newUUID(); err != nil {
+			return nil, err
+		}
+	}
+	req.Header.Set(headerRequestID, requestID)
+
 	return req, nil
 }
 
@@ -223,7 +424,7 @@ func (c *Client) OnRequestCompleted(rc RequestCompletionCallback) {
 
 // newResponse creates a new Response for the provided http.Response
 func newResponse(r *http.Response) *Response {
-	response := Response{Response: r}
+	response := Response{Response: r, headers: parseResponseHeaders(r)}
 
 	return &response
 }
@@ -232,14 +433,60 @@ func newResponse(r *http.Response) *Response {
 // pointed to by v, or returned as an error if an API error has occurred. If v implements the io.Writer interface,
 // the raw response will be written to v, without attempting to decode it.
 func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Response, error) {
-	resp, err := DoRequestWithClient(ctx, c.client, req)
+	if c.staleCache != nil && req.Method == http.MethodGet && v != nil {
+		if _, ok := v.(io.Writer); !ok {
+			return c.doStale(ctx, req, v)
+		}
+	}
+
+	if c.journal == nil || !isMutating(req.Method) {
+		return c.do(ctx, req, v)
+	}
+
+	entry := c.newJournalEntry(ctx, req)
+	resp, err := c.do(ctx, req, v)
+	c.recordJournal(ctx, entry, resp, err)
+	return resp, err
+}
+
+func (c *Client) do(ctx context.Context, req *http.Request, v interface{}) (*Response, error) {
+	if c.inFlight != nil {
+		select {
+		case c.inFlight <- struct{}{}:
+			defer func() { <-c.inFlight }()
+		case <-ctx.Done():
+			return nil, &TransportError{Op: requestOp(req), Err: ctx.Err()}
+		}
+	}
+
+	opts := c.requestOptionsOf(ctx)
+	if opts.accept != "" {
+		req.Header.Set("Accept", opts.accept)
+	}
+	if opts.contentType != "" && req.Body != nil && req.Body != http.NoBody {
+		req.Header.Set("Content-Type", opts.contentType)
+	}
+	for k, v := range opts.headers {
+		req.Header[k] = v
+	}
+
+	resp, err := c.send(ctx, req)
 
-	for resp.StatusCode == 202 {
-		resp, err = DoRequestWithClient(ctx, c.client, req)
+	// A 202 Accepted is polled again after the delay of its Retry-After, or
+	// else after the backoff of the client.
+	for attempt := 0; err == nil && resp.StatusCode == http.StatusAccepted && !opts.returnAccepted; attempt++ {
+		resp.Body.Close()
+		if err = sleep(ctx, c.acceptedWait(attempt, resp)); err != nil {
+			break
+		}
+		if err = rewindBody(req); err != nil {
+			return nil, err
+		}
+		resp, err = c.send(ctx, req)
 	}
 
 	if err != nil {
-		return nil, err
+		return nil, &TransportError{Op: requestOp(req), Err: err}
 	}
 	if c.onRequestCompleted != nil {
 		c.onRequestCompleted(req, resp)
@@ -262,6 +509,29 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Res
 		}
 	}()
 
+	if c.maxResponseSize > 0 && resp.ContentLength > c.maxResponseSize {
+		return newResponse(resp), &ResponseTooLargeError{Limit: c.maxResponseSize}
+	}
+
+	// Reading the body must stop when ctx is done, even if the caller is
+	// blocked decoding a slow response. The body is built before the
+	// goroutine closing it starts, which must not read resp.Body.
+	var body io.ReadCloser = &contextBody{ReadCloser: resp.Body, ctx: ctx}
+	if c.maxResponseSize > 0 {
+		body = &limitedBody{ReadCloser: body, remaining: c.maxResponseSize, limit: c.maxResponseSize}
+	}
+	resp.Body = body
+
+	bodyDone := make(chan struct{})
+	defer close(bodyDone)
+	go func() {
+		select {
+		case <-ctx.Done():
+			body.Close()
+		case <-bodyDone:
+		}
+	}()
+
 	response := newResponse(resp)
 
 	err = CheckResponse(resp)
@@ -271,14 +541,20 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Res
 
 	if v != nil {
 		if w, ok := v.(io.Writer); ok {
+			if rw, ok := w.(responseWriter); ok {
+				rw.begin(resp)
+			}
 			_, err = io.Copy(w, resp.Body)
 			if err != nil {
-				return nil, err
+				return response, &DecodeError{Op: requestOp(req), Err: err}
 			}
 		} else {
-			err = json.NewDecoder(resp.Body).Decode(v)
+			err = c.codecFor(resp.Header.Get("Content-Type")).Decode(resp.Body, v)
+			if err == io.EOF && resp.StatusCode == http.StatusAccepted {
+				err = nil
+			}
 			if err != nil {
-				return nil, err
+				return nil, &DecodeError{Op: requestOp(req), Err: err}
 			}
 		}
 	}
@@ -286,21 +562,77 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Res
 	return response, err
 }
 
+// contextBody is a response body failing with the error of ctx once it is
+// done.
+type contextBody struct {
+	io.ReadCloser
+	ctx context.Context
+}
+
+func (b *contextBody) Read(p []byte) (int, error) {
+	if err := b.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		if ctxErr := b.ctx.Err(); ctxErr != nil {
+			return n, ctxErr
+		}
+	}
+	return n, err
+}
+
+// limitedBody is a response body failing with a ResponseTooLargeError once
+// more than limit bytes are read.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	limit     int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, &ResponseTooLargeError{Limit: b.limit}
+	}
+
+	// Read one byte past the limit to tell a body of exactly limit bytes
+	// from a larger one.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) <= b.remaining {
+		b.remaining -= int64(n)
+		return n, err
+	}
+
+	n = int(b.remaining)
+	b.remaining = -1
+	return n, &ResponseTooLargeError{Limit: b.limit}
+}
+
 // DoRequest submits an HTTP request.
 func DoRequest(ctx context.Context, req *http.Request) (*http.Response, error) {
 	return DoRequestWithClient(ctx, http.DefaultClient, req)
 }
 
-// DoRequestWithClient submits an HTTP request using the specified client.
+// DoRequestWithClient submits an HTTP request using the specified client,
+// an *http.Client or any other Doer.
 func DoRequestWithClient(
 	ctx context.Context,
-	client *http.Client,
+	client Doer,
 	req *http.Request) (*http.Response, error) {
 	req = req.WithContext(ctx)
 	return client.Do(req)
 }
 
 func (r *ErrorResponse) Error() string {
+	if r.RequestID != "" {
+		return fmt.Sprintf("%v %v: %d %v (request id %s)",
+			r.Response.Request.Method, r.Response.Request.URL, r.Response.StatusCode, r.Message, r.RequestID)
+	}
 	return fmt.Sprintf("%v %v: %d %v",
 		r.Response.Request.Method, r.Response.Request.URL, r.Response.StatusCode, r.Message)
 }
@@ -314,6 +646,9 @@ func CheckResponse(r *http.Response) error {
 	}
 
 	errorResponse := &ErrorResponse{Response: r}
+	if r.Request != nil {
+		errorResponse.RequestID = r.Request.Header.Get(headerRequestID)
+	}
 	data, err := ioutil.ReadAll(r.Body)
 	if err == nil && len(data) > 0 {
 		err := json.Unmarshal(data, errorResponse)
@@ -0,0 +1,71 @@
+package cloudcraft
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDoWaitsBetweenAcceptedPolls(t *testing.T) {
+	var mu sync.Mutex
+	var polls []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		polls = append(polls, time.Now())
+		n := len(polls)
+		mu.Unlock()
+
+		if n < 3 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", mediaType)
+		w.Write([]byte(`{"id": "u"}`))
+	}))
+	defer server.Close()
+
+	const wait = 20 * time.Millisecond
+	client, err := New(nil, SetBaseURL(server.URL+"/"), SetRetryWait(wait, wait))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := client.Users.Get(context.Background(), "me"); err != nil {
+		t.Fatal(err)
+	}
+	if len(polls) != 3 {
+		t.Fatalf("%d polls, want 3", len(polls))
+	}
+	for i := 1; i < len(polls); i++ {
+		if d := polls[i].Sub(polls[i-1]); d < wait {
+			t.Errorf("poll %d sent %v after the previous one, want at least %v", i, d, wait)
+		}
+	}
+}
+
+func TestDoAcceptedStopsWithContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client, err := New(nil, SetBaseURL(server.URL+"/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, _, err := client.Users.Get(ctx, "me"); err == nil {
+		t.Fatal("Get() returned no error")
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Get() returned after %v, want at the deadline", d)
+	}
+}
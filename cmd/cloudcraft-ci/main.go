@@ -0,0 +1,193 @@
+// Command cloudcraft-ci exports Cloudcraft blueprints from CI jobs such as
+// GitHub Actions workflows. It is configured entirely through environment
+// variables, never prompts, reports errors as workflow annotations and
+// publishes the paths of the exported files as step outputs:
+//
+//	CLOUDCRAFT_API_KEY    API key (required)
+//	INPUT_BLUEPRINTS      blueprint IDs, separated by commas or newlines (required)
+//	INPUT_FORMAT          export format, png by default
+//	INPUT_OUTPUT_DIR      directory the exports are written to, "." by default
+//	INPUT_MANIFEST        name of a JSON manifest of the exports, with their
+//	                      checksums, written to the output directory
+//
+// Each blueprint is written to <output dir>/<blueprint ID>.<format>. The
+// "paths" output lists the written files, one per line.
+//
+// Exit codes:
+//
+//	0  all blueprints were exported
+//	1  at least one export failed
+//	2  invalid configuration
+//	3  the API key was rejected
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/updater/cloudcraft-go"
+	"github.com/updater/cloudcraft-go/sink"
+)
+
+const (
+	exitOK = iota
+	exitExportFailed
+	exitInvalidConfig
+	exitUnauthorized
+)
+
+const ciUserAgent = "cloudcraft-ci"
+
+// config holds the inputs of an export run.
+type config struct {
+	blueprintIDs []string
+	format       string
+	outputDir    string
+
+	// manifest is the name of the manifest of the exports written to the
+	// output directory, none if empty.
+	manifest string
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Stdout)
+	stop()
+	os.Exit(code)
+}
+
+func run(ctx context.Context, stdout io.Writer) int {
+	cfg, err := loadConfig()
+	if err != nil {
+		annotateError(stdout, err)
+		return exitInvalidConfig
+	}
+
+	client, err := cloudcraft.NewFromEnv(cloudcraft.SetUserAgent(ciUserAgent))
+	if err != nil {
+		annotateError(stdout, err)
+		return exitInvalidConfig
+	}
+
+	dir := sink.NewManifestSink(sink.NewDir(cfg.outputDir))
+
+	var paths []string
+	code := exitOK
+	for _, blueprintID := range cfg.blueprintIDs {
+		name := blueprintID + "." + cfg.format
+
+		image, _, err := client.Blueprints.Export(ctx, blueprintID, &cloudcraft.BlueprintExportRequest{Format: cfg.format})
+		if err == nil {
+			err = dir.PutArtifact(ctx, &sink.Artifact{
+				Name:        name,
+				ContentType: image.ContentType,
+				Source:      &sink.Source{Kind: "blueprint", Id: blueprintID},
+				Parameters:  image.ExportParameters,
+			}, image.Content)
+		}
+
+		if err != nil {
+			annotateError(stdout, fmt.Errorf("export of blueprint %s failed: %w", blueprintID, err))
+			if isUnauthorized(err) {
+				return exitUnauthorized
+			}
+			code = exitExportFailed
+			continue
+		}
+
+		path := filepath.Join(cfg.outputDir, name)
+		paths = append(paths, path)
+		fmt.Fprintf(stdout, "exported blueprint %s to %s\n", blueprintID, path)
+	}
+
+	if cfg.manifest != "" {
+		if err := dir.WriteManifest(ctx, cfg.manifest); err != nil {
+			annotateError(stdout, fmt.Errorf("writing manifest: %w", err))
+			if code == exitOK {
+				code = exitExportFailed
+			}
+		}
+	}
+
+	if err := setOutput("paths", strings.Join(paths, "\n")); err != nil {
+		annotateError(stdout, err)
+		if code == exitOK {
+			code = exitExportFailed
+		}
+	}
+
+	return code
+}
+
+func loadConfig() (*config, error) {
+	cfg := &config{
+		format:    input("FORMAT", "png"),
+		outputDir: input("OUTPUT_DIR", "."),
+		manifest:  input("MANIFEST", ""),
+	}
+
+	for _, id := range strings.FieldsFunc(input("BLUEPRINTS", ""), func(r rune) bool {
+		return r == ',' || r == '\n' || r == ' '
+	}) {
+		cfg.blueprintIDs = append(cfg.blueprintIDs, id)
+	}
+
+	if len(cfg.blueprintIDs) == 0 {
+		return nil, errors.New("INPUT_BLUEPRINTS must list at least one blueprint ID")
+	}
+
+	return cfg, nil
+}
+
+// input returns the value of a workflow input, as passed by GitHub Actions in
+// an INPUT_ prefixed environment variable.
+func input(name, defaultValue string) string {
+	if v := strings.TrimSpace(os.Getenv("INPUT_" + name)); v != "" {
+		return v
+	}
+	return defaultValue
+}
+
+func isUnauthorized(err error) bool {
+	var errorResponse *cloudcraft.ErrorResponse
+	if errors.As(err, &errorResponse) && errorResponse.Response != nil {
+		code := errorResponse.Response.StatusCode
+		return code == http.StatusUnauthorized || code == http.StatusForbidden
+	}
+	return false
+}
+
+// annotateError reports err as a workflow error annotation.
+func annotateError(w io.Writer, err error) {
+	msg := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(err.Error())
+	fmt.Fprintf(w, "::error::%s\n", msg)
+}
+
+// setOutput publishes a step output through the file named by GITHUB_OUTPUT.
+// It does nothing outside of GitHub Actions.
+func setOutput(name, value string) error {
+	path := os.Getenv("GITHUB_OUTPUT")
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	const delimiter = "CLOUDCRAFT_OUTPUT_EOF"
+	if _, err := fmt.Fprintf(f, "%s<<%s\n%s\n%s\n", name, delimiter, value, delimiter); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
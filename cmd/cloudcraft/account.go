@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/updater/cloudcraft-go"
+	"github.com/updater/cloudcraft-go/sink"
+)
+
+var accountCommands = map[string]command{
+	"list":           {usage: "", run: accountList},
+	"get":            {usage: "<id>", run: accountGet},
+	"create":         {usage: "-name <name> -role-arn <arn>", run: accountCreate},
+	"update":         {usage: "<id> -name <name> -role-arn <arn>", run: accountUpdate},
+	"delete":         {usage: "<id>", run: accountDelete},
+	"snapshot":       {usage: "<id> -region <region> [-format png] [-o path | -s3 bucket/prefix] [flags]", run: accountSnapshot},
+	"iam-parameters": {usage: "", run: accountIamParameters},
+}
+
+func accountList(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	if _, err := parseArgs(flags, args, 0); err != nil {
+		return err
+	}
+
+	accounts, _, err := c.client.AwsAccounts.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	return printResult(c, accounts)
+}
+
+func accountGet(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	args, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+
+	account, _, err := c.client.AwsAccounts.Get(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	return printResult(c, account)
+}
+
+func accountCreate(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	name := flags.String("name", "", "name of the account")
+	roleArn := flags.String("role-arn", "", "ARN of the IAM role Cloudcraft assumes")
+	if _, err := parseArgs(flags, args, 0); err != nil {
+		return err
+	}
+
+	account, _, err := c.client.AwsAccounts.Create(ctx, &cloudcraft.AwsAccountCreateOrUpdateRequest{
+		Name:    *name,
+		RoleArn: *roleArn,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printResult(c, account)
+}
+
+func accountUpdate(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	name := flags.String("name", "", "name of the account")
+	roleArn := flags.String("role-arn", "", "ARN of the IAM role Cloudcraft assumes")
+	args, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+
+	account, _, err := c.client.AwsAccounts.Update(ctx, args[0], &cloudcraft.AwsAccountCreateOrUpdateRequest{
+		Name:    *name,
+		RoleArn: *roleArn,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printResult(c, account)
+}
+
+func accountDelete(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	args, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.client.AwsAccounts.Delete(ctx, args[0])
+	return err
+}
+
+func accountSnapshot(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	region := flags.String("region", "", "AWS region to snapshot")
+	format := flags.String("format", "png", "snapshot format: json, svg, png, pdf or mxGraph")
+	output := flags.String("o", "", "output path (default standard output)")
+	s3Target := flags.String("s3", "", "upload the snapshot to an S3 bucket/prefix, using the AWS credentials of the environment")
+	manifest := flags.String("manifest", "", "also write a JSON manifest of the snapshot with its checksum to this path, or object name with -s3")
+	params := &cloudcraft.AwsAccountSnapshotParameters{}
+	flags.BoolVar(&params.Autoconnect, "autoconnect", false, "automatically connect components")
+	exclude := flags.String("exclude", "", "comma separated component types to exclude")
+	flags.StringVar(&params.Filter, "filter", "", "filter expression")
+	flags.BoolVar(&params.Grid, "grid", false, "show grid")
+	flags.IntVar(&params.Height, "height", 0, "image height in pixels")
+	flags.BoolVar(&params.Label, "label", false, "show labels")
+	flags.BoolVar(&params.Landscape, "landscape", false, "landscape orientation")
+	flags.StringVar(&params.PaperSize, "paper-size", "", "paper size for PDF snapshots")
+	flags.StringVar(&params.Projection, "projection", "", "isometric or 2d")
+	scale := flags.Float64("scale", 0, "image scale")
+	flags.BoolVar(&params.Transparent, "transparent", false, "transparent background")
+	flags.IntVar(&params.Width, "width", 0, "image width in pixels")
+	args, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+
+	if *region == "" || (*s3Target != "" && *output != "") {
+		flags.Usage()
+		return errUsage
+	}
+
+	var s3Sink *sink.S3
+	if *s3Target != "" {
+		if s3Sink, err = sink.NewS3FromEnv(*s3Target); err != nil {
+			return err
+		}
+	}
+
+	params.Exclude = splitList(*exclude)
+	params.Scale = float32(*scale)
+
+	snapshot, _, err := c.client.AwsAccounts.Snapshot(ctx, args[0], &cloudcraft.AwsAccountSnapshotRequest{
+		Format:             *format,
+		Region:             *region,
+		SnapshotParameters: params,
+	})
+	if err != nil {
+		return err
+	}
+
+	artifact := &sink.Artifact{
+		ContentType: snapshot.ContentType,
+		Source:      &sink.Source{Kind: "awsAccount", Id: args[0], Region: *region},
+		Parameters:  params,
+	}
+
+	if s3Sink != nil {
+		ms := sink.NewManifestSink(s3Sink)
+		artifact.Name = snapshotObjectName(args[0], *region, *format, time.Now())
+		if err := ms.PutArtifact(ctx, artifact, snapshot.Content); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.stderr, "uploaded snapshot to s3://%s/%s\n", s3Sink.Bucket, joinKey(s3Sink.Prefix, artifact.Name))
+
+		if *manifest != "" {
+			if err := ms.WriteManifest(ctx, *manifest); err != nil {
+				return err
+			}
+			fmt.Fprintf(c.stderr, "uploaded manifest to s3://%s/%s\n", s3Sink.Bucket, joinKey(s3Sink.Prefix, *manifest))
+		}
+		return nil
+	}
+
+	return writeArtifact(ctx, c, *output, *manifest, artifact, snapshot.Content)
+}
+
+// snapshotObjectName returns the name an account snapshot taken at t is
+// archived under, so that successive snapshots sort chronologically.
+func snapshotObjectName(accountID, region, format string, t time.Time) string {
+	return fmt.Sprintf("%s/%s/%s.%s", accountID, region, t.UTC().Format("20060102T150405Z"), format)
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "/" + name
+}
+
+func accountIamParameters(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	if _, err := parseArgs(flags, args, 0); err != nil {
+		return err
+	}
+
+	params, _, err := c.client.AwsAccounts.IamParameters(ctx)
+	if err != nil {
+		return err
+	}
+
+	return printResult(c, params)
+}
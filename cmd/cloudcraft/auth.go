@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/updater/cloudcraft-go/credentials"
+)
+
+const envPassphrase = "CLOUDCRAFT_PASSPHRASE"
+
+var authCommands = map[string]command{
+	"login":  {usage: "", offline: true, run: authLogin},
+	"logout": {usage: "", offline: true, run: authLogout},
+}
+
+// credentialStore returns the store of the API keys of the profiles, the
+// keychain of the system or else a file encrypted with $CLOUDCRAFT_PASSPHRASE.
+func credentialStore() (credentials.Store, error) {
+	store, err := credentials.Default(os.Getenv(envPassphrase))
+	if errors.Is(err, credentials.ErrUnavailable) {
+		return nil, fmt.Errorf("no keychain available: set %s to encrypt the API keys with", envPassphrase)
+	}
+	return store, err
+}
+
+// authLogin reads an API key from the standard input and stores it for the
+// profile.
+func authLogin(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	if _, err := parseArgs(flags, args, 0); err != nil {
+		return err
+	}
+
+	store, err := credentialStore()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stderr, "API key for profile %q: ", c.profileName)
+	if restore := disableEcho(c.stdin); restore != nil {
+		defer restore()
+	}
+	line, err := bufio.NewReader(c.stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return fmt.Errorf("reading API key: %w", err)
+	}
+	fmt.Fprintln(c.stderr)
+
+	apiKey := strings.TrimSpace(line)
+	if apiKey == "" {
+		return errors.New("empty API key")
+	}
+
+	return store.Set(c.profileName, apiKey)
+}
+
+// disableEcho turns off the echo of the terminal r reads from, if it is one,
+// and returns the func turning it back on, nil if the echo is unchanged.
+func disableEcho(r io.Reader) func() {
+	tty, ok := r.(*os.File)
+	if !ok {
+		return nil
+	}
+	if info, err := tty.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+
+	stty := func(arg string) error {
+		cmd := exec.Command("stty", arg)
+		cmd.Stdin = tty
+		return cmd.Run()
+	}
+	if err := stty("-echo"); err != nil {
+		return nil
+	}
+	return func() { stty("echo") }
+}
+
+// authLogout removes the stored API key of the profile.
+func authLogout(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	if _, err := parseArgs(flags, args, 0); err != nil {
+		return err
+	}
+
+	store, err := credentialStore()
+	if err != nil {
+		return err
+	}
+
+	return store.Delete(c.profileName)
+}
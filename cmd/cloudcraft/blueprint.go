@@ -0,0 +1,450 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/updater/cloudcraft-go"
+	"github.com/updater/cloudcraft-go/lint"
+	"github.com/updater/cloudcraft-go/sink"
+)
+
+var blueprintCommands = map[string]command{
+	"list":   {usage: "", run: blueprintList},
+	"get":    {usage: "<id>", run: blueprintGet},
+	"create": {usage: "-file <data.json>", run: blueprintCreate},
+	"update": {usage: "<id> -file <data.json>", run: blueprintUpdate},
+	"delete": {usage: "<id>", run: blueprintDelete},
+	"apply":  {usage: "-file <spec.json> [-dry-run]", run: blueprintApply},
+	"export": {usage: "<id> [-format png] [-o path] [-watch [-interval 1m]] [flags]", run: blueprintExport},
+	"diff":   {usage: "<id|file.json> <id|file.json> [-json]", run: blueprintDiff},
+	"lint":   {usage: "<id|file.json> [-rules rules.json] [-min-severity info]", run: blueprintLint},
+	"budget": {usage: "<id> [-format csv] [-o path] [flags]", run: blueprintBudget},
+}
+
+func blueprintList(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	if _, err := parseArgs(flags, args, 0); err != nil {
+		return err
+	}
+
+	blueprints, _, err := c.client.Blueprints.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	return printResult(c, blueprints)
+}
+
+func blueprintGet(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	args, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+
+	blueprint, _, err := c.client.Blueprints.Get(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	return printResult(c, blueprint)
+}
+
+func blueprintCreate(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	file := flags.String("file", "", "JSON file holding the blueprint data, - for standard input")
+	if _, err := parseArgs(flags, args, 0); err != nil {
+		return err
+	}
+
+	if *file == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	data := new(cloudcraft.BlueprintData)
+	if err := readJSONFile(*file, data); err != nil {
+		return err
+	}
+
+	blueprint, _, err := c.client.Blueprints.Create(ctx, &cloudcraft.BlueprintCreateRequest{Data: data})
+	if err != nil {
+		return err
+	}
+
+	return printResult(c, blueprint)
+}
+
+func blueprintUpdate(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	file := flags.String("file", "", "JSON file holding the blueprint data, - for standard input")
+	args, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+
+	if *file == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	data := new(cloudcraft.BlueprintData)
+	if err := readJSONFile(*file, data); err != nil {
+		return err
+	}
+
+	blueprint, _, err := c.client.Blueprints.Update(ctx, args[0], &cloudcraft.BlueprintUpdateRequest{Data: data})
+	if err != nil {
+		return err
+	}
+
+	return printResult(c, blueprint)
+}
+
+func blueprintDelete(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	args, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.client.Blueprints.Delete(ctx, args[0])
+	return err
+}
+
+func blueprintApply(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	file := flags.String("file", "", "JSON file holding the blueprint spec, its optional id and its data, - for standard input")
+	dryRun := flags.Bool("dry-run", false, "print the changes without making them")
+	if _, err := parseArgs(flags, args, 0); err != nil {
+		return err
+	}
+
+	if *file == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	spec := new(cloudcraft.BlueprintSpec)
+	if err := readJSONFile(*file, spec); err != nil {
+		return err
+	}
+
+	report, _, err := c.client.Blueprints.Apply(ctx, spec, &cloudcraft.ApplyOptions{DryRun: *dryRun})
+	if err != nil {
+		return err
+	}
+
+	if c.format != "" {
+		return printResult(c, report)
+	}
+
+	if report.Action != cloudcraft.ApplyUnchanged {
+		if err := printDiff(c, &cloudcraft.BlueprintDiff{Changes: report.Changes}); err != nil {
+			return err
+		}
+	}
+
+	status := string(report.Action)
+	if report.DryRun {
+		status = "would be " + status
+	}
+	if report.BlueprintId != "" {
+		status = report.BlueprintId + " " + status
+	}
+	_, err = fmt.Fprintln(c.stdout, "blueprint", status)
+	return err
+}
+
+func blueprintExport(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	format := flags.String("format", "png", "export format: svg, png, pdf or mxGraph")
+	output := flags.String("o", "", "output path (default standard output)")
+	watch := flags.Bool("watch", false, "re-export whenever the blueprint is updated, requires -o")
+	manifest := flags.String("manifest", "", "also write a JSON manifest of the export with its checksum to this path, requires -o")
+	interval := flags.Duration("interval", time.Minute, "polling interval in watch mode")
+	params := &cloudcraft.BlueprintExportParameters{}
+	flags.BoolVar(&params.Grid, "grid", false, "show grid")
+	flags.IntVar(&params.Height, "height", 0, "image height in pixels")
+	flags.BoolVar(&params.Landscape, "landscape", false, "landscape orientation")
+	flags.StringVar(&params.PaperSize, "paper-size", "", "paper size for PDF exports")
+	scale := flags.Float64("scale", 0, "image scale")
+	flags.BoolVar(&params.Transparent, "transparent", false, "transparent background")
+	flags.IntVar(&params.Width, "width", 0, "image width in pixels")
+	theme := flags.String("theme", "", "color theme: light or dark")
+	flags.StringVar(&params.Background, "background", "", "background color, e.g. #ffffff")
+	projection := flags.String("projection", "", "projection: isometric or 2d")
+	flags.BoolVar(&params.Label, "label", false, "show labels")
+	meta := &cloudcraft.PDFMetadata{}
+	flags.StringVar(&meta.Title, "title", "", "document title of PDF exports")
+	flags.StringVar(&meta.Author, "author", "", "document author of PDF exports")
+	flags.StringVar(&meta.Subject, "subject", "", "document subject of PDF exports")
+	args, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+
+	params.Scale = float32(*scale)
+	params.Theme = cloudcraft.ExportTheme(*theme)
+	params.Projection = cloudcraft.Projection(*projection)
+
+	exportRequest := &cloudcraft.BlueprintExportRequest{
+		Format:           *format,
+		ExportParameters: params,
+	}
+	if *meta != (cloudcraft.PDFMetadata{}) {
+		exportRequest.PDFMetadata = meta
+	}
+
+	if !*watch {
+		image, _, err := c.client.Blueprints.Export(ctx, args[0], exportRequest)
+		if err != nil {
+			return err
+		}
+
+		return writeArtifact(ctx, c, *output, *manifest, &sink.Artifact{
+			ContentType: image.ContentType,
+			Source:      &sink.Source{Kind: "blueprint", Id: args[0]},
+			Parameters:  params,
+		}, image.Content)
+	}
+
+	if *output == "" || *output == "-" || *interval <= 0 || *manifest != "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	return watchBlueprintExport(ctx, c, args[0], exportRequest, *output, *interval)
+}
+
+// watchBlueprintExport polls the blueprint every interval and exports it to
+// path whenever its updatedAt changes, until ctx is done. Errors are reported
+// and retried on the next poll so a transient failure does not stop the watch.
+func watchBlueprintExport(ctx context.Context, c *cli, blueprintID string, exportRequest *cloudcraft.BlueprintExportRequest, path string, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var exported bool
+	var lastUpdatedAt cloudcraft.Timestamp
+	for {
+		blueprint, _, err := c.client.Blueprints.Get(ctx, blueprintID)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			fmt.Fprintln(c.stderr, "cloudcraft:", err)
+		case !exported || !blueprint.UpdatedAt.Equal(lastUpdatedAt):
+			if err := exportBlueprintTo(ctx, c, blueprintID, exportRequest, path); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				fmt.Fprintln(c.stderr, "cloudcraft:", err)
+				break
+			}
+
+			exported = true
+			lastUpdatedAt = blueprint.UpdatedAt
+			fmt.Fprintf(c.stderr, "exported blueprint %s updated at %s to %s\n", blueprintID, lastUpdatedAt.Format(time.RFC3339), path)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// exportBlueprintTo exports a blueprint and atomically replaces path with the
+// result.
+func exportBlueprintTo(ctx context.Context, c *cli, blueprintID string, exportRequest *cloudcraft.BlueprintExportRequest, path string) error {
+	image, _, err := c.client.Blueprints.Export(ctx, blueprintID, exportRequest)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, image.Content)
+}
+
+func blueprintBudget(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	format := flags.String("format", "csv", "budget format: csv or xlsx")
+	output := flags.String("o", "", "output path (default standard output)")
+	params := &cloudcraft.BlueprintBudgetParameters{}
+	flags.StringVar(&params.Currency, "currency", "", "currency of the budget, e.g. USD")
+	flags.StringVar(&params.Period, "period", "", "budget period: hm, d, w, m or y")
+	flags.StringVar(&params.Rate, "rate", "", "pricing rate, e.g. ondemand")
+	args, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+
+	budget, _, err := c.client.Blueprints.BudgetWait(ctx, args[0], &cloudcraft.BlueprintBudgetRequest{
+		Format:           *format,
+		BudgetParameters: params,
+	}, &cloudcraft.BudgetWaitOptions{
+		Progress: func(p cloudcraft.BudgetProgress) {
+			fmt.Fprintf(c.stderr, "budget still generating, polling again in %s\n", p.Next)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeContent(c, *output, budget.Content)
+}
+
+func blueprintDiff(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	jsonOutput := flags.Bool("json", false, "print the changes as JSON, same as -output json")
+	args, err := parseArgs(flags, args, 2)
+	if err != nil {
+		return err
+	}
+
+	a, err := loadBlueprintData(ctx, c, args[0])
+	if err != nil {
+		return err
+	}
+
+	b, err := loadBlueprintData(ctx, c, args[1])
+	if err != nil {
+		return err
+	}
+
+	diff := cloudcraft.Diff(a, b)
+
+	if *jsonOutput {
+		c.format = "json"
+	}
+
+	if c.format != "" {
+		return printResult(c, diff)
+	}
+
+	return printDiff(c, diff)
+}
+
+// loadBlueprintData reads the blueprint data from a local JSON file if ref
+// names an existing file, else fetches the blueprint with ID ref. A file may
+// hold either a whole blueprint or only its data.
+func loadBlueprintData(ctx context.Context, c *cli, ref string) (*cloudcraft.BlueprintData, error) {
+	if _, err := os.Stat(ref); err != nil {
+		blueprint, _, err := c.client.Blueprints.Get(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+
+		return blueprint.Data, nil
+	}
+
+	var doc struct {
+		cloudcraft.BlueprintData
+		Data *cloudcraft.BlueprintData `json:"data"`
+	}
+	if err := readJSONFile(ref, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %v", ref, err)
+	}
+
+	if doc.Data != nil {
+		return doc.Data, nil
+	}
+
+	return &doc.BlueprintData, nil
+}
+
+// printDiff prints a human-readable report of diff, one change per line.
+func printDiff(c *cli, diff *cloudcraft.BlueprintDiff) error {
+	if diff.Empty() {
+		_, err := fmt.Fprintln(c.stdout, "no changes")
+		return err
+	}
+
+	for _, change := range diff.Changes {
+		var line string
+		switch change.Kind {
+		case cloudcraft.ChangeAdded:
+			line = fmt.Sprintf("+ %s %s", change.Collection, change.Id)
+		case cloudcraft.ChangeRemoved:
+			line = fmt.Sprintf("- %s %s", change.Collection, change.Id)
+		default:
+			target := change.Field
+			if change.Collection != "" {
+				target = fmt.Sprintf("%s %s %s", change.Collection, change.Id, change.Field)
+			}
+			line = fmt.Sprintf("~ %s: %s -> %s", target, formatValue(change.Old), formatValue(change.New))
+		}
+
+		if _, err := fmt.Fprintln(c.stdout, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "(none)"
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func blueprintLint(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+	rules := flags.String("rules", "", "JSON file configuring rule severities and deprecated node types")
+	minSeverity := flags.String("min-severity", string(lint.SeverityInfo), "only report findings at least this severe")
+	args, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+
+	min := lint.Severity(*minSeverity)
+	if !min.Valid() {
+		flags.Usage()
+		return errUsage
+	}
+
+	cfg := new(lint.Config)
+	if *rules != "" {
+		if err := readJSONFile(*rules, cfg); err != nil {
+			return fmt.Errorf("%s: %v", *rules, err)
+		}
+	}
+
+	data, err := loadBlueprintData(ctx, c, args[0])
+	if err != nil {
+		return err
+	}
+
+	findings, err := lint.Lint(data, cfg)
+	if err != nil {
+		return err
+	}
+
+	reported := []lint.Finding{}
+	for _, f := range findings {
+		if f.Severity.AtLeast(min) {
+			reported = append(reported, f)
+		}
+	}
+
+	if c.format != "" {
+		err = printResult(c, reported)
+	} else {
+		for _, f := range reported {
+			if _, err = fmt.Fprintln(c.stdout, f); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if n := lint.Count(findings, lint.SeverityError); n > 0 {
+		return fmt.Errorf("blueprint %s has %d lint errors", args[0], n)
+	}
+
+	return nil
+}
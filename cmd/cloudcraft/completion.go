@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+var completionCommands = map[string]command{
+	"bash": {usage: "", offline: true, run: completionFor(writeBashCompletion)},
+	"zsh":  {usage: "", offline: true, run: completionFor(writeZshCompletion)},
+	"fish": {usage: "", offline: true, run: completionFor(writeFishCompletion)},
+}
+
+func completionFor(write func(io.Writer) error) func(context.Context, *cli, *flag.FlagSet, []string) error {
+	return func(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error {
+		if _, err := parseArgs(flags, args, 0); err != nil {
+			return err
+		}
+		return write(c.stdout)
+	}
+}
+
+func sortedKeys(m map[string]command) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func groupNames() []string {
+	names := make([]string, 0, len(commandGroups))
+	for name := range commandGroups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func writeBashCompletion(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# bash completion for cloudcraft, load with: source <(cloudcraft completion bash)\n")
+	b.WriteString("_cloudcraft() {\n")
+	b.WriteString("  local cur=${COMP_WORDS[COMP_CWORD]}\n")
+	b.WriteString("  case $COMP_CWORD in\n")
+	fmt.Fprintf(&b, "    1) COMPREPLY=($(compgen -W %q -- \"$cur\")) ;;\n", strings.Join(groupNames(), " "))
+	b.WriteString("    2)\n      case ${COMP_WORDS[1]} in\n")
+	for _, group := range groupNames() {
+		fmt.Fprintf(&b, "        %s) COMPREPLY=($(compgen -W %q -- \"$cur\")) ;;\n", group, strings.Join(sortedKeys(commandGroups[group]), " "))
+	}
+	b.WriteString("      esac ;;\n")
+	b.WriteString("    *) COMPREPLY=($(compgen -f -- \"$cur\")) ;;\n")
+	b.WriteString("  esac\n")
+	b.WriteString("}\n")
+	b.WriteString("complete -F _cloudcraft cloudcraft\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeZshCompletion(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("#compdef cloudcraft\n")
+	b.WriteString("# zsh completion for cloudcraft, load with: source <(cloudcraft completion zsh)\n")
+	b.WriteString("_cloudcraft() {\n")
+	b.WriteString("  case $CURRENT in\n")
+	fmt.Fprintf(&b, "    2) compadd %s ;;\n", strings.Join(groupNames(), " "))
+	b.WriteString("    3)\n      case $words[2] in\n")
+	for _, group := range groupNames() {
+		fmt.Fprintf(&b, "        %s) compadd %s ;;\n", group, strings.Join(sortedKeys(commandGroups[group]), " "))
+	}
+	b.WriteString("      esac ;;\n")
+	b.WriteString("    *) _files ;;\n")
+	b.WriteString("  esac\n")
+	b.WriteString("}\n")
+	b.WriteString("if [ \"$funcstack[1]\" = \"_cloudcraft\" ]; then\n  _cloudcraft \"$@\"\nelse\n  compdef _cloudcraft cloudcraft\nfi\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeFishCompletion(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# fish completion for cloudcraft, load with: cloudcraft completion fish | source\n")
+	fmt.Fprintf(&b, "complete -c cloudcraft -n '__fish_use_subcommand' -f -a '%s'\n", strings.Join(groupNames(), " "))
+	for _, group := range groupNames() {
+		subcommands := strings.Join(sortedKeys(commandGroups[group]), " ")
+		fmt.Fprintf(&b, "complete -c cloudcraft -n '__fish_seen_subcommand_from %s; and not __fish_seen_subcommand_from %s' -f -a '%s'\n", group, subcommands, subcommands)
+	}
+	fmt.Fprintf(&b, "complete -c cloudcraft -l output -x -a '%s' -d 'output format'\n", strings.Join(outputFormats, " "))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
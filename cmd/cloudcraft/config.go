@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/updater/cloudcraft-go"
+	"github.com/updater/cloudcraft-go/credentials"
+)
+
+const (
+	envAPIKey  = cloudcraft.EnvAPIKey
+	envBaseURL = cloudcraft.EnvBaseURL
+	envProfile = "CLOUDCRAFT_PROFILE"
+	envConfig  = "CLOUDCRAFT_CONFIG"
+
+	defaultProfile = "default"
+	cliUserAgent   = "cloudcraft-cli"
+)
+
+// profile holds the settings of a configuration profile.
+type profile struct {
+	name    string
+	apiKey  string
+	baseURL string
+}
+
+// loadProfile reads the named profile from the configuration file at path,
+// then applies the environment overrides. Empty arguments fall back to the
+// environment and to the defaults. A missing configuration file is not an
+// error as long as the API key is set in the environment.
+func loadProfile(path, name string) (*profile, error) {
+	name = resolveProfileName(name)
+
+	explicitPath := path != "" || os.Getenv(envConfig) != ""
+	if path == "" {
+		path = os.Getenv(envConfig)
+	}
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			path = filepath.Join(home, ".cloudcraft", "config")
+		}
+	}
+
+	p := &profile{name: name}
+
+	if path != "" {
+		sections, err := readConfig(path)
+		switch {
+		case err == nil:
+			if s, ok := sections[name]; ok {
+				p.apiKey = s["api_key"]
+				p.baseURL = s["base_url"]
+			} else if name != defaultProfile {
+				return nil, fmt.Errorf("profile %q not found in %s", name, path)
+			}
+		case os.IsNotExist(err) && !explicitPath:
+		default:
+			return nil, err
+		}
+	}
+
+	if v := os.Getenv(envAPIKey); v != "" {
+		p.apiKey = v
+	}
+	if v := os.Getenv(envBaseURL); v != "" {
+		p.baseURL = v
+	}
+
+	if p.apiKey == "" {
+		if store, err := credentialStore(); err == nil {
+			apiKey, err := store.Get(name)
+			if err != nil && !errors.Is(err, credentials.ErrNotFound) {
+				return nil, err
+			}
+			p.apiKey = apiKey
+		}
+	}
+
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("no API key: set %s, api_key in profile %q or run cloudcraft auth login", envAPIKey, name)
+	}
+
+	return p, nil
+}
+
+// resolveProfileName returns the profile to use, name if not empty, or else
+// $CLOUDCRAFT_PROFILE or the default profile.
+func resolveProfileName(name string) string {
+	if name == "" {
+		name = os.Getenv(envProfile)
+	}
+	if name == "" {
+		name = defaultProfile
+	}
+	return name
+}
+
+// newClient returns a Cloudcraft client authenticated with the profile.
+func (p *profile) newClient() (*cloudcraft.Client, error) {
+	opts := []cloudcraft.ClientOpt{
+		cloudcraft.SetUserAgent(cliUserAgent),
+		cloudcraft.SetRequestHeaders(map[string]string{
+			"Authorization": "Bearer " + p.apiKey,
+		}),
+	}
+
+	if p.baseURL != "" {
+		opts = append(opts, cloudcraft.SetBaseURL(p.baseURL))
+	}
+
+	return cloudcraft.New(nil, opts...)
+}
+
+// readConfig parses an INI style configuration file into its sections.
+func readConfig(path string) (map[string]map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sections := make(map[string]map[string]string)
+	var section map[string]string
+
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			section = make(map[string]string)
+			sections[name] = section
+			continue
+		}
+
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 || section == nil {
+			return nil, fmt.Errorf("%s:%d: invalid line", path, lineNo)
+		}
+
+		section[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+
+	return sections, scanner.Err()
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+)
+
+// outputFormats lists the values accepted by the -output flag.
+var outputFormats = []string{"json", "yaml", "table"}
+
+func validOutputFormat(format string) bool {
+	if format == "" {
+		return true
+	}
+	for _, f := range outputFormats {
+		if format == f {
+			return true
+		}
+	}
+	return false
+}
+
+// orderedObject is a decoded JSON object keeping the order of its members,
+// so YAML and table output list fields in the order of the API types.
+type orderedObject []orderedMember
+
+type orderedMember struct {
+	key   string
+	value interface{}
+}
+
+// toOrdered converts v to its JSON representation, decoding objects as
+// orderedObject, arrays as []interface{} and numbers as json.Number.
+func toOrdered(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return decodeOrdered(dec)
+}
+
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		obj := orderedObject{}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			obj = append(obj, orderedMember{key: keyTok.(string), value: value})
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []interface{}{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err := dec.Token()
+		return arr, err
+	}
+
+	return tok, nil
+}
+
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case orderedObject, []interface{}:
+		return false
+	}
+	return true
+}
+
+var plainYAMLString = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_./-]*$`)
+
+// yamlScalar formats a scalar as YAML, quoting strings that would otherwise
+// be read back as another type or contain special characters.
+func yamlScalar(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		switch strings.ToLower(v) {
+		case "true", "false", "yes", "no", "on", "off", "null", "y", "n", "~":
+			return fmt.Sprintf("%q", v)
+		}
+		if plainYAMLString.MatchString(v) {
+			return v
+		}
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+// writeYAML writes an ordered JSON value as a YAML document.
+func writeYAML(w io.Writer, v interface{}) error {
+	var buf bytes.Buffer
+	if isScalar(v) || isEmpty(v) {
+		buf.WriteString(yamlInline(v) + "\n")
+	} else {
+		writeYAMLValue(&buf, v, 0)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func isEmpty(v interface{}) bool {
+	switch v := v.(type) {
+	case orderedObject:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// yamlInline formats scalars and empty collections on a single line.
+func yamlInline(v interface{}) string {
+	switch v.(type) {
+	case orderedObject:
+		return "{}"
+	case []interface{}:
+		return "[]"
+	}
+	return yamlScalar(v)
+}
+
+func writeYAMLValue(buf *bytes.Buffer, v interface{}, indent int) {
+	pad := strings.Repeat("  ", indent)
+
+	switch v := v.(type) {
+	case orderedObject:
+		for _, m := range v {
+			if isScalar(m.value) || isEmpty(m.value) {
+				fmt.Fprintf(buf, "%s%s: %s\n", pad, yamlScalar(m.key), yamlInline(m.value))
+				continue
+			}
+
+			fmt.Fprintf(buf, "%s%s:\n", pad, yamlScalar(m.key))
+			writeYAMLValue(buf, m.value, indent+1)
+		}
+	case []interface{}:
+		for _, item := range v {
+			if isScalar(item) || isEmpty(item) {
+				fmt.Fprintf(buf, "%s- %s\n", pad, yamlInline(item))
+				continue
+			}
+
+			// Render the item one level deeper, then replace the leading
+			// indentation of its first line with the list marker.
+			var itemBuf bytes.Buffer
+			writeYAMLValue(&itemBuf, item, indent+1)
+			buf.WriteString(pad + "- ")
+			buf.Write(bytes.TrimPrefix(itemBuf.Bytes(), []byte(pad+"  ")))
+		}
+	}
+}
+
+// writeTable writes an ordered JSON value as an aligned text table. Lists of
+// objects get one row per item and one column per scalar field, a single
+// object is printed as field/value pairs. An object wrapping a single list,
+// such as a diff, is printed as that list.
+func writeTable(w io.Writer, v interface{}) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	if obj, ok := v.(orderedObject); ok && len(obj) == 1 {
+		if list, ok := obj[0].value.([]interface{}); ok {
+			v = list
+		}
+	}
+
+	switch v := v.(type) {
+	case []interface{}:
+		var columns []string
+		seen := make(map[string]bool)
+		for _, item := range v {
+			obj, ok := item.(orderedObject)
+			if !ok {
+				columns = nil
+				break
+			}
+			for _, m := range obj {
+				if isScalar(m.value) && !seen[m.key] {
+					seen[m.key] = true
+					columns = append(columns, m.key)
+				}
+			}
+		}
+
+		if columns == nil {
+			fmt.Fprintln(tw, "VALUE")
+			for _, item := range v {
+				fmt.Fprintln(tw, tableCell(item))
+			}
+			break
+		}
+
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+		for _, item := range v {
+			values := make(map[string]interface{})
+			for _, m := range item.(orderedObject) {
+				values[m.key] = m.value
+			}
+
+			cells := make([]string, len(columns))
+			for i, column := range columns {
+				cells[i] = tableCell(values[column])
+			}
+			fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+	case orderedObject:
+		fmt.Fprintln(tw, "FIELD\tVALUE")
+		for _, m := range v {
+			fmt.Fprintf(tw, "%s\t%s\n", m.key, tableCell(m.value))
+		}
+	default:
+		fmt.Fprintln(tw, tableCell(v))
+	}
+
+	return tw.Flush()
+}
+
+// tableCell formats a value for a table cell, nested values as compact JSON.
+func tableCell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case orderedObject, []interface{}:
+		return compactJSON(v)
+	}
+	return fmt.Sprint(v)
+}
+
+func compactJSON(v interface{}) string {
+	var buf bytes.Buffer
+	writeCompactJSON(&buf, v)
+	return buf.String()
+}
+
+func writeCompactJSON(buf *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case orderedObject:
+		buf.WriteByte('{')
+		for i, m := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(m.key)
+			buf.Write(key)
+			buf.WriteByte(':')
+			writeCompactJSON(buf, m.value)
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCompactJSON(buf, item)
+		}
+		buf.WriteByte(']')
+	default:
+		b, _ := json.Marshal(v)
+		buf.Write(b)
+	}
+}
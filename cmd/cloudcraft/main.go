@@ -0,0 +1,219 @@
+// Command cloudcraft is a command line interface for the Cloudcraft API.
+//
+// Usage:
+//
+//	cloudcraft [-profile name] [-config path] <command> <subcommand> [flags] [args]
+//
+// The API key is read from the CLOUDCRAFT_API_KEY environment variable or
+// from a profile of the configuration file, ~/.cloudcraft/config by default:
+//
+//	[default]
+//	api_key = ...
+//
+//	[staging]
+//	api_key = ...
+//	base_url = https://api.cloudcraft.co/
+//
+// Profiles without api_key use the API key stored by "cloudcraft auth
+// login", in the keychain of the system or, without keychain, in
+// ~/.cloudcraft/credentials encrypted with CLOUDCRAFT_PASSPHRASE.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/updater/cloudcraft-go"
+)
+
+// errUsage is returned by commands invoked with invalid arguments, after the
+// usage has been printed.
+var errUsage = errors.New("invalid usage")
+
+// cli holds the state shared by all commands.
+type cli struct {
+	client *cloudcraft.Client
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	// Name of the configuration profile, selected with the -profile flag.
+	profileName string
+
+	// Output format of results, selected with the -output flag.
+	format string
+}
+
+// command is a subcommand of a command group, e.g. "list" of "account".
+// Offline commands do not need an API client.
+type command struct {
+	usage   string
+	offline bool
+	run     func(ctx context.Context, c *cli, flags *flag.FlagSet, args []string) error
+}
+
+// commandGroups lists the top-level commands of the CLI. It is set in init
+// as the completion commands refer to it.
+var commandGroups map[string]map[string]command
+
+func init() {
+	commandGroups = map[string]map[string]command{
+		"account":    accountCommands,
+		"auth":       authCommands,
+		"blueprint":  blueprintCommands,
+		"completion": completionCommands,
+	}
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+
+	if err == errUsage {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "cloudcraft:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("cloudcraft", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	profileName := flags.String("profile", "", "configuration profile to use (default $CLOUDCRAFT_PROFILE or \"default\")")
+	configPath := flags.String("config", "", "path of the configuration file (default $CLOUDCRAFT_CONFIG or ~/.cloudcraft/config)")
+	flags.Usage = func() { printUsage(flags) }
+
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return errUsage
+	}
+
+	group, ok := commandGroups[flags.Arg(0)]
+	if !ok {
+		flags.Usage()
+		return errUsage
+	}
+
+	cmd, ok := group[flags.Arg(1)]
+	if !ok {
+		flags.Usage()
+		return errUsage
+	}
+
+	c := &cli{stdin: os.Stdin, stdout: stdout, stderr: stderr, profileName: resolveProfileName(*profileName)}
+
+	if !cmd.offline {
+		p, err := loadProfile(*configPath, *profileName)
+		if err != nil {
+			return err
+		}
+
+		if c.client, err = p.newClient(); err != nil {
+			return err
+		}
+	}
+
+	cmdFlags := newFlagSet(c, flags.Arg(0)+" "+flags.Arg(1), cmd.usage)
+	cmdFlags.StringVar(&c.format, "output", "", "output format of results: "+strings.Join(outputFormats, ", ")+" (default json)")
+
+	return cmd.run(ctx, c, cmdFlags, flags.Args()[2:])
+}
+
+func printUsage(flags *flag.FlagSet) {
+	w := flags.Output()
+	fmt.Fprintln(w, "Usage: cloudcraft [flags] <command> <subcommand> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	groupNames := make([]string, 0, len(commandGroups))
+	for name := range commandGroups {
+		groupNames = append(groupNames, name)
+	}
+	sort.Strings(groupNames)
+
+	for _, groupName := range groupNames {
+		group := commandGroups[groupName]
+		names := make([]string, 0, len(group))
+		for name := range group {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Fprintln(w, strings.TrimRight(fmt.Sprintf("  %s %s %s", groupName, name, group[name].usage), " "))
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Flags:")
+	flags.PrintDefaults()
+}
+
+// newFlagSet returns a FlagSet for a subcommand, printing its usage on error.
+func newFlagSet(c *cli, name, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: cloudcraft %s %s\n", name, usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseArgs parses the flags of a subcommand, which may be interspersed with
+// its positional arguments, and checks it received exactly n positional
+// arguments. The positional arguments are returned.
+func parseArgs(flags *flag.FlagSet, args []string, n int) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, errUsage
+		}
+
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if f := flags.Lookup("output"); f != nil && !validOutputFormat(f.Value.String()) {
+		fmt.Fprintf(flags.Output(), "invalid -output %q\n", f.Value.String())
+		flags.Usage()
+		return nil, errUsage
+	}
+
+	if len(positional) != n {
+		flags.Usage()
+		return nil, errUsage
+	}
+
+	return positional, nil
+}
+
+// splitList splits a comma separated flag value, ignoring empty entries.
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/updater/cloudcraft-go/sink"
+)
+
+// printResult writes v to the standard output in the format selected with
+// the -output flag.
+func printResult(c *cli, v interface{}) error {
+	switch c.format {
+	case "", "json":
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "yaml":
+		doc, err := toOrdered(v)
+		if err != nil {
+			return err
+		}
+		return writeYAML(c.stdout, doc)
+	case "table":
+		doc, err := toOrdered(v)
+		if err != nil {
+			return err
+		}
+		return writeTable(c.stdout, doc)
+	}
+
+	return fmt.Errorf("unknown output format %q", c.format)
+}
+
+// writeContent writes the content of an export, snapshot or budget to path,
+// or to the standard output if path is empty or "-".
+func writeContent(c *cli, path string, content io.Reader) error {
+	if path == "" || path == "-" {
+		_, err := io.Copy(c.stdout, content)
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, content); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// writeArtifact writes content to path like writeContent, and if
+// manifestPath is not empty a manifest describing artifact and its checksum
+// to manifestPath.
+func writeArtifact(ctx context.Context, c *cli, path, manifestPath string, artifact *sink.Artifact, content io.Reader) error {
+	if manifestPath == "" {
+		return writeContent(c, path, content)
+	}
+	if path == "" || path == "-" {
+		return errors.New("-manifest requires -o")
+	}
+
+	ms := sink.NewManifestSink(sink.NewDir(filepath.Dir(path)))
+	artifact.Name = filepath.Base(path)
+	if err := ms.PutArtifact(ctx, artifact, content); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(ms.Manifest(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(manifestPath, bytes.NewReader(append(data, '\n')))
+}
+
+// writeFileAtomic writes content to a temporary file next to path, then
+// renames it over path so readers never observe a partial file.
+func writeFileAtomic(path string, content io.Reader) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, content); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// readJSONFile decodes the JSON file at path into v. A path of "-" reads the
+// standard input.
+func readJSONFile(path string, v interface{}) error {
+	if path == "-" {
+		return json.NewDecoder(os.Stdin).Decode(v)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewDecoder(f).Decode(v)
+}
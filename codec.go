@@ -0,0 +1,104 @@
+package cloudcraft
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+)
+
+// Codec encodes request bodies and decodes response bodies of the API. It
+// allows replacing encoding/json with a faster implementation, such as
+// json-iterator or segmentio/encoding, when syncing many blueprints.
+//
+// Implementations must produce and accept the same documents as
+// encoding/json, honoring the json struct tags and the json.Marshaler and
+// json.Unmarshaler interfaces.
+type Codec interface {
+	Encode(w io.Writer, v interface{}) error
+	Decode(r io.Reader, v interface{}) error
+}
+
+// JSONCodec is the Codec based on encoding/json, used by default.
+type JSONCodec struct{}
+
+var _ Codec = JSONCodec{}
+
+// Encode implements Codec.
+func (JSONCodec) Encode(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// Decode implements Codec.
+func (JSONCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// XMLCodec is the Codec based on encoding/xml, used by default for
+// application/xml and text/xml content such as mxGraph exports.
+type XMLCodec struct{}
+
+var _ Codec = XMLCodec{}
+
+// Encode implements Codec.
+func (XMLCodec) Encode(w io.Writer, v interface{}) error {
+	return xml.NewEncoder(w).Encode(v)
+}
+
+// Decode implements Codec.
+func (XMLCodec) Decode(r io.Reader, v interface{}) error {
+	return xml.NewDecoder(r).Decode(v)
+}
+
+// defaultCodecs returns the codecs of the media types other than JSON
+// supported by default.
+func defaultCodecs() map[string]Codec {
+	return map[string]Codec{
+		"application/xml": XMLCodec{},
+		"text/xml":        XMLCodec{},
+	}
+}
+
+// SetMediaTypeCodec is a client option for the Codec of the bodies of the
+// given media type, such as application/xml. Response bodies are decoded with
+// the codec of their Content-Type, request bodies are encoded with the codec
+// of the ContentType request option. Other bodies use the codec set with
+// SetCodec.
+func SetMediaTypeCodec(mediaType string, codec Codec) ClientOpt {
+	return func(c *Client) error {
+		if codec == nil {
+			return NewArgError("codec", "cannot be nil")
+		}
+
+		t, _, err := mime.ParseMediaType(mediaType)
+		if err != nil {
+			return NewArgError("mediaType", "is not a valid media type")
+		}
+
+		c.codecs[t] = codec
+		return nil
+	}
+}
+
+// codecFor returns the codec of bodies with the given Content-Type.
+func (c *Client) codecFor(contentType string) Codec {
+	if t, _, err := mime.ParseMediaType(contentType); err == nil {
+		if codec, ok := c.codecs[t]; ok {
+			return codec
+		}
+	}
+	return c.codec
+}
+
+// SetCodec is a client option for the Codec of request and response bodies,
+// JSON ones unless other media types are set with SetMediaTypeCodec.
+func SetCodec(codec Codec) ClientOpt {
+	return func(c *Client) error {
+		if codec == nil {
+			return NewArgError("codec", "cannot be nil")
+		}
+
+		c.codec = codec
+		return nil
+	}
+}
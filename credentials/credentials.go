@@ -0,0 +1,59 @@
+// Package credentials stores the API keys of Cloudcraft configuration
+// profiles outside of the configuration file, in the keychain of the
+// operating system where available, or else in a file encrypted with a
+// passphrase, so API keys are not left in plaintext on laptops and CI
+// runners.
+package credentials
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+var (
+	// ErrNotFound is returned by Store.Get when no API key is stored for the
+	// profile.
+	ErrNotFound = errors.New("credentials: no API key stored for the profile")
+
+	// ErrUnavailable is returned by NewKeychain when the operating system
+	// has no supported keychain, and by Default when neither a keychain nor
+	// a passphrase are available.
+	ErrUnavailable = errors.New("credentials: no keychain available")
+)
+
+// Store stores the API keys of profiles.
+type Store interface {
+	Get(profile string) (string, error)
+	Set(profile, apiKey string) error
+	Delete(profile string) error
+}
+
+// DefaultPath returns the path of the encrypted credentials file,
+// ~/.cloudcraft/credentials.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cloudcraft", "credentials"), nil
+}
+
+// Default returns the keychain of the operating system if available, or
+// else the encrypted file at DefaultPath, encrypted with passphrase. It
+// returns ErrUnavailable if there is no keychain and passphrase is empty.
+func Default(passphrase string) (Store, error) {
+	if k, err := NewKeychain(); err == nil {
+		return k, nil
+	}
+
+	if passphrase == "" {
+		return nil, ErrUnavailable
+	}
+
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return NewFile(path, passphrase)
+}
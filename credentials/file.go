@@ -0,0 +1,241 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	fileVersion = 1
+
+	// pbkdf2Iterations is the number of PBKDF2-HMAC-SHA256 iterations
+	// deriving the key of a file from its passphrase.
+	pbkdf2Iterations = 600000
+
+	saltSize = 16
+	keySize  = 32
+)
+
+// ErrPassphrase is returned when a credentials file cannot be decrypted
+// with the given passphrase.
+var ErrPassphrase = errors.New("credentials: wrong passphrase or corrupted file")
+
+// encryptedFile is the content of a credentials file. Data is the JSON
+// encoding of the API keys by profile, encrypted with AES-256-GCM.
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// File is a Store keeping API keys in a file encrypted with a key derived
+// from a passphrase. The whole file is rewritten, with a new salt and
+// nonce, on each change.
+type File struct {
+	path       string
+	passphrase string
+
+	mu sync.Mutex
+}
+
+var _ Store = &File{}
+
+// NewFile returns the Store of the credentials file at path, which is
+// created on the first Set.
+func NewFile(path, passphrase string) (*File, error) {
+	if passphrase == "" {
+		return nil, errors.New("credentials: passphrase cannot be empty")
+	}
+	return &File{path: path, passphrase: passphrase}, nil
+}
+
+// Get returns the API key of profile.
+func (f *File) Get(profile string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	keys, err := f.read()
+	if err != nil {
+		return "", err
+	}
+
+	apiKey, ok := keys[profile]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return apiKey, nil
+}
+
+// Set stores the API key of profile, replacing any previous one.
+func (f *File) Set(profile, apiKey string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	keys, err := f.read()
+	if err != nil {
+		return err
+	}
+
+	keys[profile] = apiKey
+	return f.write(keys)
+}
+
+// Delete removes the API key of profile, if any.
+func (f *File) Delete(profile string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	keys, err := f.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := keys[profile]; !ok {
+		return nil
+	}
+
+	delete(keys, profile)
+	return f.write(keys)
+}
+
+// read decrypts the API keys of the file, none if it does not exist.
+func (f *File) read() (map[string]string, error) {
+	raw, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return make(map[string]string), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ef encryptedFile
+	if err := json.Unmarshal(raw, &ef); err != nil {
+		return nil, fmt.Errorf("credentials: %s: %w", f.path, err)
+	}
+	if ef.Version != fileVersion || ef.KDF != "pbkdf2-sha256" || ef.Iterations < 1 {
+		return nil, fmt.Errorf("credentials: %s: unsupported version %d", f.path, ef.Version)
+	}
+
+	gcm, err := newGCM(pbkdf2([]byte(f.passphrase), ef.Salt, ef.Iterations, keySize))
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, ef.Nonce, ef.Data, nil)
+	if err != nil {
+		return nil, ErrPassphrase
+	}
+
+	keys := make(map[string]string)
+	if err := json.Unmarshal(plaintext, &keys); err != nil {
+		return nil, ErrPassphrase
+	}
+	return keys, nil
+}
+
+// write encrypts keys to the file, atomically and readable by its owner
+// only.
+func (f *File) write(keys map[string]string) error {
+	plaintext, err := json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+
+	ef := encryptedFile{
+		Version:    fileVersion,
+		KDF:        "pbkdf2-sha256",
+		Iterations: pbkdf2Iterations,
+		Salt:       make([]byte, saltSize),
+	}
+	if _, err := rand.Read(ef.Salt); err != nil {
+		return err
+	}
+
+	gcm, err := newGCM(pbkdf2([]byte(f.passphrase), ef.Salt, ef.Iterations, keySize))
+	if err != nil {
+		return err
+	}
+
+	ef.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(ef.Nonce); err != nil {
+		return err
+	}
+	ef.Data = gcm.Seal(nil, ef.Nonce, plaintext, nil)
+
+	raw, err := json.Marshal(ef)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), "."+filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2 derives a key of keyLen bytes from password and salt with
+// PBKDF2-HMAC-SHA256, as specified by RFC 8018.
+func pbkdf2(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	blocks := (keyLen + hashLen - 1) / hashLen
+
+	key := make([]byte, 0, blocks*hashLen)
+	u := make([]byte, hashLen)
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		var counter [4]byte
+		binary.BigEndian.PutUint32(counter[:], uint32(block))
+		prf.Write(counter[:])
+		u = prf.Sum(u[:0])
+
+		t := make([]byte, hashLen)
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
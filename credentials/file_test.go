@@ -0,0 +1,166 @@
+package credentials
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// The test vectors of PBKDF2-HMAC-SHA256 of RFC 7914, section 11.
+func TestPBKDF2(t *testing.T) {
+	tests := []struct {
+		password, salt string
+		iterations     int
+		want           string
+	}{
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{"Password", "NaCl", 80000, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+	}
+
+	for _, tt := range tests {
+		got := hex.EncodeToString(pbkdf2([]byte(tt.password), []byte(tt.salt), tt.iterations, 64))
+		if got != tt.want {
+			t.Errorf("pbkdf2(%q, %q, %d) = %s, want %s", tt.password, tt.salt, tt.iterations, got, tt.want)
+		}
+	}
+}
+
+func newTestFile(t *testing.T) *File {
+	t.Helper()
+
+	f, err := NewFile(filepath.Join(t.TempDir(), "credentials"), "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestFileRoundTrip(t *testing.T) {
+	f := newTestFile(t)
+
+	if _, err := f.Get("default"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() of a missing file returned %v, want ErrNotFound", err)
+	}
+
+	if err := f.Set("default", "key-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Set("prod", "key-2"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A File of the same path and passphrase reads the keys back.
+	g, err := NewFile(f.path, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for profile, want := range map[string]string{"default": "key-1", "prod": "key-2"} {
+		if got, err := g.Get(profile); err != nil || got != want {
+			t.Errorf("Get(%q) = %q, %v, want %q", profile, got, err, want)
+		}
+	}
+
+	if err := g.Delete("default"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Get("default"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() of a deleted profile returned %v, want ErrNotFound", err)
+	}
+	if got, err := f.Get("prod"); err != nil || got != "key-2" {
+		t.Errorf("Get(prod) = %q, %v, want key-2", got, err)
+	}
+	if err := f.Delete("missing"); err != nil {
+		t.Errorf("Delete() of a missing profile returned %v", err)
+	}
+}
+
+func TestFileWrongPassphrase(t *testing.T) {
+	f := newTestFile(t)
+	if err := f.Set("default", "key"); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := NewFile(f.path, "wrong")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Get("default"); !errors.Is(err, ErrPassphrase) {
+		t.Errorf("Get() with a wrong passphrase returned %v, want ErrPassphrase", err)
+	}
+}
+
+// modify rewrites the encrypted file of f with change applied.
+func modify(t *testing.T, f *File, change func(*encryptedFile)) {
+	t.Helper()
+
+	raw, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ef encryptedFile
+	if err := json.Unmarshal(raw, &ef); err != nil {
+		t.Fatal(err)
+	}
+	change(&ef)
+	if raw, err = json.Marshal(ef); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(f.path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileTampered(t *testing.T) {
+	f := newTestFile(t)
+	if err := f.Set("default", "key"); err != nil {
+		t.Fatal(err)
+	}
+
+	modify(t, f, func(ef *encryptedFile) { ef.Data[0] ^= 1 })
+	if _, err := f.Get("default"); !errors.Is(err, ErrPassphrase) {
+		t.Errorf("Get() of a tampered file returned %v, want ErrPassphrase", err)
+	}
+}
+
+func TestFileUnknownVersion(t *testing.T) {
+	f := newTestFile(t)
+	if err := f.Set("default", "key"); err != nil {
+		t.Fatal(err)
+	}
+
+	modify(t, f, func(ef *encryptedFile) { ef.Version = fileVersion + 1 })
+	_, err := f.Get("default")
+	if err == nil || errors.Is(err, ErrPassphrase) {
+		t.Errorf("Get() of an unknown version returned %v, want an unsupported version error", err)
+	}
+}
+
+func TestFileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on Windows")
+	}
+
+	f := newTestFile(t)
+	if err := f.Set("default", "key"); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("file mode = %o, want 600", mode)
+	}
+}
+
+func TestNewFileEmptyPassphrase(t *testing.T) {
+	if _, err := NewFile("credentials", ""); err == nil {
+		t.Error("NewFile() with an empty passphrase returned no error")
+	}
+}
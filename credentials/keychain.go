@@ -0,0 +1,148 @@
+package credentials
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// service is the name API keys are stored under in the keychain.
+const service = "cloudcraft"
+
+// Keychain is a Store keeping API keys in the keychain of the operating
+// system, through the security command on macOS and secret-tool, of
+// libsecret, on Linux.
+type Keychain struct {
+	tool string
+}
+
+var _ Store = &Keychain{}
+
+// NewKeychain returns the keychain of the operating system, or
+// ErrUnavailable if it is not supported or its command is not installed.
+func NewKeychain() (*Keychain, error) {
+	var tool string
+	switch runtime.GOOS {
+	case "darwin":
+		tool = "security"
+	case "linux", "freebsd", "openbsd":
+		tool = "secret-tool"
+	default:
+		return nil, ErrUnavailable
+	}
+
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return nil, ErrUnavailable
+	}
+	return &Keychain{tool: path}, nil
+}
+
+// Get returns the API key of profile.
+func (k *Keychain) Get(profile string) (string, error) {
+	var out []byte
+	var err error
+	if k.isSecurity() {
+		out, err = k.run(nil, "find-generic-password", "-s", service, "-a", profile, "-w")
+	} else {
+		out, err = k.run(nil, "lookup", "service", service, "profile", profile)
+	}
+
+	// security exits with 44 for missing items, secret-tool with 1 and no
+	// error message.
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if _, bare := err.(*exec.ExitError); (k.isSecurity() && exitErr.ExitCode() == 44) || (!k.isSecurity() && bare) {
+			return "", ErrNotFound
+		}
+	}
+	if err == nil && len(out) == 0 {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// Set stores the API key of profile, replacing any previous one. The key is
+// written to the standard input of the keychain command, never passed as an
+// argument other processes could list.
+func (k *Keychain) Set(profile, apiKey string) error {
+	var err error
+	if k.isSecurity() {
+		err = k.interactive("add-generic-password", "-U", "-s", service, "-a", profile, "-w", apiKey)
+	} else {
+		label := fmt.Sprintf("Cloudcraft API key (%s)", profile)
+		_, err = k.run(strings.NewReader(apiKey), "store", "--label", label, "service", service, "profile", profile)
+	}
+	return err
+}
+
+// Delete removes the API key of profile, if any.
+func (k *Keychain) Delete(profile string) error {
+	var err error
+	if k.isSecurity() {
+		_, err = k.run(nil, "delete-generic-password", "-s", service, "-a", profile)
+
+		// security fails when there is nothing to delete.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+			err = nil
+		}
+	} else {
+		_, err = k.run(nil, "clear", "service", service, "profile", profile)
+	}
+	return err
+}
+
+// interactive runs the security command with args in interactive mode,
+// writing it to the standard input of security -i. Failing commands do not
+// change the exit status of security -i, only their error message tells.
+func (k *Keychain) interactive(args ...string) error {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if strings.ContainsAny(arg, "\r\n") {
+			return fmt.Errorf("credentials: %s: arguments cannot contain line breaks", args[0])
+		}
+		quoted[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+	}
+
+	cmd := exec.Command(k.tool, "-i")
+	cmd.Stdin = strings.NewReader(strings.Join(quoted, " ") + "\n")
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil || stderr.Len() > 0 {
+		if err == nil {
+			err = errors.New("command failed")
+		}
+		return fmt.Errorf("credentials: %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (k *Keychain) isSecurity() bool {
+	return strings.HasSuffix(k.tool, "security")
+}
+
+// run runs the keychain command with args, returning its output.
+func (k *Keychain) run(stdin *strings.Reader, args ...string) ([]byte, error) {
+	cmd := exec.Command(k.tool, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil && stderr.Len() > 0 {
+		return out, fmt.Errorf("credentials: %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, err
+}
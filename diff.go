@@ -0,0 +1,169 @@
+package cloudcraft
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ChangeKind describes how an element differs between two blueprints.
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+)
+
+// BlueprintChange is a single difference between two BlueprintData.
+//
+// Collection is the collection holding the element, e.g. "nodes", and is
+// empty for top-level fields such as the name. Elements are matched by id.
+// For added and removed elements Old or New holds the whole element, for
+// modified elements Field names the property that changed.
+type BlueprintChange struct {
+	Kind       ChangeKind  `json:"kind"`
+	Collection string      `json:"collection,omitempty"`
+	Id         string      `json:"id,omitempty"`
+	Field      string      `json:"field,omitempty"`
+	Old        interface{} `json:"old,omitempty"`
+	New        interface{} `json:"new,omitempty"`
+}
+
+func (d BlueprintChange) String() string {
+	return Stringify(d)
+}
+
+// BlueprintDiff is the list of changes turning one BlueprintData into
+// another, in a deterministic order.
+type BlueprintDiff struct {
+	Changes []BlueprintChange `json:"changes"`
+}
+
+// Empty reports whether the diff holds no changes.
+func (d *BlueprintDiff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// Diff compares the BlueprintData a and b and returns the changes turning a
+// into b. A nil BlueprintData is treated as empty.
+func Diff(a, b *BlueprintData) *BlueprintDiff {
+	if a == nil {
+		a = &BlueprintData{}
+	}
+	if b == nil {
+		b = &BlueprintData{}
+	}
+
+	diff := &BlueprintDiff{Changes: []BlueprintChange{}}
+
+	diffField(diff, "name", a.Name, b.Name)
+	diffField(diff, "grid", a.Grid, b.Grid)
+	diffField(diff, "linkKey", a.LinkKey, b.LinkKey)
+
+	for _, c := range blueprintCollections(a) {
+		diffCollection(diff, c.name, c.elements, collectionOf(b, c.name))
+	}
+
+	return diff
+}
+
+type blueprintCollection struct {
+	name     string
+	elements []map[string]interface{}
+}
+
+// blueprintCollections returns the element collections of d, in the order
+// they are diffed.
+func blueprintCollections(d *BlueprintData) []blueprintCollection {
+	return []blueprintCollection{
+		{"nodes", d.Nodes},
+		{"edges", d.Edges},
+		{"groups", d.Groups},
+		{"text", d.Text},
+		{"icons", d.Icons},
+		{"images", d.Images},
+		{"surfaces", d.Surfaces},
+		{"connectors", d.Connectors},
+		{"disabledLayers", d.DisabledLayers},
+	}
+}
+
+func collectionOf(d *BlueprintData, name string) []map[string]interface{} {
+	for _, c := range blueprintCollections(d) {
+		if c.name == name {
+			return c.elements
+		}
+	}
+	return nil
+}
+
+func diffField(diff *BlueprintDiff, field string, old, new string) {
+	if old != new {
+		diff.Changes = append(diff.Changes, BlueprintChange{Kind: ChangeModified, Field: field, Old: old, New: new})
+	}
+}
+
+// elementKey returns the id used to match an element, falling back to its
+// position for elements without an id.
+func elementKey(element map[string]interface{}, index int) string {
+	if id, ok := element["id"].(string); ok && id != "" {
+		return id
+	}
+	return fmt.Sprintf("#%d", index)
+}
+
+func indexElements(elements []map[string]interface{}) (map[string]map[string]interface{}, []string) {
+	byKey := make(map[string]map[string]interface{}, len(elements))
+	keys := make([]string, 0, len(elements))
+	for i, element := range elements {
+		key := elementKey(element, i)
+		if _, ok := byKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		byKey[key] = element
+	}
+	sort.Strings(keys)
+	return byKey, keys
+}
+
+func diffCollection(diff *BlueprintDiff, collection string, a, b []map[string]interface{}) {
+	oldElements, oldKeys := indexElements(a)
+	newElements, newKeys := indexElements(b)
+
+	for _, key := range oldKeys {
+		oldElement := oldElements[key]
+		newElement, ok := newElements[key]
+		if !ok {
+			diff.Changes = append(diff.Changes, BlueprintChange{Kind: ChangeRemoved, Collection: collection, Id: key, Old: oldElement})
+			continue
+		}
+
+		for _, field := range unionKeys(oldElement, newElement) {
+			oldValue, newValue := oldElement[field], newElement[field]
+			if !reflect.DeepEqual(oldValue, newValue) {
+				diff.Changes = append(diff.Changes, BlueprintChange{Kind: ChangeModified, Collection: collection, Id: key, Field: field, Old: oldValue, New: newValue})
+			}
+		}
+	}
+
+	for _, key := range newKeys {
+		if _, ok := oldElements[key]; !ok {
+			diff.Changes = append(diff.Changes, BlueprintChange{Kind: ChangeAdded, Collection: collection, Id: key, New: newElements[key]})
+		}
+	}
+}
+
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
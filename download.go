@@ -0,0 +1,253 @@
+package cloudcraft
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultChunkSize    = 8 << 20
+	defaultChunkRetries = 3
+)
+
+// DownloadOptions specifies the optional parameters of chunked downloads.
+type DownloadOptions struct {
+	// Size of the ranges requested, 8 MiB if zero.
+	ChunkSize int64
+
+	// Number of times a failing chunk is requested again, 3 if zero: chunks
+	// failing with a network error or a 429 or 5xx status, and chunks
+	// interrupted mid-transfer, which are resumed. The retries wait for the
+	// backoff set with SetRetryWait, or as long as a Retry-After asks.
+	MaxChunkRetries int
+
+	// Optional function reporting the progress of the download.
+	Progress ProgressFunc
+}
+
+// Download sends the GET request req and writes its content to w in chunks
+// requested with Range headers. A failing chunk is requested again, and a
+// chunk interrupted by a network error is resumed from the last byte
+// received. The ETag of the content is sent with
+// If-Range, so the download restarts from the beginning if the content
+// changes meanwhile. Servers ignoring Range are supported, the content is
+// then downloaded again in full on failure.
+//
+// It returns the size of the content.
+func (c *Client) Download(ctx context.Context, req *http.Request, w io.WriterAt, opt *DownloadOptions) (int64, *Response, error) {
+	if req.Method != http.MethodGet {
+		return 0, nil, NewArgError("req.Method", "must be GET")
+	}
+	if w == nil {
+		return 0, nil, NewArgError("w", "cannot be nil")
+	}
+	if opt == nil {
+		opt = &DownloadOptions{}
+	}
+
+	chunkSize := opt.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+	retries := opt.MaxChunkRetries
+	if retries <= 0 {
+		retries = defaultChunkRetries
+	}
+
+	var (
+		offset   int64
+		etag     string
+		failures int
+	)
+	for {
+		r := req.Clone(ctx)
+		r.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+chunkSize-1))
+		if etag != "" {
+			r.Header.Set("If-Range", etag)
+		}
+
+		chunk := &chunkWriter{w: w, offset: offset, progress: opt.Progress}
+		resp, err := c.Do(ctx, r, chunk)
+		if chunk.status == 0 {
+			if offset == 0 && resp != nil && resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+				// Only an empty content has no satisfiable range.
+				return 0, resp, nil
+			}
+			if !isChunkFailure(err) || ctx.Err() != nil || failures >= retries {
+				return offset, resp, err
+			}
+
+			// The chunk is requested again from the same offset.
+			if err := c.waitChunkRetry(ctx, failures, resp); err != nil {
+				return offset, resp, err
+			}
+			failures++
+			continue
+		}
+
+		partial := chunk.status == http.StatusPartialContent
+		if err != nil {
+			var decErr *DecodeError
+			if chunk.writeErr != nil || !errors.As(err, &decErr) || ctx.Err() != nil || failures >= retries {
+				return chunk.offset, resp, err
+			}
+
+			if err := c.waitChunkRetry(ctx, failures, resp); err != nil {
+				return chunk.offset, resp, err
+			}
+			failures++
+			if partial {
+				offset, etag = chunk.offset, chunk.etag
+			} else {
+				offset, etag = 0, ""
+			}
+			continue
+		}
+
+		failures = 0
+		offset, etag = chunk.offset, chunk.etag
+		if !partial {
+			return offset, resp, nil
+		}
+		if chunk.total >= 0 && offset >= chunk.total {
+			return offset, resp, nil
+		}
+		if chunk.total < 0 && offset-chunk.start < chunkSize {
+			return offset, resp, nil
+		}
+	}
+}
+
+// isChunkFailure reports whether err, the error of a chunk request answered
+// without content, is worth requesting the chunk again.
+func isChunkFailure(err error) bool {
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return true
+	}
+
+	var errResp *ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil {
+		code := errResp.Response.StatusCode
+		return code == http.StatusTooManyRequests || code >= 500
+	}
+	return false
+}
+
+// waitChunkRetry waits before the retry following the failed attempt of a
+// chunk, with the backoff of the client.
+func (c *Client) waitChunkRetry(ctx context.Context, attempt int, resp *Response) error {
+	var r *http.Response
+	if resp != nil {
+		r = resp.Response
+	}
+	return sleep(ctx, c.backoffPolicy().wait(attempt, r))
+}
+
+// ExportTo exports a Blueprint to w with Client.Download.
+//
+// The PDFMetadata of exportRequest is not supported, as the export is not
+// held in memory.
+func (s *BlueprintsServiceOp) ExportTo(ctx context.Context, blueprintId string, exportRequest *BlueprintExportRequest, w io.WriterAt, opt *DownloadOptions, opts ...RequestOpt) (int64, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if exportRequest != nil && exportRequest.PDFMetadata != nil {
+		return 0, nil, NewArgError("exportRequest.PDFMetadata", "is not supported by ExportTo")
+	}
+
+	req, err := s.newExportRequest(ctx, blueprintId, exportRequest)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return s.client.Download(ctx, req, w, opt)
+}
+
+// SnapshotTo snapshots an AwsAccount to w with Client.Download.
+func (s *AwsAccountsServiceOp) SnapshotTo(ctx context.Context, awsAccountID string, snapshotRequest *AwsAccountSnapshotRequest, w io.WriterAt, opt *DownloadOptions, opts ...RequestOpt) (int64, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	req, err := s.newSnapshotRequest(ctx, awsAccountID, snapshotRequest)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return s.client.Download(ctx, req, w, opt)
+}
+
+// chunkWriter writes a chunk of a download to w at the offset announced by
+// the Content-Range of the response.
+type chunkWriter struct {
+	w        io.WriterAt
+	progress ProgressFunc
+
+	status   int
+	start    int64
+	offset   int64
+	total    int64
+	etag     string
+	writeErr error
+}
+
+func (cw *chunkWriter) begin(resp *http.Response) {
+	cw.status = resp.StatusCode
+	cw.etag = resp.Header.Get("ETag")
+
+	if resp.StatusCode == http.StatusPartialContent {
+		cw.start, cw.total, cw.writeErr = parseContentRange(resp.Header.Get("Content-Range"))
+	} else {
+		cw.start, cw.total = 0, resp.ContentLength
+	}
+	cw.offset = cw.start
+}
+
+func (cw *chunkWriter) Write(p []byte) (int, error) {
+	if cw.writeErr != nil {
+		return 0, cw.writeErr
+	}
+
+	n, err := cw.w.WriteAt(p, cw.offset)
+	cw.offset += int64(n)
+	if err != nil {
+		cw.writeErr = err
+	}
+	if cw.progress != nil {
+		cw.progress(cw.offset, cw.total)
+	}
+	return n, err
+}
+
+// parseContentRange returns the first byte and the complete length, -1 if
+// unknown, of a Content-Range header such as "bytes 0-499/1234".
+func parseContentRange(s string) (start, total int64, err error) {
+	invalid := fmt.Errorf("cloudcraft: invalid Content-Range %q", s)
+
+	s = strings.TrimPrefix(s, "bytes ")
+	i := strings.IndexByte(s, '-')
+	j := strings.IndexByte(s, '/')
+	if i < 0 || j < i {
+		return 0, 0, invalid
+	}
+
+	start, err = strconv.ParseInt(s[:i], 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, invalid
+	}
+
+	if s[j+1:] == "*" {
+		return start, -1, nil
+	}
+	total, err = strconv.ParseInt(s[j+1:], 10, 64)
+	if err != nil || total < 0 {
+		return 0, 0, invalid
+	}
+
+	return start, total, nil
+}
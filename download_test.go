@@ -0,0 +1,110 @@
+package cloudcraft
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// bufferAt is an io.WriterAt writing to memory.
+type bufferAt struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (b *bufferAt) WriteAt(p []byte, off int64) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if end := int(off) + len(p); end > len(b.buf) {
+		b.buf = append(b.buf, make([]byte, end-len(b.buf))...)
+	}
+	return copy(b.buf[off:], p), nil
+}
+
+func TestDownloadRetriesFailedChunks(t *testing.T) {
+	content := strings.Repeat("0123456789", 3)
+
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rng := r.Header.Get("Range")
+		mu.Lock()
+		attempts[rng]++
+		attempt := attempts[rng]
+		mu.Unlock()
+
+		var start, end int
+		fmt.Sscanf(rng, "bytes=%d-%d", &start, &end)
+		if start == 10 {
+			switch attempt {
+			case 1:
+				// The connection is dropped before any header is sent.
+				conn, _, err := w.(http.Hijacker).Hijack()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				conn.Close()
+				return
+			case 2:
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		}
+
+		if end >= len(content) {
+			end = len(content) - 1
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(content)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte(content[start : end+1]))
+	}))
+	defer server.Close()
+
+	client, err := New(nil, SetBaseURL(server.URL+"/"), SetRetryWait(time.Millisecond, 2*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := client.NewRequest(context.Background(), http.MethodGet, "export", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := new(bufferAt)
+	n, _, err := client.Download(context.Background(), req, w, &DownloadOptions{ChunkSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(content)) || string(w.buf) != content {
+		t.Errorf("Download() = %d, %q, want %d, %q", n, w.buf, len(content), content)
+	}
+	if got := attempts["bytes=10-19"]; got != 3 {
+		t.Errorf("%d attempts of the failing chunk, want 3", got)
+	}
+}
+
+func TestDownloadGivesUpAfterRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client, err := New(nil, SetBaseURL(server.URL+"/"), SetRetryWait(time.Millisecond, 2*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := client.NewRequest(context.Background(), http.MethodGet, "export", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := client.Download(context.Background(), req, new(bufferAt), &DownloadOptions{MaxChunkRetries: 2}); err == nil {
+		t.Error("Download() from a failing server returned no error")
+	}
+}
@@ -0,0 +1,145 @@
+package cloudcraft
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// defaultViewerURL is the URL of the Cloudcraft viewer of shared blueprints.
+const defaultViewerURL = "https://app.cloudcraft.co/view/"
+
+// EmbedViewMode is how the viewer presents an embedded blueprint.
+type EmbedViewMode string
+
+const (
+	// EmbedViewInteractive lets viewers pan, zoom and select components.
+	EmbedViewInteractive EmbedViewMode = "interactive"
+
+	// EmbedViewStatic shows the diagram without controls.
+	EmbedViewStatic EmbedViewMode = "static"
+
+	// EmbedViewPresentation fits the diagram to the frame, for displays.
+	EmbedViewPresentation EmbedViewMode = "presentation"
+)
+
+// EmbedViewModeValues returns the known EmbedViewMode values.
+func EmbedViewModeValues() []string {
+	return []string{string(EmbedViewInteractive), string(EmbedViewStatic), string(EmbedViewPresentation)}
+}
+
+// Valid reports whether m is a known EmbedViewMode.
+func (m EmbedViewMode) Valid() bool {
+	switch m {
+	case EmbedViewInteractive, EmbedViewStatic, EmbedViewPresentation:
+		return true
+	}
+	return false
+}
+
+// EmbedOptions are the rendering parameters of an embedded blueprint, the
+// defaults of the viewer when empty.
+type EmbedOptions struct {
+	// ViewerURL is the URL of the viewer, https://app.cloudcraft.co/view/
+	// by default.
+	ViewerURL string
+
+	Mode       EmbedViewMode
+	Projection Projection
+	Theme      ExportTheme
+
+	// AutoRotate rotates isometric diagrams continuously, at RotationSpeed
+	// degrees per second if not zero.
+	AutoRotate    bool
+	RotationSpeed float64
+
+	Grid bool
+}
+
+// Encode returns the query parameters of o.
+func (o *EmbedOptions) Encode() url.Values {
+	if o == nil {
+		return nil
+	}
+
+	v := url.Values{}
+	w := queryWriter(v)
+	w.bool("embed", true, false)
+	w.string("mode", string(o.Mode), true)
+	w.string("projection", string(o.Projection), true)
+	w.string("theme", string(o.Theme), true)
+	w.bool("autorotate", o.AutoRotate, true)
+	w.float32("rotationSpeed", float32(o.RotationSpeed), true)
+	w.bool("grid", o.Grid, true)
+	return v
+}
+
+func (o *EmbedOptions) validate() error {
+	if o.Mode != "" && !o.Mode.Valid() {
+		return NewArgError("opt.Mode", oneOf(EmbedViewModeValues()))
+	}
+	if o.Projection != "" && !o.Projection.Valid() {
+		return NewArgError("opt.Projection", oneOf(ProjectionValues()))
+	}
+	if o.Theme != "" && !o.Theme.Valid() {
+		return NewArgError("opt.Theme", oneOf(ExportThemeValues()))
+	}
+	if o.RotationSpeed < 0 {
+		return NewArgError("opt.RotationSpeed", "cannot be negative")
+	}
+	if o.RotationSpeed != 0 && !o.AutoRotate {
+		return NewArgError("opt.RotationSpeed", "requires AutoRotate")
+	}
+	if o.RotationSpeed != 0 && o.Projection == Projection2D {
+		return NewArgError("opt.RotationSpeed", "requires an isometric projection")
+	}
+	return nil
+}
+
+// EmbedURL returns the URL embedding the blueprint shared with linkKey,
+// the key of its share link, in a page, e.g. as the src of an iframe.
+func EmbedURL(blueprintId, linkKey string, opt *EmbedOptions) (string, error) {
+	if blueprintId == "" {
+		return "", NewArgError("blueprintId", "cannot be empty")
+	}
+	if linkKey == "" {
+		return "", NewArgError("linkKey", "cannot be empty")
+	}
+	if opt == nil {
+		opt = &EmbedOptions{}
+	}
+	if err := opt.validate(); err != nil {
+		return "", err
+	}
+
+	viewerURL := opt.ViewerURL
+	if viewerURL == "" {
+		viewerURL = defaultViewerURL
+	}
+	if !strings.HasSuffix(viewerURL, "/") {
+		viewerURL += "/"
+	}
+
+	base, err := url.Parse(viewerURL)
+	if err != nil {
+		return "", NewArgError("opt.ViewerURL", err.Error())
+	}
+	u, err := base.Parse(url.PathEscape(blueprintId))
+	if err != nil {
+		return "", fmt.Errorf("cloudcraft: building embed URL: %w", err)
+	}
+
+	query := opt.Encode()
+	query.Set("key", linkKey)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// EmbedURL returns the URL embedding the Blueprint in a page, see EmbedURL.
+// The Blueprint must have been shared, so that its data holds a link key.
+func (d *Blueprint) EmbedURL(opt *EmbedOptions) (string, error) {
+	if d.Data == nil || d.Data.LinkKey == "" {
+		return "", NewArgError("blueprint.Data.LinkKey", "is empty, the blueprint is not shared")
+	}
+	return EmbedURL(d.Id, d.Data.LinkKey, opt)
+}
@@ -1,6 +1,10 @@
 package cloudcraft
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
 
 // ArgError is an error that represents an error with an input to cloudcraft-go. It
 // identifies the argument and the cause (if possible).
@@ -22,3 +26,104 @@ func NewArgError(arg, reason string) *ArgError {
 func (e *ArgError) Error() string {
 	return fmt.Sprintf("%s is invalid because %s", e.arg, e.reason)
 }
+
+// ErrResponseTooLarge is matched by the errors returned when a response body
+// exceeds the size set with SetMaxResponseSize.
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// ResponseTooLargeError is returned when a response body exceeds the size
+// set with SetMaxResponseSize.
+type ResponseTooLargeError struct {
+	Limit int64
+}
+
+var _ error = &ResponseTooLargeError{}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response body exceeds the limit of %d bytes", e.Limit)
+}
+
+// Is reports whether target is ErrResponseTooLarge.
+func (e *ResponseTooLargeError) Is(target error) bool {
+	return target == ErrResponseTooLarge
+}
+
+// IsNotFound reports whether err is an ErrorResponse for a resource that does
+// not exist, e.g. a Blueprint deleted outside of the caller's control.
+func IsNotFound(err error) bool {
+	var errorResponse *ErrorResponse
+	if errors.As(err, &errorResponse) && errorResponse.Response != nil {
+		return errorResponse.Response.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// IsConflict reports whether err is an ErrorResponse for a request rejected
+// because of a concurrent change, e.g. the update of a Blueprint modified
+// since it was read by Blueprints.Patch.
+func IsConflict(err error) bool {
+	var errorResponse *ErrorResponse
+	if errors.As(err, &errorResponse) && errorResponse.Response != nil {
+		code := errorResponse.Response.StatusCode
+		return code == http.StatusConflict || code == http.StatusPreconditionFailed
+	}
+	return false
+}
+
+// EncodeError is returned when the body of a request cannot be encoded.
+type EncodeError struct {
+	Op  string
+	Err error
+}
+
+var _ error = &EncodeError{}
+
+func (e *EncodeError) Error() string {
+	return fmt.Sprintf("cloudcraft: %s: encoding request body: %v", e.Op, e.Err)
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError is returned when the body of a response cannot be read or
+// decoded.
+type DecodeError struct {
+	Op  string
+	Err error
+}
+
+var _ error = &DecodeError{}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("cloudcraft: %s: decoding response body: %v", e.Op, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// TransportError is returned when a request cannot be sent or its response
+// cannot be received, including when its context is done.
+type TransportError struct {
+	Op  string
+	Err error
+}
+
+var _ error = &TransportError{}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("cloudcraft: %s: %v", e.Op, e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// requestOp describes a request for the Op of errors.
+func requestOp(req *http.Request) string {
+	if id := req.Header.Get(headerRequestID); id != "" {
+		return req.Method + " " + req.URL.String() + " (request id " + id + ")"
+	}
+	return req.Method + " " + req.URL.String()
+}
@@ -0,0 +1,222 @@
+package cloudcraft
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CachedExport is an export stored by an ExportCache, along with the
+// updatedAt of the Blueprint it was rendered from.
+type CachedExport struct {
+	UpdatedAt   Timestamp `json:"updatedAt"`
+	ContentType string    `json:"contentType,omitempty"`
+	Content     []byte    `json:"content"`
+}
+
+// ExportCache stores the exports of Blueprints.Export, keyed by Blueprint,
+// format and parameters. An export is served from the cache, without any
+// rendering, as long as the updatedAt of its Blueprint is unchanged, which
+// costs a Blueprints.Get per export. Exports served from the cache come with
+// an empty 200 OK Response.
+//
+// Get returns a nil export for keys not cached. Errors of the cache never
+// fail exports, they are treated as cache misses.
+type ExportCache interface {
+	Get(key string) (*CachedExport, error)
+	Put(key string, export *CachedExport) error
+}
+
+// SetExportCache is a client option caching the exports of
+// Blueprints.Export in cache.
+func SetExportCache(cache ExportCache) ClientOpt {
+	return func(c *Client) error {
+		if cache == nil {
+			return NewArgError("cache", "cannot be nil")
+		}
+
+		c.exportCache = cache
+		return nil
+	}
+}
+
+// exportKey returns the key of an export in the export cache.
+func exportKey(blueprintId string, exportRequest *BlueprintExportRequest) string {
+	key := blueprintId + "/" + exportRequest.Format
+	if q := exportRequest.ExportParameters.Encode(); len(q) > 0 {
+		key += "?" + q.Encode()
+	}
+	if m := exportRequest.PDFMetadata; m != nil {
+		key += fmt.Sprintf("#%q,%q,%q", m.Title, m.Author, m.Subject)
+	}
+	return key
+}
+
+// exportCached exports a Blueprint through the export cache of the client.
+func (s *BlueprintsServiceOp) exportCached(ctx context.Context, blueprintId string, exportRequest *BlueprintExportRequest) (*BlueprintImage, *Response, error) {
+	if blueprintId == "" {
+		return nil, nil, NewArgError("blueprintId", "cannot be empty")
+	}
+
+	if exportRequest == nil {
+		return nil, nil, NewArgError("exportRequest", "cannot be nil")
+	}
+
+	if err := exportRequest.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	blueprint, resp, err := s.Get(ctx, blueprintId)
+	if err != nil {
+		return nil, resp, err
+	}
+	if blueprint.UpdatedAt.IsZero() {
+		return s.export(ctx, blueprintId, exportRequest)
+	}
+
+	cache := s.client.exportCache
+	key := exportKey(blueprintId, exportRequest)
+	if cached, err := cache.Get(key); err == nil && cached != nil && cached.UpdatedAt.Equal(blueprint.UpdatedAt) {
+		req, err := s.newExportRequest(ctx, blueprintId, exportRequest)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		image := &BlueprintImage{
+			ContentType:      cached.ContentType,
+			Content:          bytes.NewBuffer(cached.Content),
+			ExportParameters: exportRequest.ExportParameters,
+		}
+		return image, staleResponse(req, http.StatusOK, cached.ContentType, len(cached.Content), nil), nil
+	}
+
+	image, resp, err := s.export(ctx, blueprintId, exportRequest)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	cached := &CachedExport{UpdatedAt: blueprint.UpdatedAt, ContentType: image.ContentType}
+	if image.Content != nil {
+		cached.Content = image.Content.Bytes()
+	}
+	_ = cache.Put(key, cached)
+
+	return image, resp, nil
+}
+
+// MemoryExportCache is an ExportCache held in memory. It is safe for
+// concurrent use. The exports are copied in and out, so the cached content is
+// never shared with the callers.
+type MemoryExportCache struct {
+	mu      sync.RWMutex
+	exports map[string]*CachedExport
+}
+
+var _ ExportCache = &MemoryExportCache{}
+
+// NewMemoryExportCache returns an empty MemoryExportCache.
+func NewMemoryExportCache() *MemoryExportCache {
+	return &MemoryExportCache{exports: make(map[string]*CachedExport)}
+}
+
+// Get implements ExportCache.
+func (m *MemoryExportCache) Get(key string) (*CachedExport, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return copyExport(m.exports[key]), nil
+}
+
+// Put implements ExportCache.
+func (m *MemoryExportCache) Put(key string, export *CachedExport) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.exports[key] = copyExport(export)
+	return nil
+}
+
+func copyExport(export *CachedExport) *CachedExport {
+	if export == nil {
+		return nil
+	}
+
+	c := *export
+	c.Content = append([]byte(nil), export.Content...)
+	return &c
+}
+
+// DirExportCache is an ExportCache storing one JSON file per export in a
+// directory, so nightly jobs reuse the exports of the previous runs.
+type DirExportCache struct {
+	dir string
+}
+
+var _ ExportCache = &DirExportCache{}
+
+// NewDirExportCache returns a DirExportCache storing its files in dir, which
+// is created if needed.
+func NewDirExportCache(dir string) (*DirExportCache, error) {
+	if dir == "" {
+		return nil, NewArgError("dir", "cannot be empty")
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("cloudcraft: creating cache directory: %w", err)
+	}
+	return &DirExportCache{dir: dir}, nil
+}
+
+func (d *DirExportCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get implements ExportCache.
+func (d *DirExportCache) Get(key string) (*CachedExport, error) {
+	content, err := ioutil.ReadFile(d.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	export := new(CachedExport)
+	if err := json.Unmarshal(content, export); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// Put implements ExportCache. The file is replaced atomically.
+func (d *DirExportCache) Put(key string, export *CachedExport) error {
+	content, err := json.Marshal(export)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(d.dir, ".export-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), d.path(key))
+}
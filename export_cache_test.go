@@ -0,0 +1,66 @@
+package cloudcraft
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMemoryExportCacheCopies(t *testing.T) {
+	cache := NewMemoryExportCache()
+
+	content := []byte("png")
+	if err := cache.Put("k", &CachedExport{Content: content}); err != nil {
+		t.Fatal(err)
+	}
+	content[0] = 'x'
+
+	got, _ := cache.Get("k")
+	got.Content[1] = 'x'
+
+	again, _ := cache.Get("k")
+	if string(again.Content) != "png" {
+		t.Errorf("cached content = %q, want png", again.Content)
+	}
+}
+
+func TestExportCacheHit(t *testing.T) {
+	var exports int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/blueprint/b" {
+			w.Header().Set("Content-Type", mediaType)
+			w.Write([]byte(`{"id": "b", "updatedAt": "2021-03-04T05:06:07Z"}`))
+			return
+		}
+		exports++
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png"))
+	}))
+	defer server.Close()
+
+	client, err := New(nil, SetBaseURL(server.URL+"/"), SetExportCache(NewMemoryExportCache()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		image, resp, err := client.Blueprints.Export(context.Background(), "b", &BlueprintExportRequest{Format: "png"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp == nil || resp.StatusCode != http.StatusOK {
+			t.Errorf("Export() response = %v, want 200 OK", resp)
+		}
+		if !bytes.Equal(image.Content.Bytes(), []byte("png")) {
+			t.Errorf("Export() content = %q, want png", image.Content.Bytes())
+		}
+
+		// Writing to the exported content leaves the cache intact.
+		image.Content.WriteString("garbage")
+	}
+	if exports != 1 {
+		t.Errorf("%d exports, want 1", exports)
+	}
+}
@@ -0,0 +1,3 @@
+package cloudcraft
+
+//go:generate go run ./internal/codegen -spec api/openapi.json -out openapi_gen.go
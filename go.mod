@@ -1,5 +1,3 @@
 module github.com/updater/cloudcraft-go
 
 go 1.16
-
-require github.com/google/go-querystring v1.1.0 // indirect
@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"github.com/updater/cloudcraft-go"
+)
+
+// CytoscapeElement is a node or an edge of a Cytoscape.js graph.
+type CytoscapeElement struct {
+	Group    string        `json:"group"`
+	Data     CytoscapeData `json:"data"`
+	Position *Position     `json:"position,omitempty"`
+	Classes  []string      `json:"classes,omitempty"`
+}
+
+// CytoscapeData is the data of a CytoscapeElement. Parent is the compound
+// node of the innermost group of a node, Source and Target the ends of an
+// edge.
+type CytoscapeData struct {
+	Id     string `json:"id,omitempty"`
+	Label  string `json:"label,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Parent string `json:"parent,omitempty"`
+	Source string `json:"source,omitempty"`
+	Target string `json:"target,omitempty"`
+}
+
+// CytoscapeDocument is a Cytoscape.js graph, given as is to the elements
+// option of cytoscape() once encoded as JSON.
+type CytoscapeDocument struct {
+	Elements []CytoscapeElement `json:"elements"`
+}
+
+// Cytoscape converts data to a Cytoscape.js graph. The elements are classed
+// "group" for groups and "node" for nodes, edges to missing components
+// being dropped.
+func Cytoscape(data *cloudcraft.BlueprintData) *CytoscapeDocument {
+	b := newBlueprint(data)
+
+	doc := &CytoscapeDocument{Elements: make([]CytoscapeElement, 0, len(b.nodes)+len(b.edges))}
+	for _, n := range b.nodes {
+		class := "node"
+		if n.group {
+			class = "group"
+		}
+
+		doc.Elements = append(doc.Elements, CytoscapeElement{
+			Group: "nodes",
+			Data: CytoscapeData{
+				Id:     n.id,
+				Label:  n.label,
+				Type:   n.kind,
+				Parent: b.parents[n.id],
+			},
+			Position: n.position,
+			Classes:  []string{class},
+		})
+	}
+
+	for _, e := range b.edges {
+		doc.Elements = append(doc.Elements, CytoscapeElement{
+			Group: "edges",
+			Data: CytoscapeData{
+				Id:     e.id,
+				Type:   e.kind,
+				Source: e.source,
+				Target: e.target,
+			},
+		})
+	}
+
+	return doc
+}
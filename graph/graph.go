@@ -0,0 +1,148 @@
+// Package graph converts the data of Cloudcraft blueprints to graph
+// documents, Cytoscape.js elements and the JSON Graph Format, so diagrams
+// can be loaded into interactive graph viewers and analysis pipelines.
+//
+// The nodes of a blueprint become graph nodes and its edges graph edges.
+// Groups, such as VPCs and subnets, become compound nodes in Cytoscape.js
+// documents and are listed in the metadata of their members in JSON Graph
+// Format documents.
+package graph
+
+import (
+	"github.com/updater/cloudcraft-go"
+)
+
+// node is a blueprint node or group, indexed for the converters.
+type node struct {
+	id       string
+	kind     string
+	label    string
+	position *Position
+	groups   []string
+	group    bool
+}
+
+// Position is the position of a node on the grid of the blueprint.
+type Position struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// edge is a blueprint edge between two known nodes.
+type edge struct {
+	id, source, target string
+	kind               string
+}
+
+// blueprint indexes the nodes and edges of a blueprint, in the order of
+// the blueprint.
+type blueprint struct {
+	name  string
+	nodes []*node
+	edges []edge
+
+	// parents maps the id of a node to the smallest group holding it.
+	parents map[string]string
+}
+
+func newBlueprint(data *cloudcraft.BlueprintData) *blueprint {
+	if data == nil {
+		data = &cloudcraft.BlueprintData{}
+	}
+
+	b := &blueprint{name: data.Name, parents: make(map[string]string)}
+	byId := make(map[string]*node)
+
+	for _, element := range data.Nodes {
+		n := &node{
+			id:       stringField(element, "id"),
+			kind:     stringField(element, "type"),
+			label:    label(element),
+			position: position(element),
+		}
+		if n.id == "" {
+			continue
+		}
+		b.nodes = append(b.nodes, n)
+		byId[n.id] = n
+	}
+
+	groupSizes := make(map[string]int)
+	for _, element := range data.Groups {
+		g := &node{
+			id:    stringField(element, "id"),
+			kind:  stringField(element, "type"),
+			label: label(element),
+			group: true,
+		}
+		if g.id == "" {
+			continue
+		}
+
+		members, _ := element["nodes"].([]interface{})
+		groupSizes[g.id] = len(members)
+		b.nodes = append(b.nodes, g)
+		byId[g.id] = g
+	}
+
+	// Groups are nested by holding the same nodes, the smallest group of
+	// a node being the innermost one.
+	for _, element := range data.Groups {
+		id := stringField(element, "id")
+		members, _ := element["nodes"].([]interface{})
+		for _, member := range members {
+			memberId, _ := member.(string)
+			n, ok := byId[memberId]
+			if !ok || id == "" {
+				continue
+			}
+			n.groups = append(n.groups, id)
+
+			if parent, ok := b.parents[memberId]; !ok || groupSizes[id] < groupSizes[parent] {
+				b.parents[memberId] = id
+			}
+		}
+	}
+
+	for _, element := range data.Edges {
+		e := edge{
+			id:     stringField(element, "id"),
+			source: stringField(element, "from"),
+			target: stringField(element, "to"),
+			kind:   stringField(element, "type"),
+		}
+		if byId[e.source] == nil || byId[e.target] == nil {
+			continue
+		}
+		b.edges = append(b.edges, e)
+	}
+
+	return b
+}
+
+func stringField(element map[string]interface{}, key string) string {
+	s, _ := element[key].(string)
+	return s
+}
+
+// label returns the name of a blueprint element, if any.
+func label(element map[string]interface{}) string {
+	if s := stringField(element, "name"); s != "" {
+		return s
+	}
+	return stringField(element, "label")
+}
+
+// position returns the position of a node given as a [x, y] mapPos.
+func position(element map[string]interface{}) *Position {
+	mapPos, _ := element["mapPos"].([]interface{})
+	if len(mapPos) != 2 {
+		return nil
+	}
+	x, okX := mapPos[0].(float64)
+	y, okY := mapPos[1].(float64)
+	if !okX || !okY {
+		return nil
+	}
+	return &Position{X: x, Y: y}
+}
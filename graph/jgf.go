@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"github.com/updater/cloudcraft-go"
+)
+
+// JGFNode is a node of a JSON Graph Format graph.
+type JGFNode struct {
+	Label    string      `json:"label,omitempty"`
+	Metadata JGFMetadata `json:"metadata"`
+}
+
+// JGFEdge is an edge of a JSON Graph Format graph.
+type JGFEdge struct {
+	Id       string      `json:"id,omitempty"`
+	Source   string      `json:"source"`
+	Target   string      `json:"target"`
+	Metadata JGFMetadata `json:"metadata,omitempty"`
+}
+
+// JGFMetadata is the metadata of the nodes and edges, the Cloudcraft type
+// of the element, whether a node is a group and the groups holding it.
+type JGFMetadata struct {
+	Type     string    `json:"type,omitempty"`
+	Group    bool      `json:"group,omitempty"`
+	Groups   []string  `json:"groups,omitempty"`
+	Position *Position `json:"position,omitempty"`
+}
+
+// JGFGraph is a JSON Graph Format graph, nodes being keyed by id as of
+// version 2 of the format.
+type JGFGraph struct {
+	Label    string             `json:"label,omitempty"`
+	Directed bool               `json:"directed"`
+	Nodes    map[string]JGFNode `json:"nodes"`
+	Edges    []JGFEdge          `json:"edges"`
+}
+
+// JGFDocument is a JSON Graph Format document holding a single graph.
+type JGFDocument struct {
+	Graph JGFGraph `json:"graph"`
+}
+
+// JGF converts data to a directed JSON Graph Format document, edges going
+// from their "from" to their "to" component. Groups are nodes of the graph
+// as well, the ids of the groups holding a node being listed in its
+// metadata, and edges to missing components are dropped.
+func JGF(data *cloudcraft.BlueprintData) *JGFDocument {
+	b := newBlueprint(data)
+
+	g := JGFGraph{
+		Label:    b.name,
+		Directed: true,
+		Nodes:    make(map[string]JGFNode, len(b.nodes)),
+		Edges:    make([]JGFEdge, 0, len(b.edges)),
+	}
+
+	for _, n := range b.nodes {
+		g.Nodes[n.id] = JGFNode{
+			Label: n.label,
+			Metadata: JGFMetadata{
+				Type:     n.kind,
+				Group:    n.group,
+				Groups:   n.groups,
+				Position: n.position,
+			},
+		}
+	}
+
+	for _, e := range b.edges {
+		g.Edges = append(g.Edges, JGFEdge{
+			Id:       e.id,
+			Source:   e.source,
+			Target:   e.target,
+			Metadata: JGFMetadata{Type: e.kind},
+		})
+	}
+
+	return &JGFDocument{Graph: g}
+}
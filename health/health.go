@@ -0,0 +1,262 @@
+// Package health aggregates the health of the AWS accounts registered in
+// Cloudcraft, from the snapshots taken by a client, into data ready to be
+// rendered by an internal status dashboard.
+//
+// The Cloudcraft API does not keep the history of snapshots, so a Tracker
+// observes the snapshot requests of the client:
+//
+//	tracker := health.NewTracker(24 * time.Hour)
+//	client.OnRequestCompleted(tracker.Observe)
+package health
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/updater/cloudcraft-go"
+)
+
+// Status is the overall health of an account.
+type Status string
+
+const (
+	// StatusOK is the status of accounts whose expected regions all were
+	// snapshotted recently, without recent errors.
+	StatusOK Status = "ok"
+
+	// StatusDegraded is the status of accounts missing recent snapshots of
+	// some regions, or with recent errors followed by successes.
+	StatusDegraded Status = "degraded"
+
+	// StatusFailing is the status of accounts whose last snapshot failed.
+	StatusFailing Status = "failing"
+
+	// StatusUnknown is the status of accounts no snapshot was observed for.
+	StatusUnknown Status = "unknown"
+)
+
+// regionStats are the observed snapshots of a region of an account.
+type regionStats struct {
+	lastSuccess time.Time
+	lastError   time.Time
+	lastStatus  int
+	errors      []time.Time
+}
+
+// Tracker records the outcome of the snapshots of the AWS accounts. It is
+// safe for concurrent use.
+type Tracker struct {
+	// window is the period errors are counted over.
+	window time.Duration
+
+	// now returns the current time.
+	now func() time.Time
+
+	mu       sync.Mutex
+	accounts map[string]map[string]*regionStats
+}
+
+// NewTracker returns a Tracker counting the errors of the last window.
+func NewTracker(window time.Duration) *Tracker {
+	return &Tracker{window: window, now: time.Now, accounts: make(map[string]map[string]*regionStats)}
+}
+
+// Observe records the outcome of the snapshot requests among the requests
+// it is given. It has the signature of a cloudcraft.RequestCompletionCallback.
+// Snapshots failing before a response is received are recorded with
+// RecordError instead.
+func (t *Tracker) Observe(req *http.Request, resp *http.Response) {
+	accountId, region, ok := snapshotOf(req)
+	if !ok || resp == nil || resp.StatusCode == http.StatusAccepted {
+		return
+	}
+	t.record(accountId, region, resp.StatusCode)
+}
+
+// RecordError records a failed snapshot of the region of an account, such
+// as a snapshot failing with a network error.
+func (t *Tracker) RecordError(accountId, region string) {
+	t.record(accountId, region, 0)
+}
+
+func (t *Tracker) record(accountId, region string, status int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	regions, ok := t.accounts[accountId]
+	if !ok {
+		regions = make(map[string]*regionStats)
+		t.accounts[accountId] = regions
+	}
+	stats, ok := regions[region]
+	if !ok {
+		stats = &regionStats{}
+		regions[region] = stats
+	}
+
+	now := t.now()
+	stats.lastStatus = status
+	if status >= 200 && status < 300 {
+		stats.lastSuccess = now
+		return
+	}
+
+	stats.lastError = now
+	stats.errors = append(stats.errors, now)
+	t.pruneLocked(stats, now)
+}
+
+// pruneLocked forgets the errors older than the window.
+func (t *Tracker) pruneLocked(stats *regionStats, now time.Time) {
+	i := 0
+	for i < len(stats.errors) && now.Sub(stats.errors[i]) > t.window {
+		i++
+	}
+	stats.errors = stats.errors[i:]
+}
+
+// snapshotOf returns the account and region of a snapshot request, whose
+// path is aws/account/<id>/<region>/<format>.
+func snapshotOf(req *http.Request) (accountId, region string, ok bool) {
+	if req == nil || req.Method != http.MethodGet {
+		return "", "", false
+	}
+
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	n := len(segments)
+	if n < 5 || segments[n-5] != "aws" || segments[n-4] != "account" {
+		return "", "", false
+	}
+	return segments[n-3], segments[n-2], true
+}
+
+// RegionHealth is the health of the snapshots of a region of an account.
+type RegionHealth struct {
+	Region      string    `json:"region"`
+	LastSuccess time.Time `json:"lastSuccess,omitempty"`
+	LastError   time.Time `json:"lastError,omitempty"`
+
+	// LastStatus is the HTTP status of the last snapshot, zero if it failed
+	// without response.
+	LastStatus   int `json:"lastStatus"`
+	RecentErrors int `json:"recentErrors"`
+}
+
+// AccountHealth is the health of an AWS account.
+type AccountHealth struct {
+	Account cloudcraft.AwsAccount `json:"account"`
+	Status  Status                `json:"status"`
+
+	// LastSuccess is the time of the last successful snapshot of any
+	// region of the account.
+	LastSuccess  time.Time `json:"lastSuccess,omitempty"`
+	RecentErrors int       `json:"recentErrors"`
+
+	// Regions are the observed regions, and MissingRegions the expected
+	// regions without recent successful snapshot.
+	Regions        []RegionHealth `json:"regions"`
+	MissingRegions []string       `json:"missingRegions,omitempty"`
+}
+
+// Dashboard is the health of all the registered AWS accounts.
+type Dashboard struct {
+	GeneratedAt time.Time       `json:"generatedAt"`
+	Accounts    []AccountHealth `json:"accounts"`
+	Statuses    map[Status]int  `json:"statuses"`
+}
+
+// Options configures NewDashboard.
+type Options struct {
+	// Regions expected to be snapshotted for every account.
+	Regions []string
+
+	// MaxAge is the age after which a successful snapshot of an
+	// expected region no longer counts, a day if zero.
+	MaxAge time.Duration
+}
+
+// NewDashboard lists the registered AWS accounts and returns their health
+// according to the snapshots observed by t, sorted from the least to the
+// most healthy.
+func NewDashboard(ctx context.Context, client *cloudcraft.Client, t *Tracker, opts *Options) (*Dashboard, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	maxAge := opts.MaxAge
+	if maxAge <= 0 {
+		maxAge = 24 * time.Hour
+	}
+
+	accounts, _, err := client.AwsAccounts.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	d := &Dashboard{GeneratedAt: now.UTC(), Accounts: []AccountHealth{}, Statuses: make(map[Status]int)}
+	for _, account := range accounts {
+		h := t.accountHealthLocked(account, opts.Regions, maxAge, now)
+		d.Accounts = append(d.Accounts, h)
+		d.Statuses[h.Status]++
+	}
+
+	rank := map[Status]int{StatusFailing: 0, StatusDegraded: 1, StatusUnknown: 2, StatusOK: 3}
+	sort.SliceStable(d.Accounts, func(i, j int) bool {
+		return rank[d.Accounts[i].Status] < rank[d.Accounts[j].Status]
+	})
+
+	return d, nil
+}
+
+func (t *Tracker) accountHealthLocked(account cloudcraft.AwsAccount, expected []string, maxAge time.Duration, now time.Time) AccountHealth {
+	h := AccountHealth{Account: account, Regions: []RegionHealth{}}
+
+	regions := t.accounts[account.Id]
+	failing := false
+	for region, stats := range regions {
+		t.pruneLocked(stats, now)
+
+		h.Regions = append(h.Regions, RegionHealth{
+			Region:       region,
+			LastSuccess:  stats.lastSuccess,
+			LastError:    stats.lastError,
+			LastStatus:   stats.lastStatus,
+			RecentErrors: len(stats.errors),
+		})
+		h.RecentErrors += len(stats.errors)
+		if stats.lastSuccess.After(h.LastSuccess) {
+			h.LastSuccess = stats.lastSuccess
+		}
+		if stats.lastError.After(stats.lastSuccess) {
+			failing = true
+		}
+	}
+	sort.Slice(h.Regions, func(i, j int) bool { return h.Regions[i].Region < h.Regions[j].Region })
+
+	for _, region := range expected {
+		stats, ok := regions[region]
+		if !ok || now.Sub(stats.lastSuccess) > maxAge {
+			h.MissingRegions = append(h.MissingRegions, region)
+		}
+	}
+
+	switch {
+	case len(regions) == 0:
+		h.Status = StatusUnknown
+	case failing:
+		h.Status = StatusFailing
+	case h.RecentErrors > 0 || len(h.MissingRegions) > 0:
+		h.Status = StatusDegraded
+	default:
+		h.Status = StatusOK
+	}
+
+	return h
+}
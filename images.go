@@ -0,0 +1,161 @@
+package cloudcraft
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+// ImageAsset is a custom image, such as a logo, placed on the canvas of a
+// blueprint. The API has no endpoint storing images, so the image is
+// referenced by URL: either a public one or a data URL holding the image
+// itself, see NewImageAsset.
+type ImageAsset struct {
+	Id  string
+	URL string
+
+	// Position of the top left corner of the image on the canvas grid.
+	MapPos [2]float64
+
+	// Size of the image in canvas units, the natural size if zero.
+	Width  float64
+	Height float64
+}
+
+func (d ImageAsset) String() string {
+	return Stringify(d)
+}
+
+// maxImageAssetSize is the size of the largest image embedded as data URL.
+const maxImageAssetSize = 1 << 20
+
+// NewImageAsset returns an ImageAsset embedding the image read from r as a
+// data URL. Images larger than 1 MiB are rejected, they must be hosted and
+// referenced by URL instead.
+func NewImageAsset(r io.Reader, contentType string) (*ImageAsset, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "image/") {
+		return nil, NewArgError("contentType", "must be an image media type")
+	}
+
+	content, err := ioutil.ReadAll(io.LimitReader(r, maxImageAssetSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("cloudcraft: reading image: %w", err)
+	}
+	if len(content) > maxImageAssetSize {
+		return nil, NewArgError("r", "image exceeds 1 MiB, reference it by URL")
+	}
+
+	return &ImageAsset{URL: "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(content)}, nil
+}
+
+// validate checks the ImageAsset can be placed on a blueprint.
+func (a *ImageAsset) validate() error {
+	if a == nil {
+		return NewArgError("image", "cannot be nil")
+	}
+
+	u, err := url.Parse(a.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http" && u.Scheme != "data") {
+		return NewArgError("image.URL", "must be an http, https or data URL")
+	}
+	if a.Width < 0 || a.Height < 0 {
+		return NewArgError("image.Width", "cannot be negative")
+	}
+	return nil
+}
+
+// element returns the image element of the blueprint data.
+func (a *ImageAsset) element() map[string]interface{} {
+	e := map[string]interface{}{
+		"id":     a.Id,
+		"type":   "image",
+		"url":    a.URL,
+		"mapPos": []interface{}{a.MapPos[0], a.MapPos[1]},
+	}
+	if a.Width > 0 {
+		e["width"] = a.Width
+	}
+	if a.Height > 0 {
+		e["height"] = a.Height
+	}
+	return e
+}
+
+// AddImage places the image on the canvas, or moves it if an image of the
+// same id already exists. An id is generated for images without one. It
+// returns the id of the image.
+func (d *BlueprintData) AddImage(image *ImageAsset) (string, error) {
+	if err := image.validate(); err != nil {
+		return "", err
+	}
+
+	img := *image
+	if img.Id == "" {
+		id, err := newUUID()
+		if err != nil {
+			return "", err
+		}
+		img.Id = id
+	}
+
+	e := img.element()
+	for i, existing := range d.Images {
+		if existing["id"] == img.Id {
+			d.Images[i] = e
+			return img.Id, nil
+		}
+	}
+
+	d.Images = append(d.Images, e)
+	return img.Id, nil
+}
+
+// RemoveImage removes the image of the given id from the canvas. It reports
+// whether the image existed.
+func (d *BlueprintData) RemoveImage(id string) bool {
+	for i, existing := range d.Images {
+		if existing["id"] == id {
+			d.Images = append(d.Images[:i], d.Images[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// AttachImage places an image on the canvas of a Blueprint and returns the
+// updated Blueprint. The Blueprint is updated as by Patch, under the same
+// precondition and keeping the data BlueprintData does not model.
+func (s *BlueprintsServiceOp) AttachImage(ctx context.Context, blueprintId string, image *ImageAsset, opts ...RequestOpt) (*Blueprint, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if blueprintId == "" {
+		return nil, nil, NewArgError("blueprintId", "cannot be empty")
+	}
+
+	if err := image.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	return s.patch(ctx, blueprintId, func(current json.RawMessage) ([]byte, error) {
+		data := new(BlueprintData)
+		if len(current) > 0 {
+			if err := json.Unmarshal(current, data); err != nil {
+				return nil, &DecodeError{Op: "attaching image to blueprint " + blueprintId, Err: err}
+			}
+		}
+		if _, err := data.AddImage(image); err != nil {
+			return nil, err
+		}
+
+		// A merge patch replaces arrays whole, so the patch holds every image.
+		return json.Marshal(map[string]interface{}{"images": data.Images})
+	})
+}
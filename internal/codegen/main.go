@@ -0,0 +1,483 @@
+// Command codegen generates Go code for the cloudcraft package from a subset
+// of an OpenAPI 3 specification of the Cloudcraft API. It is run with
+// go generate, see generate.go in the repository root.
+//
+// The following parts of the specification are used:
+//
+//   - string schemas with an enum become a string type with one constant per
+//     value, named after x-enum-varnames when present
+//   - object schemas become structs, one field per property, date-time
+//     strings becoming Timestamps
+//   - operations of a tag listed in x-go-services become methods of a
+//     generated <Tag>Service interface and <Tag>ServiceOp implementation,
+//     named after their operationId and taking per-call RequestOpts
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// spec is the subset of an OpenAPI document understood by the generator.
+type spec struct {
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+
+	// Services lists the tags whose operations are generated.
+	Services []string `json:"x-go-services"`
+}
+
+type operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary"`
+	Tags        []string             `json:"tags"`
+	Parameters  []parameter          `json:"parameters"`
+	RequestBody *content             `json:"requestBody"`
+	Responses   map[string]*response `json:"responses"`
+}
+
+type parameter struct {
+	Name string `json:"name"`
+	In   string `json:"in"`
+}
+
+type content struct {
+	Content map[string]struct {
+		Schema *schema `json:"schema"`
+	} `json:"content"`
+}
+
+type response struct {
+	content
+}
+
+type schema struct {
+	Ref         string             `json:"$ref"`
+	Type        string             `json:"type"`
+	Format      string             `json:"format"`
+	Description string             `json:"description"`
+	Enum        []string           `json:"enum"`
+	EnumNames   []string           `json:"x-enum-varnames"`
+	Properties  map[string]*schema `json:"properties"`
+	Required    []string           `json:"required"`
+	Items       *schema            `json:"items"`
+}
+
+func main() {
+	specPath := flag.String("spec", "api/openapi.json", "path of the OpenAPI specification")
+	outPath := flag.String("out", "openapi_gen.go", "path of the generated file")
+	pkg := flag.String("package", "cloudcraft", "package of the generated file")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: codegen [-spec api/openapi.json] [-out openapi_gen.go] [-package cloudcraft]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	log.SetFlags(0)
+	log.SetPrefix("codegen: ")
+
+	b, err := ioutil.ReadFile(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s := new(spec)
+	if err := json.Unmarshal(b, s); err != nil {
+		log.Fatalf("%s: %v", *specPath, err)
+	}
+
+	src, err := generate(s, *pkg, *specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(*outPath, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generator accumulates the generated source and its imports.
+type generator struct {
+	buf     bytes.Buffer
+	imports map[string]bool
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func generate(s *spec, pkg, specPath string) ([]byte, error) {
+	g := &generator{imports: make(map[string]bool)}
+
+	names := make([]string, 0, len(s.Components.Schemas))
+	for name := range s.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		sch := s.Components.Schemas[name]
+		switch {
+		case len(sch.Enum) > 0:
+			if err := g.genEnum(name, sch); err != nil {
+				return nil, err
+			}
+		case sch.Type == "object":
+			g.genStruct(name, sch)
+		}
+	}
+
+	for _, service := range s.Services {
+		if err := g.genService(s, service); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by internal/codegen from %s; DO NOT EDIT.\n\n", specPath)
+	fmt.Fprintf(&out, "package %s\n\n", pkg)
+
+	if len(g.imports) > 0 {
+		imports := make([]string, 0, len(g.imports))
+		for imp := range g.imports {
+			imports = append(imports, imp)
+		}
+		sort.Strings(imports)
+
+		out.WriteString("import (\n")
+		for _, imp := range imports {
+			fmt.Fprintf(&out, "\t%q\n", imp)
+		}
+		out.WriteString(")\n\n")
+	}
+
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated source: %v\n%s", err, out.Bytes())
+	}
+	return src, nil
+}
+
+func (g *generator) comment(name, description, fallback string) {
+	text := fallback
+	if description != "" {
+		text = name + " " + lowerFirst(description)
+	}
+	g.printf("// %s\n", strings.TrimSuffix(text, "."))
+}
+
+func (g *generator) genEnum(name string, sch *schema) error {
+	if len(sch.EnumNames) > 0 && len(sch.EnumNames) != len(sch.Enum) {
+		return fmt.Errorf("schema %s: x-enum-varnames must name every enum value", name)
+	}
+
+	g.comment(name, sch.Description, name+" is one of the values of the "+name+" enumeration.")
+	g.printf("type %s string\n\n", name)
+
+	g.printf("const (\n")
+	constNames := make([]string, len(sch.Enum))
+	for i, value := range sch.Enum {
+		suffix := exportedName(value)
+		if len(sch.EnumNames) > 0 {
+			suffix = sch.EnumNames[i]
+		}
+		constNames[i] = name + suffix
+		g.printf("\t%s %s = %q\n", constNames[i], name, value)
+	}
+	g.printf(")\n\n")
+
+	g.printf("var valid%ss = []%s{%s}\n\n", name, name, strings.Join(constNames, ", "))
+
+	g.printf("// Valid reports whether v is a known %s.\n", name)
+	g.printf("func (v %s) Valid() bool {\n", name)
+	g.printf("\tfor _, valid := range valid%ss {\n\t\tif v == valid {\n\t\t\treturn true\n\t\t}\n\t}\n\treturn false\n}\n\n", name)
+
+	g.printf("// %sValues returns the known %s values.\n", name, name)
+	g.printf("func %sValues() []string {\n", name)
+	g.printf("\tvalues := make([]string, len(valid%ss))\n", name)
+	g.printf("\tfor i, v := range valid%ss {\n\t\tvalues[i] = string(v)\n\t}\n\treturn values\n}\n\n", name)
+
+	return nil
+}
+
+func (g *generator) genStruct(name string, sch *schema) {
+	g.comment(name, sch.Description, name+" represents a Cloudcraft "+name)
+	g.printf("type %s struct {\n", name)
+
+	required := make(map[string]bool)
+	for _, r := range sch.Required {
+		required[r] = true
+	}
+
+	props := make([]string, 0, len(sch.Properties))
+	for prop := range sch.Properties {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+
+	for _, prop := range props {
+		tag := prop
+		if !required[prop] {
+			tag += ",omitempty"
+		}
+		g.printf("\t%s %s `json:%q`\n", exportedName(prop), g.goType(sch.Properties[prop]), tag)
+	}
+	g.printf("}\n\n")
+
+	g.printf("func (d %s) String() string {\n\treturn Stringify(d)\n}\n\n", name)
+}
+
+func (g *generator) goType(sch *schema) string {
+	if sch == nil {
+		return "interface{}"
+	}
+
+	if sch.Ref != "" {
+		return refName(sch.Ref)
+	}
+
+	switch sch.Type {
+	case "string":
+		if sch.Format == "date-time" {
+			return "Timestamp"
+		}
+		return "string"
+	case "integer":
+		return "int"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.goType(sch.Items)
+	case "object":
+		return "map[string]interface{}"
+	}
+
+	return "interface{}"
+}
+
+type genOperation struct {
+	method string
+	path   string
+	op     *operation
+}
+
+func (g *generator) genService(s *spec, service string) error {
+	var ops []genOperation
+	for path, methods := range s.Paths {
+		for method, op := range methods {
+			for _, tag := range op.Tags {
+				if tag == service {
+					ops = append(ops, genOperation{method: strings.ToUpper(method), path: path, op: op})
+				}
+			}
+		}
+	}
+
+	if len(ops) == 0 {
+		return fmt.Errorf("service %s has no operations", service)
+	}
+
+	sort.Slice(ops, func(i, j int) bool { return ops[i].op.OperationID < ops[j].op.OperationID })
+
+	g.imports["context"] = true
+	g.imports["net/http"] = true
+
+	g.printf("// %sService is an interface for interfacing with the %s\n// endpoints of the Cloudcraft API\n", service, service)
+	g.printf("type %sService interface {\n", service)
+	for _, o := range ops {
+		if o.op.OperationID == "" {
+			return fmt.Errorf("%s %s has no operationId", o.method, o.path)
+		}
+		params, results := g.signature(o.op, false)
+		g.printf("\t%s(%s) (%s)\n", o.op.OperationID, params, results)
+	}
+	g.printf("}\n\n")
+
+	g.printf("// %sServiceOp handles communication with the %s related methods of the\n// Cloudcraft API.\n", service, service)
+	g.printf("type %sServiceOp struct {\n\tclient *Client\n}\n\n", service)
+	g.printf("var _ %sService = &%sServiceOp{}\n\n", service, service)
+
+	for _, o := range ops {
+		if err := g.genMethod(service, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pathParams returns the path parameters of op in the order they appear.
+func pathParams(op *operation) []string {
+	var params []string
+	for _, p := range op.Parameters {
+		if p.In == "path" {
+			params = append(params, p.Name)
+		}
+	}
+	return params
+}
+
+func jsonSchema(c *content) *schema {
+	if c == nil {
+		return nil
+	}
+	if media, ok := c.Content["application/json"]; ok {
+		return media.Schema
+	}
+	return nil
+}
+
+func (g *generator) responseSchema(op *operation) *schema {
+	for _, code := range []string{"200", "201"} {
+		if r, ok := op.Responses[code]; ok {
+			return jsonSchema(&r.content)
+		}
+	}
+	return nil
+}
+
+// signature returns the parameters and results of the method for op, with
+// parameter names if named is set.
+func (g *generator) signature(op *operation, named bool) (string, string) {
+	var params []string
+	add := func(name, typ string) {
+		if named {
+			params = append(params, name+" "+typ)
+		} else {
+			params = append(params, typ)
+		}
+	}
+
+	add("ctx", "context.Context")
+	for _, p := range pathParams(op) {
+		add(p, "string")
+	}
+	if body := jsonSchema(op.RequestBody); body != nil {
+		add("body", "*"+g.goType(body))
+	}
+
+	add("opts", "...RequestOpt")
+
+	resp := g.responseSchema(op)
+	if resp == nil {
+		return strings.Join(params, ", "), "*Response, error"
+	}
+
+	typ := g.goType(resp)
+	if !strings.HasPrefix(typ, "[]") {
+		typ = "*" + typ
+	}
+	return strings.Join(params, ", "), typ + ", *Response, error"
+}
+
+func (g *generator) genMethod(service string, o genOperation) error {
+	op := o.op
+	params, results := g.signature(op, true)
+	resp := g.responseSchema(op)
+
+	nilResults := "nil, nil, "
+	if resp == nil {
+		nilResults = "nil, "
+	}
+
+	summary := op.Summary
+	if summary == "" {
+		summary = op.OperationID + " " + service + "."
+	}
+	g.printf("// %s\n", summary)
+	g.printf("func (s *%sServiceOp) %s(%s) (%s) {\n", service, op.OperationID, params, results)
+	g.printf("\tctx, cancel := ApplyRequestOpts(ctx, opts...)\n\tdefer cancel()\n\n")
+
+	path := o.path
+	var pathArgs []string
+	for _, p := range pathParams(op) {
+		g.printf("\tif %s == \"\" {\n\t\treturn %sNewArgError(%q, \"cannot be empty\")\n\t}\n\n", p, nilResults, p)
+		path = strings.Replace(path, "{"+p+"}", "%s", 1)
+		pathArgs = append(pathArgs, p)
+	}
+
+	body := "nil"
+	if jsonSchema(op.RequestBody) != nil {
+		g.printf("\tif body == nil {\n\t\treturn %sNewArgError(\"body\", \"cannot be nil\")\n\t}\n\n", nilResults)
+		body = "body"
+	}
+
+	path = strings.TrimPrefix(path, "/")
+	if len(pathArgs) > 0 {
+		g.imports["fmt"] = true
+		g.printf("\tpath := fmt.Sprintf(%q, %s)\n\n", path, strings.Join(pathArgs, ", "))
+	} else {
+		g.printf("\tpath := %q\n\n", path)
+	}
+
+	g.printf("\treq, err := s.client.NewRequest(ctx, http.Method%s, path, %s)\n", methodName(o.method), body)
+	g.printf("\tif err != nil {\n\t\treturn %serr\n\t}\n\n", nilResults)
+
+	if resp == nil {
+		g.printf("\tresp, err := s.client.Do(ctx, req, nil)\n\n\treturn resp, err\n}\n\n")
+		return nil
+	}
+
+	typ := g.goType(resp)
+	if strings.HasPrefix(typ, "[]") {
+		g.printf("\tvar result %s\n", typ)
+		g.printf("\tresp, err := s.client.Do(ctx, req, &result)\n")
+	} else {
+		g.printf("\tresult := new(%s)\n", typ)
+		g.printf("\tresp, err := s.client.Do(ctx, req, result)\n")
+	}
+	g.printf("\tif err != nil {\n\t\treturn nil, resp, err\n\t}\n\n\treturn result, resp, err\n}\n\n")
+
+	return nil
+}
+
+func methodName(method string) string {
+	return string(method[0]) + strings.ToLower(method[1:])
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// exportedName converts a JSON name or enum value such as "paperSize" or
+// "mxGraph" to an exported Go identifier.
+func exportedName(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
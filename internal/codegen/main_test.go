@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func generateFile(t *testing.T, specPath string) []byte {
+	t.Helper()
+
+	b, err := ioutil.ReadFile(specPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := new(spec)
+	if err := json.Unmarshal(b, s); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := generate(s, "cloudcraft", specPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func TestGenerateGolden(t *testing.T) {
+	const golden = "testdata/service.golden"
+
+	src := generateFile(t, "testdata/service.json")
+	if *update {
+		if err := ioutil.WriteFile(golden, src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, want) {
+		t.Errorf("generated source differs from %s, run go test -update:\n%s", golden, src)
+	}
+}
+
+// TestGenerateUpToDate checks openapi_gen.go was regenerated after the last
+// change of the specification or of the generator.
+func TestGenerateUpToDate(t *testing.T) {
+	src := generateFile(t, "../../api/openapi.json")
+	src = bytes.Replace(src, []byte("from ../../api/openapi.json"), []byte("from api/openapi.json"), 1)
+
+	want, err := ioutil.ReadFile("../../openapi_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, want) {
+		t.Error("openapi_gen.go is out of date, run go generate")
+	}
+}
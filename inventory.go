@@ -0,0 +1,90 @@
+package cloudcraft
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Inventory is a snapshot of the resources the API key of a client has
+// access to, see Client.ListAll.
+type Inventory struct {
+	User        *User        `json:"user"`
+	AwsAccounts []AwsAccount `json:"awsAccounts"`
+	Blueprints  []Blueprint  `json:"blueprints"`
+	FetchedAt   time.Time    `json:"fetchedAt"`
+}
+
+func (d Inventory) String() string {
+	return Stringify(d)
+}
+
+// ListAll fetches the current user, the AWS accounts and the Blueprints
+// concurrently, under the rate limit of opt, and returns them as an
+// Inventory. The first failure cancels the other fetches and is returned.
+// The concurrency and retries of opt are ignored: all of them are fetched
+// at once, and their requests are retried by the client as usual.
+func (c *Client) ListAll(ctx context.Context, opt *BatchOptions, opts ...RequestOpt) (*Inventory, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	var batchOpt BatchOptions
+	if opt != nil {
+		batchOpt = *opt
+	}
+	batchOpt.Concurrency = 3
+	batchOpt.Retries = 0
+
+	ctx, cancelAll := context.WithCancel(ctx)
+	defer cancelAll()
+
+	var (
+		inventory Inventory
+		once      sync.Once
+		firstErr  error
+	)
+	fail := func(what string, err error) error {
+		once.Do(func() {
+			firstErr = fmt.Errorf("cloudcraft: listing %s: %w", what, err)
+			cancelAll()
+		})
+		return err
+	}
+
+	batch := NewBatch(&batchOpt)
+	batch.Add("user", func(ctx context.Context) error {
+		user, _, err := c.Users.Me(ctx)
+		if err != nil {
+			return fail("user", err)
+		}
+		inventory.User = user
+		return nil
+	})
+	batch.Add("awsAccounts", func(ctx context.Context) error {
+		accounts, _, err := c.AwsAccounts.List(ctx)
+		if err != nil {
+			return fail("AWS accounts", err)
+		}
+		inventory.AwsAccounts = accounts
+		return nil
+	})
+	batch.Add("blueprints", func(ctx context.Context) error {
+		blueprints, _, err := c.Blueprints.List(ctx)
+		if err != nil {
+			return fail("blueprints", err)
+		}
+		inventory.Blueprints = blueprints
+		return nil
+	})
+
+	if err := batch.Run(ctx); err != nil {
+		if firstErr != nil {
+			return nil, firstErr
+		}
+		return nil, err
+	}
+
+	inventory.FetchedAt = time.Now().UTC()
+	return &inventory, nil
+}
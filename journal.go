@@ -0,0 +1,256 @@
+package cloudcraft
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JournalEntry records a mutating call to the API: who made it, on which
+// resource, with which payload and with which result.
+type JournalEntry struct {
+	Time      time.Time `json:"time"`
+	Actor     string    `json:"actor,omitempty"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	RequestID string    `json:"requestId,omitempty"`
+
+	// Resource is the collection of the call, e.g. "blueprint", and
+	// ResourceId the id of the resource, if any.
+	Resource   string `json:"resource"`
+	ResourceId string `json:"resourceId,omitempty"`
+
+	// PayloadSHA256 is the hex encoded SHA-256 of the request body, empty
+	// for bodies sent from an io.Reader, which cannot be read twice.
+	PayloadSHA256 string `json:"payloadSha256,omitempty"`
+
+	StatusCode int           `json:"statusCode,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration"`
+}
+
+func (d JournalEntry) String() string {
+	return Stringify(d)
+}
+
+// Journal records the mutating calls of a client, see SetJournal.
+type Journal interface {
+	Record(ctx context.Context, entry *JournalEntry) error
+}
+
+// JournalFunc is a function implementing Journal.
+type JournalFunc func(ctx context.Context, entry *JournalEntry) error
+
+// Record calls f.
+func (f JournalFunc) Record(ctx context.Context, entry *JournalEntry) error {
+	return f(ctx, entry)
+}
+
+// SetJournal is a client option recording every call to the API other than
+// GET, HEAD and OPTIONS to j once it completes, successfully or not, giving
+// a local change trail. The entries are attributed to actor, unless
+// overridden per call with the Actor request option. Failures to record an
+// entry are passed to onError if not nil, the call itself succeeding.
+func SetJournal(j Journal, actor string, onError func(*JournalEntry, error)) ClientOpt {
+	return func(c *Client) error {
+		if j == nil {
+			return NewArgError("j", "cannot be nil")
+		}
+
+		c.journal = j
+		c.journalActor = actor
+		c.onJournalError = onError
+		return nil
+	}
+}
+
+// Actor is a request option attributing the journal entry of the request to
+// actor, see SetJournal.
+func Actor(actor string) RequestOpt {
+	return func(o *requestOptions) {
+		o.actor = actor
+	}
+}
+
+// isMutating reports whether requests of method change resources.
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// newJournalEntry returns the entry of req, before it is sent.
+func (c *Client) newJournalEntry(ctx context.Context, req *http.Request) *JournalEntry {
+	entry := &JournalEntry{
+		Time:      time.Now(),
+		Actor:     c.journalActor,
+		Method:    req.Method,
+		Path:      strings.TrimPrefix(req.URL.Path, c.BaseURL.Path),
+		RequestID: req.Header.Get(headerRequestID),
+	}
+	if actor := c.requestOptionsOf(ctx).actor; actor != "" {
+		entry.Actor = actor
+	}
+
+	segments := strings.SplitN(strings.Trim(entry.Path, "/"), "/", 3)
+	entry.Resource = segments[0]
+	if len(segments) > 1 {
+		entry.ResourceId = segments[1]
+	}
+
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			h := sha256.New()
+			if _, err := io.Copy(h, body); err == nil {
+				entry.PayloadSHA256 = hex.EncodeToString(h.Sum(nil))
+			}
+			body.Close()
+		}
+	}
+
+	return entry
+}
+
+// recordJournal completes entry with the result of its call and records it.
+func (c *Client) recordJournal(ctx context.Context, entry *JournalEntry, resp *Response, err error) {
+	entry.Duration = time.Since(entry.Time)
+	if resp != nil && resp.Response != nil {
+		entry.StatusCode = resp.StatusCode
+	}
+	if err != nil {
+		entry.Error = err.Error()
+		if errResp, ok := err.(*ErrorResponse); ok && entry.StatusCode == 0 {
+			entry.StatusCode = errResp.Response.StatusCode
+		}
+	}
+
+	// The entry is recorded even if ctx was canceled during the call.
+	if rerr := c.journal.Record(detachedContext{ctx}, entry); rerr != nil && c.onJournalError != nil {
+		c.onJournalError(entry, rerr)
+	}
+}
+
+// detachedContext carries the values of a context, but not its
+// cancellation.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+// FileJournal is a Journal appending entries to a file as JSON lines.
+type FileJournal struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+var _ Journal = &FileJournal{}
+
+// OpenFileJournal opens the journal file at path, creating it if needed.
+func OpenFileJournal(path string) (*FileJournal, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileJournal{f: f}, nil
+}
+
+// Record appends entry to the file.
+func (j *FileJournal) Record(_ context.Context, entry *JournalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	_, err = j.f.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the file.
+func (j *FileJournal) Close() error {
+	return j.f.Close()
+}
+
+// SQLJournal is a Journal inserting entries into a table of a database,
+// such as a SQLite database opened with the driver of the caller. The
+// statements use ? placeholders, as SQLite and MySQL do. The table is
+// created by Init.
+type SQLJournal struct {
+	DB *sql.DB
+
+	// Table is the name of the table, cloudcraft_journal if empty. It is
+	// written into the statements, so it must be an identifier of letters,
+	// digits and underscores.
+	Table string
+}
+
+var _ Journal = &SQLJournal{}
+
+// Init creates the table of the journal if it does not exist.
+func (j *SQLJournal) Init(ctx context.Context) error {
+	table, err := j.table()
+	if err != nil {
+		return err
+	}
+
+	_, err = j.DB.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	time TIMESTAMP NOT NULL,
+	actor TEXT,
+	method TEXT NOT NULL,
+	path TEXT NOT NULL,
+	request_id TEXT,
+	resource TEXT NOT NULL,
+	resource_id TEXT,
+	payload_sha256 TEXT,
+	status_code INTEGER,
+	error TEXT,
+	duration_ms INTEGER NOT NULL
+)`, table))
+	return err
+}
+
+// Record inserts entry into the table.
+func (j *SQLJournal) Record(ctx context.Context, entry *JournalEntry) error {
+	table, err := j.table()
+	if err != nil {
+		return err
+	}
+
+	_, err = j.DB.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s
+	(time, actor, method, path, request_id, resource, resource_id, payload_sha256, status_code, error, duration_ms)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, table),
+		entry.Time.UTC(), entry.Actor, entry.Method, entry.Path, entry.RequestID, entry.Resource,
+		entry.ResourceId, entry.PayloadSHA256, entry.StatusCode, entry.Error, entry.Duration.Milliseconds())
+	return err
+}
+
+// sqlIdentifier matches the table names written into the statements.
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// table returns the name of the table, if it is a valid identifier.
+func (j *SQLJournal) table() (string, error) {
+	if j.Table == "" {
+		return "cloudcraft_journal", nil
+	}
+	if !sqlIdentifier.MatchString(j.Table) {
+		return "", NewArgError("Table", "must be an identifier of letters, digits and underscores")
+	}
+	return j.Table, nil
+}
@@ -0,0 +1,456 @@
+// Package layout places the nodes added to an existing blueprint, such as
+// the nodes synced from an inventory or added by a patch, so that they do
+// not overlap the existing geometry and stay within the groups, such as the
+// VPCs and subnets, they belong to.
+//
+// Nodes are placed on the grid of the blueprint one at a time, at the free
+// cell closest to the nodes they are connected to or to the other members
+// of their group. A group spans the cells of its members, plus a padding,
+// and groups not nested in one another must not overlap, so a node outside
+// a group is never placed within it and a group grows away from the others.
+package layout
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/updater/cloudcraft-go"
+)
+
+// Options configures Place.
+type Options struct {
+	// Spacing is the minimum distance between two nodes, in grid cells, 2
+	// if zero.
+	Spacing int
+
+	// Padding is the distance between the nodes of a group and its
+	// boundary, in grid cells, 1 if zero.
+	Padding int
+}
+
+// Placement is the position given to a node by Place.
+type Placement struct {
+	NodeId string  `json:"nodeId"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+
+	// Group is the innermost group of the node, if any.
+	Group string `json:"group,omitempty"`
+}
+
+// point is a cell of the grid.
+type point struct {
+	x, y int
+}
+
+// box is a rectangle of cells, bounds included.
+type box struct {
+	minX, minY, maxX, maxY int
+}
+
+func (b box) union(o box) box {
+	return box{minInt(b.minX, o.minX), minInt(b.minY, o.minY), maxInt(b.maxX, o.maxX), maxInt(b.maxY, o.maxY)}
+}
+
+func (b box) intersects(o box) bool {
+	return b.minX <= o.maxX && o.minX <= b.maxX && b.minY <= o.maxY && o.minY <= b.maxY
+}
+
+func (b box) contains(p point) bool {
+	return p.x >= b.minX && p.x <= b.maxX && p.y >= b.minY && p.y <= b.maxY
+}
+
+// group is a group of the blueprint.
+type group struct {
+	id      string
+	members map[string]bool
+}
+
+// encloses reports whether g is o or a group o is nested in.
+func (g *group) encloses(o *group) bool {
+	if len(o.members) > len(g.members) {
+		return false
+	}
+	for id := range o.members {
+		if !g.members[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// solver holds the state of a placement.
+type solver struct {
+	spacing, padding int
+
+	// positions of the placed nodes, and the other occupied cells.
+	positions map[string]point
+	occupied  []point
+
+	groups    []*group
+	neighbors map[string][]string
+}
+
+// Place positions the nodes of data without a position, and returns their
+// placements in the order of data. Nodes are added to a group by listing
+// their ids in the nodes of the group, before calling Place. It fails if a
+// group is boxed in by other groups and has no room left for a new member.
+func Place(data *cloudcraft.BlueprintData, opts *Options) ([]Placement, error) {
+	if data == nil {
+		return nil, cloudcraft.NewArgError("data", "cannot be nil")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	if opts.Spacing < 0 || opts.Padding < 0 {
+		return nil, cloudcraft.NewArgError("opts", "spacing and padding cannot be negative")
+	}
+
+	s := &solver{
+		spacing:   opts.Spacing,
+		padding:   opts.Padding,
+		positions: make(map[string]point),
+		neighbors: make(map[string][]string),
+	}
+	if s.spacing == 0 {
+		s.spacing = 2
+	}
+	if s.padding == 0 {
+		s.padding = 1
+	}
+
+	var pending []map[string]interface{}
+	for _, node := range data.Nodes {
+		id := stringField(node, "id")
+		if p, ok := position(node); ok {
+			if id != "" {
+				s.positions[id] = p
+			} else {
+				s.occupied = append(s.occupied, p)
+			}
+			continue
+		}
+		if id != "" {
+			pending = append(pending, node)
+		}
+	}
+	for _, collection := range [][]map[string]interface{}{data.Icons, data.Images, data.Text} {
+		for _, element := range collection {
+			if p, ok := position(element); ok {
+				s.occupied = append(s.occupied, p)
+			}
+		}
+	}
+
+	for _, element := range data.Groups {
+		g := &group{id: stringField(element, "id"), members: make(map[string]bool)}
+		members, _ := element["nodes"].([]interface{})
+		for _, member := range members {
+			if id, ok := member.(string); ok {
+				g.members[id] = true
+			}
+		}
+		s.groups = append(s.groups, g)
+	}
+
+	for _, edge := range data.Edges {
+		from, to := stringField(edge, "from"), stringField(edge, "to")
+		if from != "" && to != "" {
+			s.neighbors[from] = append(s.neighbors[from], to)
+			s.neighbors[to] = append(s.neighbors[to], from)
+		}
+	}
+
+	placed := make(map[string]Placement, len(pending))
+	for len(placed) < len(pending) {
+		// The nodes next to placed ones go first, so that chains of new
+		// nodes grow from the existing diagram.
+		next := -1
+		for i, node := range pending {
+			id := stringField(node, "id")
+			if _, ok := placed[id]; ok {
+				continue
+			}
+			if next == -1 {
+				next = i
+			}
+			if _, ok := s.anchor(id); ok {
+				next = i
+				break
+			}
+		}
+
+		node := pending[next]
+		id := stringField(node, "id")
+		p, err := s.place(id)
+		if err != nil {
+			return nil, err
+		}
+
+		s.positions[id] = p
+		node["mapPos"] = []interface{}{float64(p.x), float64(p.y)}
+
+		placement := Placement{NodeId: id, X: float64(p.x), Y: float64(p.y)}
+		if g := s.innermost(id); g != nil {
+			placement.Group = g.id
+		}
+		placed[id] = placement
+	}
+
+	placements := make([]Placement, 0, len(pending))
+	for _, node := range pending {
+		placements = append(placements, placed[stringField(node, "id")])
+	}
+	return placements, nil
+}
+
+// anchor returns the point a node is placed closest to: the center of its
+// placed neighbors, or else of the placed members of its innermost group
+// having some.
+func (s *solver) anchor(id string) (point, bool) {
+	var ids []string
+	for _, neighbor := range s.neighbors[id] {
+		if _, ok := s.positions[neighbor]; ok {
+			ids = append(ids, neighbor)
+		}
+	}
+
+	if len(ids) == 0 {
+		groups := s.groupsOf(id)
+		sort.Slice(groups, func(i, j int) bool { return len(groups[i].members) < len(groups[j].members) })
+		for _, g := range groups {
+			for member := range g.members {
+				if _, ok := s.positions[member]; ok {
+					ids = append(ids, member)
+				}
+			}
+			if len(ids) > 0 {
+				break
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return point{}, false
+	}
+
+	var x, y float64
+	for _, neighbor := range ids {
+		x += float64(s.positions[neighbor].x)
+		y += float64(s.positions[neighbor].y)
+	}
+	n := float64(len(ids))
+	return point{int(math.Round(x / n)), int(math.Round(y / n))}, true
+}
+
+// place returns the free cell closest to the anchor of a node satisfying the
+// constraints of its groups.
+func (s *solver) place(id string) (point, error) {
+	extent, ok := s.extent()
+	anchor, hasAnchor := s.anchor(id)
+	switch {
+	case !ok:
+		// Empty blueprint.
+	case !hasAnchor:
+		// Nodes unrelated to the diagram go to its right.
+		anchor = point{extent.maxX + s.spacing + s.padding, extent.minY}
+	}
+
+	maxRadius := s.spacing*(len(s.positions)+1) + 2*s.padding + 1
+	if ok {
+		maxRadius += maxInt(extent.maxX-extent.minX, extent.maxY-extent.minY)
+	}
+
+	own := s.groupsOf(id)
+	for r := 0; r <= maxRadius; r++ {
+		var best *point
+		bestDistance := math.Inf(1)
+		for _, p := range ring(anchor, r) {
+			if !s.fits(id, p, own) {
+				continue
+			}
+			if d := math.Hypot(float64(p.x-anchor.x), float64(p.y-anchor.y)); d < bestDistance {
+				p := p
+				best, bestDistance = &p, d
+			}
+		}
+		if best != nil {
+			return *best, nil
+		}
+	}
+
+	if g := s.innermost(id); g != nil {
+		return point{}, fmt.Errorf("layout: no room for node %s in group %s", id, g.id)
+	}
+	return point{}, fmt.Errorf("layout: no room for node %s", id)
+}
+
+// fits reports whether a node of the given groups may be placed at p.
+func (s *solver) fits(id string, p point, own []*group) bool {
+	for _, q := range s.positions {
+		if chebyshev(p, q) < s.spacing {
+			return false
+		}
+	}
+	for _, q := range s.occupied {
+		if chebyshev(p, q) < s.spacing {
+			return false
+		}
+	}
+
+	for _, g := range s.groups {
+		b, ok := s.box(g)
+		if !ok {
+			continue
+		}
+		if !g.members[id] && b.contains(p) {
+			return false
+		}
+	}
+
+	// The groups of the node grow to hold it, and must not overlap the
+	// groups they are not nested with.
+	cell := box{p.x - s.padding, p.y - s.padding, p.x + s.padding, p.y + s.padding}
+	for _, g := range own {
+		grown := cell
+		if b, ok := s.box(g); ok {
+			grown = b.union(cell)
+		}
+
+		for _, o := range s.groups {
+			if o.members[id] || g.encloses(o) || o.encloses(g) {
+				continue
+			}
+			if b, ok := s.box(o); ok && grown.intersects(b) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// box returns the cells spanned by the placed members of g.
+func (s *solver) box(g *group) (box, bool) {
+	var b box
+	found := false
+	for member := range g.members {
+		p, ok := s.positions[member]
+		if !ok {
+			continue
+		}
+		cell := box{p.x - s.padding, p.y - s.padding, p.x + s.padding, p.y + s.padding}
+		if !found {
+			b, found = cell, true
+		} else {
+			b = b.union(cell)
+		}
+	}
+	return b, found
+}
+
+// extent returns the cells spanned by the placed nodes and other elements.
+func (s *solver) extent() (box, bool) {
+	var b box
+	found := false
+	add := func(p point) {
+		cell := box{p.x, p.y, p.x, p.y}
+		if !found {
+			b, found = cell, true
+		} else {
+			b = b.union(cell)
+		}
+	}
+	for _, p := range s.positions {
+		add(p)
+	}
+	for _, p := range s.occupied {
+		add(p)
+	}
+	return b, found
+}
+
+// groupsOf returns the groups holding a node.
+func (s *solver) groupsOf(id string) []*group {
+	var groups []*group
+	for _, g := range s.groups {
+		if g.members[id] {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// innermost returns the smallest group holding a node, nil if none.
+func (s *solver) innermost(id string) *group {
+	var inner *group
+	for _, g := range s.groupsOf(id) {
+		if inner == nil || len(g.members) < len(inner.members) {
+			inner = g
+		}
+	}
+	return inner
+}
+
+// ring returns the cells at a Chebyshev distance of r from center.
+func ring(center point, r int) []point {
+	if r == 0 {
+		return []point{center}
+	}
+
+	points := make([]point, 0, 8*r)
+	for i := -r; i <= r; i++ {
+		points = append(points,
+			point{center.x + i, center.y - r},
+			point{center.x + i, center.y + r})
+	}
+	for i := -r + 1; i <= r-1; i++ {
+		points = append(points,
+			point{center.x - r, center.y + i},
+			point{center.x + r, center.y + i})
+	}
+	return points
+}
+
+func chebyshev(p, q point) int {
+	return maxInt(absInt(p.x-q.x), absInt(p.y-q.y))
+}
+
+func stringField(element map[string]interface{}, key string) string {
+	s, _ := element[key].(string)
+	return s
+}
+
+// position returns the cell of an element positioned with a [x, y] mapPos.
+func position(element map[string]interface{}) (point, bool) {
+	mapPos, _ := element["mapPos"].([]interface{})
+	if len(mapPos) != 2 {
+		return point{}, false
+	}
+	x, okX := mapPos[0].(float64)
+	y, okY := mapPos[1].(float64)
+	if !okX || !okY {
+		return point{}, false
+	}
+	return point{int(math.Round(x)), int(math.Round(y))}, true
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func absInt(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
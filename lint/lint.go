@@ -0,0 +1,181 @@
+// Package lint checks Cloudcraft blueprints against a set of quality rules,
+// such as unlabeled nodes or edges pointing to missing components, so that
+// diagram conventions can be enforced across many teams.
+package lint
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/updater/cloudcraft-go"
+)
+
+// Severity is the importance of a Finding.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+
+	// SeverityOff disables a rule.
+	SeverityOff Severity = "off"
+)
+
+var severityRanks = map[Severity]int{
+	SeverityOff:     0,
+	SeverityInfo:    1,
+	SeverityWarning: 2,
+	SeverityError:   3,
+}
+
+// Valid reports whether s is a known Severity.
+func (s Severity) Valid() bool {
+	_, ok := severityRanks[s]
+	return ok
+}
+
+// AtLeast reports whether s is as important as min.
+func (s Severity) AtLeast(min Severity) bool {
+	return severityRanks[s] >= severityRanks[min]
+}
+
+// Finding is a rule violation found in a blueprint.
+type Finding struct {
+	Rule       string   `json:"rule"`
+	Severity   Severity `json:"severity"`
+	Collection string   `json:"collection"`
+	ElementId  string   `json:"elementId"`
+	Message    string   `json:"message"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s %s: %s (%s)", f.Severity, f.Collection, f.ElementId, f.Message, f.Rule)
+}
+
+// Rule is a check run against the data of a blueprint.
+type Rule struct {
+	Name            string
+	Description     string
+	DefaultSeverity Severity
+	Check           func(*Blueprint, *Config) []Finding
+}
+
+// Config configures a lint run. The zero value runs every rule with its
+// default severity.
+type Config struct {
+	// Severities overrides the severity of rules by name.
+	Severities map[string]Severity `json:"severities,omitempty"`
+
+	// DeprecatedTypes maps deprecated node types to the reason they are
+	// deprecated, usually what to use instead.
+	DeprecatedTypes map[string]string `json:"deprecatedTypes,omitempty"`
+
+	// GroupTypes restricts the groups considered by the ungrouped-node rule,
+	// e.g. []string{"vpc"}. All groups are considered if empty.
+	GroupTypes []string `json:"groupTypes,omitempty"`
+}
+
+// Validate checks the severities of c name known rules and severities.
+func (c *Config) Validate() error {
+	for name, severity := range c.Severities {
+		if !knownRule(name) {
+			return cloudcraft.NewArgError("Severities", fmt.Sprintf("has unknown rule %q", name))
+		}
+		if !severity.Valid() {
+			return cloudcraft.NewArgError("Severities", fmt.Sprintf("has unknown severity %q for rule %q", severity, name))
+		}
+	}
+	return nil
+}
+
+func (c *Config) severity(rule Rule) Severity {
+	if c != nil {
+		if s, ok := c.Severities[rule.Name]; ok {
+			return s
+		}
+	}
+	return rule.DefaultSeverity
+}
+
+// Rules returns the built-in rules.
+func Rules() []Rule {
+	return []Rule{
+		{
+			Name:            "unlabeled-node",
+			Description:     "nodes should have a name or a text label attached",
+			DefaultSeverity: SeverityWarning,
+			Check:           checkUnlabeledNodes,
+		},
+		{
+			Name:            "dangling-edge",
+			Description:     "edges must connect existing components",
+			DefaultSeverity: SeverityError,
+			Check:           checkDanglingEdges,
+		},
+		{
+			Name:            "ungrouped-node",
+			Description:     "nodes should be placed inside a VPC or group",
+			DefaultSeverity: SeverityInfo,
+			Check:           checkUngroupedNodes,
+		},
+		{
+			Name:            "deprecated-node-type",
+			Description:     "nodes should not use deprecated types",
+			DefaultSeverity: SeverityWarning,
+			Check:           checkDeprecatedTypes,
+		},
+	}
+}
+
+func knownRule(name string) bool {
+	for _, rule := range Rules() {
+		if rule.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint runs the built-in rules on data and returns the findings, ordered by
+// decreasing severity. A nil Config uses the defaults.
+func Lint(data *cloudcraft.BlueprintData, cfg *Config) ([]Finding, error) {
+	if cfg != nil {
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	b := newBlueprint(data)
+
+	findings := []Finding{}
+	for _, rule := range Rules() {
+		severity := cfg.severity(rule)
+		if severity == SeverityOff {
+			continue
+		}
+
+		for _, f := range rule.Check(b, cfg) {
+			f.Rule = rule.Name
+			f.Severity = severity
+			findings = append(findings, f)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityRanks[findings[i].Severity] > severityRanks[findings[j].Severity]
+	})
+
+	return findings, nil
+}
+
+// Count returns the number of findings at least as important as min.
+func Count(findings []Finding, min Severity) int {
+	n := 0
+	for _, f := range findings {
+		if f.Severity.AtLeast(min) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,157 @@
+package lint
+
+import (
+	"fmt"
+
+	"github.com/updater/cloudcraft-go"
+)
+
+// Blueprint indexes the data of a blueprint for the rules.
+type Blueprint struct {
+	Data *cloudcraft.BlueprintData
+
+	// ids holds the id of every element of the blueprint.
+	ids map[string]bool
+
+	// labeled holds the ids of the elements a text is attached to.
+	labeled map[string]bool
+}
+
+func newBlueprint(data *cloudcraft.BlueprintData) *Blueprint {
+	if data == nil {
+		data = &cloudcraft.BlueprintData{}
+	}
+
+	b := &Blueprint{Data: data, ids: make(map[string]bool), labeled: make(map[string]bool)}
+
+	for _, collection := range [][]map[string]interface{}{
+		data.Nodes, data.Groups, data.Text, data.Icons, data.Images, data.Surfaces, data.Connectors,
+	} {
+		for _, element := range collection {
+			if id := stringField(element, "id"); id != "" {
+				b.ids[id] = true
+			}
+		}
+	}
+
+	for _, text := range data.Text {
+		if mapPos, ok := text["mapPos"].(map[string]interface{}); ok {
+			if relTo := stringField(mapPos, "relTo"); relTo != "" && stringField(text, "text") != "" {
+				b.labeled[relTo] = true
+			}
+		}
+	}
+
+	return b
+}
+
+func stringField(element map[string]interface{}, key string) string {
+	s, _ := element[key].(string)
+	return s
+}
+
+func checkUnlabeledNodes(b *Blueprint, cfg *Config) []Finding {
+	var findings []Finding
+	for _, node := range b.Data.Nodes {
+		id := stringField(node, "id")
+		if b.labeled[id] || stringField(node, "name") != "" || stringField(node, "label") != "" {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Collection: "nodes",
+			ElementId:  id,
+			Message:    fmt.Sprintf("%s node has no label", stringField(node, "type")),
+		})
+	}
+	return findings
+}
+
+func checkDanglingEdges(b *Blueprint, cfg *Config) []Finding {
+	var findings []Finding
+	for _, edge := range b.Data.Edges {
+		for _, end := range []string{"from", "to"} {
+			target := stringField(edge, end)
+			if target != "" && b.ids[target] {
+				continue
+			}
+
+			message := fmt.Sprintf("edge has no %q component", end)
+			if target != "" {
+				message = fmt.Sprintf("edge %q component %s does not exist", end, target)
+			}
+
+			findings = append(findings, Finding{
+				Collection: "edges",
+				ElementId:  stringField(edge, "id"),
+				Message:    message,
+			})
+		}
+	}
+	return findings
+}
+
+func checkUngroupedNodes(b *Blueprint, cfg *Config) []Finding {
+	groupTypes := make(map[string]bool)
+	if cfg != nil {
+		for _, t := range cfg.GroupTypes {
+			groupTypes[t] = true
+		}
+	}
+
+	grouped := make(map[string]bool)
+	for _, group := range b.Data.Groups {
+		if len(groupTypes) > 0 && !groupTypes[stringField(group, "type")] {
+			continue
+		}
+
+		members, _ := group["nodes"].([]interface{})
+		for _, member := range members {
+			if id, ok := member.(string); ok {
+				grouped[id] = true
+			}
+		}
+	}
+
+	var findings []Finding
+	for _, node := range b.Data.Nodes {
+		id := stringField(node, "id")
+		if grouped[id] {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Collection: "nodes",
+			ElementId:  id,
+			Message:    fmt.Sprintf("%s node is not inside any group", stringField(node, "type")),
+		})
+	}
+	return findings
+}
+
+func checkDeprecatedTypes(b *Blueprint, cfg *Config) []Finding {
+	if cfg == nil || len(cfg.DeprecatedTypes) == 0 {
+		return nil
+	}
+
+	var findings []Finding
+	for _, node := range b.Data.Nodes {
+		nodeType := stringField(node, "type")
+		reason, ok := cfg.DeprecatedTypes[nodeType]
+		if !ok {
+			continue
+		}
+
+		message := fmt.Sprintf("node type %s is deprecated", nodeType)
+		if reason != "" {
+			message += ": " + reason
+		}
+
+		findings = append(findings, Finding{
+			Collection: "nodes",
+			ElementId:  stringField(node, "id"),
+			Message:    message,
+		})
+	}
+	return findings
+}
@@ -0,0 +1,53 @@
+package cloudcraft
+
+import (
+	"encoding/json"
+)
+
+// MergePatch applies an RFC 7386 JSON merge patch to the JSON document doc
+// and returns the patched document.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var patchValue interface{}
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, NewArgError("patch", "must be valid JSON")
+	}
+
+	var docValue interface{}
+	if len(doc) > 0 {
+		if err := json.Unmarshal(doc, &docValue); err != nil {
+			return nil, NewArgError("doc", "must be valid JSON")
+		}
+	}
+
+	patched, err := json.Marshal(mergePatchValue(docValue, patchValue))
+	if err != nil {
+		return nil, &EncodeError{Op: "merge patch", Err: err}
+	}
+
+	return patched, nil
+}
+
+// mergePatchValue implements the MergePatch algorithm described in RFC 7386
+// section 2 on decoded JSON values.
+func mergePatchValue(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+
+	for k, v := range patchObject {
+		if v == nil {
+			delete(targetObject, k)
+			continue
+		}
+
+		targetObject[k] = mergePatchValue(targetObject[k], v)
+	}
+
+	return targetObject
+}
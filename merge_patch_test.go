@@ -0,0 +1,58 @@
+package cloudcraft
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"replace value", `{"a": "b"}`, `{"a": "c"}`, `{"a": "c"}`},
+		{"add key", `{"a": "b"}`, `{"b": "c"}`, `{"a": "b", "b": "c"}`},
+		{"null deletes key", `{"a": "b", "b": "c"}`, `{"a": null}`, `{"b": "c"}`},
+		{"null deletes missing key", `{"a": "b"}`, `{"c": null}`, `{"a": "b"}`},
+		{"array replaced", `{"a": [1, 2, 3]}`, `{"a": [4]}`, `{"a": [4]}`},
+		{"nested objects merge", `{"a": {"b": 1, "c": 2}}`, `{"a": {"c": 3, "d": 4}}`, `{"a": {"b": 1, "c": 3, "d": 4}}`},
+		{"nested null deletes key", `{"a": {"b": 1, "c": 2}}`, `{"a": {"b": null}}`, `{"a": {"c": 2}}`},
+		{"object replaces value", `{"a": "b"}`, `{"a": {"c": 1}}`, `{"a": {"c": 1}}`},
+		{"non-object patch replaces document", `{"a": "b"}`, `["c"]`, `["c"]`},
+		{"null patch replaces document", `{"a": "b"}`, `null`, `null`},
+		{"empty document", ``, `{"a": "b"}`, `{"a": "b"}`},
+		{"object patch replaces non-object document", `["a"]`, `{"b": "c"}`, `{"b": "c"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("MergePatch() returned error: %v", err)
+			}
+
+			var gotValue, wantValue interface{}
+			if err := json.Unmarshal(got, &gotValue); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &wantValue); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(gotValue, wantValue) {
+				t.Errorf("MergePatch(%s, %s) = %s, want %s", tt.doc, tt.patch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergePatchInvalid(t *testing.T) {
+	if _, err := MergePatch([]byte(`{}`), []byte(`{`)); err == nil {
+		t.Error("MergePatch with an invalid patch returned no error")
+	}
+	if _, err := MergePatch([]byte(`{`), []byte(`{}`)); err == nil {
+		t.Error("MergePatch with an invalid document returned no error")
+	}
+}
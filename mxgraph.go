@@ -0,0 +1,81 @@
+package cloudcraft
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+)
+
+// MxGraphModel is a blueprint exported in the mxGraph format, as read by
+// draw.io and other mxGraph based editors.
+type MxGraphModel struct {
+	XMLName xml.Name `xml:"mxGraphModel"`
+	Cells   []MxCell `xml:"root>mxCell"`
+}
+
+func (m MxGraphModel) String() string {
+	return Stringify(m)
+}
+
+// MxCell is a vertex or an edge of an MxGraphModel. Parent holds the id of
+// the cell containing it, Source and Target the ids of the cells an edge
+// connects.
+type MxCell struct {
+	Id       string      `xml:"id,attr"`
+	Parent   string      `xml:"parent,attr,omitempty"`
+	Value    string      `xml:"value,attr,omitempty"`
+	Style    string      `xml:"style,attr,omitempty"`
+	Vertex   string      `xml:"vertex,attr,omitempty"`
+	Edge     string      `xml:"edge,attr,omitempty"`
+	Source   string      `xml:"source,attr,omitempty"`
+	Target   string      `xml:"target,attr,omitempty"`
+	Geometry *MxGeometry `xml:"mxGeometry,omitempty"`
+}
+
+// IsVertex reports whether the cell is a vertex.
+func (c *MxCell) IsVertex() bool {
+	return c.Vertex == "1"
+}
+
+// IsEdge reports whether the cell is an edge.
+func (c *MxCell) IsEdge() bool {
+	return c.Edge == "1"
+}
+
+// MxGeometry is the position and size of an MxCell.
+type MxGeometry struct {
+	X        float64 `xml:"x,attr,omitempty"`
+	Y        float64 `xml:"y,attr,omitempty"`
+	Width    float64 `xml:"width,attr,omitempty"`
+	Height   float64 `xml:"height,attr,omitempty"`
+	Relative string  `xml:"relative,attr,omitempty"`
+	As       string  `xml:"as,attr,omitempty"`
+}
+
+// ExportMxGraph exports a Blueprint in the mxGraph format and decodes it.
+func (s *BlueprintsServiceOp) ExportMxGraph(ctx context.Context, blueprintId string, exportParameters *BlueprintExportParameters, opts ...RequestOpt) (*MxGraphModel, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	req, err := s.newExportRequest(ctx, blueprintId, &BlueprintExportRequest{
+		Format:           string(ExportFormatMxGraph),
+		ExportParameters: exportParameters,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The content is decoded as XML whatever the Content-Type announced.
+	content := new(bytes.Buffer)
+	resp, err := s.client.Do(ctx, req, content)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	model := new(MxGraphModel)
+	if err := s.client.codecFor("application/xml").Decode(content, model); err != nil {
+		return nil, resp, &DecodeError{Op: requestOp(req), Err: err}
+	}
+
+	return model, resp, nil
+}
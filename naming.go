@@ -0,0 +1,206 @@
+package cloudcraft
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// NamingPolicy is an organization-wide naming standard of blueprints. A
+// name must match Pattern, and start with one of the prefixes of each of
+// the scopes, such as a team or an environment, the blueprint belongs to.
+type NamingPolicy struct {
+	// Pattern is a regular expression names must match, e.g.
+	// `^[a-z0-9-]+$`. Any name matches if empty.
+	Pattern string
+
+	// Prefixes maps a scope to the prefixes the names of the blueprints of
+	// the scope must start with one of, e.g. "prod" to {"prod-"}.
+	Prefixes map[string][]string
+
+	// MaxLength is the maximum length of names, in characters, unlimited
+	// if zero.
+	MaxLength int
+
+	pattern *regexp.Regexp
+}
+
+// NamingViolation is a name breaking a NamingPolicy.
+type NamingViolation struct {
+	BlueprintId string `json:"blueprintId,omitempty"`
+	Name        string `json:"name"`
+	Scope       string `json:"scope,omitempty"`
+	Reason      string `json:"reason"`
+}
+
+func (d NamingViolation) String() string {
+	return Stringify(d)
+}
+
+// NamingPolicyError is returned by Blueprints.Create, Blueprints.Update and
+// Blueprints.Apply for data whose name breaks the naming policy of the
+// client, before any request is sent.
+type NamingPolicyError struct {
+	Violations []NamingViolation
+}
+
+var _ error = &NamingPolicyError{}
+
+func (e *NamingPolicyError) Error() string {
+	reasons := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		reasons[i] = v.Reason
+	}
+	return fmt.Sprintf("cloudcraft: blueprint name %q breaks the naming policy: %s", e.Violations[0].Name, strings.Join(reasons, ", "))
+}
+
+// Compile checks the policy and compiles its pattern. It is called by
+// SetNamingPolicy, and by Check if needed: a policy shared by goroutines
+// must be compiled first.
+func (p *NamingPolicy) Compile() error {
+	if p.pattern != nil || p.Pattern == "" {
+		return nil
+	}
+
+	re, err := regexp.Compile(p.Pattern)
+	if err != nil {
+		return NewArgError("policy.Pattern", err.Error())
+	}
+	p.pattern = re
+	return nil
+}
+
+// Check returns the violations of the policy by name, for a blueprint
+// belonging to scopes. Scopes without prefixes in the policy accept any
+// name.
+func (p *NamingPolicy) Check(name string, scopes ...string) ([]NamingViolation, error) {
+	if err := p.Compile(); err != nil {
+		return nil, err
+	}
+
+	var violations []NamingViolation
+	violate := func(scope, reason string) {
+		violations = append(violations, NamingViolation{Name: name, Scope: scope, Reason: reason})
+	}
+
+	if name == "" {
+		violate("", "name is empty")
+		return violations, nil
+	}
+	if p.pattern != nil && !p.pattern.MatchString(name) {
+		violate("", fmt.Sprintf("name does not match %s", p.Pattern))
+	}
+	if p.MaxLength > 0 && len([]rune(name)) > p.MaxLength {
+		violate("", fmt.Sprintf("name is longer than %d characters", p.MaxLength))
+	}
+
+	for _, scope := range scopes {
+		prefixes, ok := p.Prefixes[scope]
+		if !ok || len(prefixes) == 0 {
+			continue
+		}
+
+		matched := false
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(name, prefix) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			violate(scope, fmt.Sprintf("name in scope %s does not start with %s", scope, strings.Join(prefixes, " or ")))
+		}
+	}
+
+	return violations, nil
+}
+
+// Scan returns the violations of the policy by the names of blueprints,
+// in their order. scopesOf returns the scopes of a blueprint, which has no
+// scope if scopesOf is nil.
+func (p *NamingPolicy) Scan(blueprints []Blueprint, scopesOf func(Blueprint) []string) ([]NamingViolation, error) {
+	violations := []NamingViolation{}
+	for _, blueprint := range blueprints {
+		var scopes []string
+		if scopesOf != nil {
+			scopes = scopesOf(blueprint)
+		}
+
+		found, err := p.Check(blueprint.Name, scopes...)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range found {
+			v.BlueprintId = blueprint.Id
+			violations = append(violations, v)
+		}
+	}
+	return violations, nil
+}
+
+// SetNamingPolicy is a client option checking the names of the blueprints
+// created or updated by the client against policy. The scopes of a call
+// are given with the NamingScopes request option. Merge patches of
+// Blueprints.Patch are not checked.
+func SetNamingPolicy(policy *NamingPolicy) ClientOpt {
+	return func(c *Client) error {
+		if policy == nil {
+			return NewArgError("policy", "cannot be nil")
+		}
+		if err := policy.Compile(); err != nil {
+			return err
+		}
+
+		c.namingPolicy = policy
+		return nil
+	}
+}
+
+// NamingScopes is a request option giving the scopes, such as the team or
+// the environment, of the blueprint created or updated by the request, see
+// SetNamingPolicy.
+func NamingScopes(scopes ...string) RequestOpt {
+	return func(o *requestOptions) {
+		o.namingScopes = append(o.namingScopes, scopes...)
+	}
+}
+
+// checkName checks the name of data against the naming policy of s, if
+// any.
+func (s *BlueprintsServiceOp) checkName(ctx context.Context, data *BlueprintData) error {
+	policy := s.client.namingPolicy
+	if policy == nil {
+		return nil
+	}
+
+	var name string
+	if data != nil {
+		name = data.Name
+	}
+
+	violations, err := policy.Check(name, s.client.requestOptionsOf(ctx).namingScopes...)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &NamingPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// ScanNames lists the Blueprints and returns the violations of policy by
+// their names, see NamingPolicy.Scan.
+func (s *BlueprintsServiceOp) ScanNames(ctx context.Context, policy *NamingPolicy, scopesOf func(Blueprint) []string, opts ...RequestOpt) ([]NamingViolation, *Response, error) {
+	if policy == nil {
+		return nil, nil, NewArgError("policy", "cannot be nil")
+	}
+
+	blueprints, resp, err := s.List(ctx, opts...)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	violations, err := policy.Scan(blueprints, scopesOf)
+	return violations, resp, err
+}
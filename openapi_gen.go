@@ -0,0 +1,222 @@
+// Code generated by internal/codegen from api/openapi.json; DO NOT EDIT.
+
+package cloudcraft
+
+// BudgetFormat is the format of a blueprint budget export
+type BudgetFormat string
+
+const (
+	BudgetFormatCsv  BudgetFormat = "csv"
+	BudgetFormatXlsx BudgetFormat = "xlsx"
+)
+
+var validBudgetFormats = []BudgetFormat{BudgetFormatCsv, BudgetFormatXlsx}
+
+// Valid reports whether v is a known BudgetFormat.
+func (v BudgetFormat) Valid() bool {
+	for _, valid := range validBudgetFormats {
+		if v == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// BudgetFormatValues returns the known BudgetFormat values.
+func BudgetFormatValues() []string {
+	values := make([]string, len(validBudgetFormats))
+	for i, v := range validBudgetFormats {
+		values[i] = string(v)
+	}
+	return values
+}
+
+// BudgetPeriod is the period the costs of a budget are computed for
+type BudgetPeriod string
+
+const (
+	BudgetPeriodHourly  BudgetPeriod = "hm"
+	BudgetPeriodDaily   BudgetPeriod = "d"
+	BudgetPeriodWeekly  BudgetPeriod = "w"
+	BudgetPeriodMonthly BudgetPeriod = "m"
+	BudgetPeriodYearly  BudgetPeriod = "y"
+)
+
+var validBudgetPeriods = []BudgetPeriod{BudgetPeriodHourly, BudgetPeriodDaily, BudgetPeriodWeekly, BudgetPeriodMonthly, BudgetPeriodYearly}
+
+// Valid reports whether v is a known BudgetPeriod.
+func (v BudgetPeriod) Valid() bool {
+	for _, valid := range validBudgetPeriods {
+		if v == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// BudgetPeriodValues returns the known BudgetPeriod values.
+func BudgetPeriodValues() []string {
+	values := make([]string, len(validBudgetPeriods))
+	for i, v := range validBudgetPeriods {
+		values[i] = string(v)
+	}
+	return values
+}
+
+// ExportFormat is the format of a blueprint export
+type ExportFormat string
+
+const (
+	ExportFormatSvg     ExportFormat = "svg"
+	ExportFormatPng     ExportFormat = "png"
+	ExportFormatPdf     ExportFormat = "pdf"
+	ExportFormatMxGraph ExportFormat = "mxGraph"
+)
+
+var validExportFormats = []ExportFormat{ExportFormatSvg, ExportFormatPng, ExportFormatPdf, ExportFormatMxGraph}
+
+// Valid reports whether v is a known ExportFormat.
+func (v ExportFormat) Valid() bool {
+	for _, valid := range validExportFormats {
+		if v == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportFormatValues returns the known ExportFormat values.
+func ExportFormatValues() []string {
+	values := make([]string, len(validExportFormats))
+	for i, v := range validExportFormats {
+		values[i] = string(v)
+	}
+	return values
+}
+
+// ExportTheme is the color theme of a blueprint export
+type ExportTheme string
+
+const (
+	ExportThemeLight ExportTheme = "light"
+	ExportThemeDark  ExportTheme = "dark"
+)
+
+var validExportThemes = []ExportTheme{ExportThemeLight, ExportThemeDark}
+
+// Valid reports whether v is a known ExportTheme.
+func (v ExportTheme) Valid() bool {
+	for _, valid := range validExportThemes {
+		if v == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportThemeValues returns the known ExportTheme values.
+func ExportThemeValues() []string {
+	values := make([]string, len(validExportThemes))
+	for i, v := range validExportThemes {
+		values[i] = string(v)
+	}
+	return values
+}
+
+// PaperSize is the paper size of PDF exports and snapshots
+type PaperSize string
+
+const (
+	PaperSizeLetter  PaperSize = "Letter"
+	PaperSizeLegal   PaperSize = "Legal"
+	PaperSizeTabloid PaperSize = "Tabloid"
+	PaperSizeLedger  PaperSize = "Ledger"
+	PaperSizeA0      PaperSize = "A0"
+	PaperSizeA1      PaperSize = "A1"
+	PaperSizeA2      PaperSize = "A2"
+	PaperSizeA3      PaperSize = "A3"
+	PaperSizeA4      PaperSize = "A4"
+	PaperSizeA5      PaperSize = "A5"
+)
+
+var validPaperSizes = []PaperSize{PaperSizeLetter, PaperSizeLegal, PaperSizeTabloid, PaperSizeLedger, PaperSizeA0, PaperSizeA1, PaperSizeA2, PaperSizeA3, PaperSizeA4, PaperSizeA5}
+
+// Valid reports whether v is a known PaperSize.
+func (v PaperSize) Valid() bool {
+	for _, valid := range validPaperSizes {
+		if v == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// PaperSizeValues returns the known PaperSize values.
+func PaperSizeValues() []string {
+	values := make([]string, len(validPaperSizes))
+	for i, v := range validPaperSizes {
+		values[i] = string(v)
+	}
+	return values
+}
+
+// Projection is the projection of an AWS account snapshot
+type Projection string
+
+const (
+	ProjectionIsometric Projection = "isometric"
+	Projection2D        Projection = "2d"
+)
+
+var validProjections = []Projection{ProjectionIsometric, Projection2D}
+
+// Valid reports whether v is a known Projection.
+func (v Projection) Valid() bool {
+	for _, valid := range validProjections {
+		if v == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectionValues returns the known Projection values.
+func ProjectionValues() []string {
+	values := make([]string, len(validProjections))
+	for i, v := range validProjections {
+		values[i] = string(v)
+	}
+	return values
+}
+
+// SnapshotFormat is the format of an AWS account snapshot
+type SnapshotFormat string
+
+const (
+	SnapshotFormatJson    SnapshotFormat = "json"
+	SnapshotFormatSvg     SnapshotFormat = "svg"
+	SnapshotFormatPng     SnapshotFormat = "png"
+	SnapshotFormatPdf     SnapshotFormat = "pdf"
+	SnapshotFormatMxGraph SnapshotFormat = "mxGraph"
+)
+
+var validSnapshotFormats = []SnapshotFormat{SnapshotFormatJson, SnapshotFormatSvg, SnapshotFormatPng, SnapshotFormatPdf, SnapshotFormatMxGraph}
+
+// Valid reports whether v is a known SnapshotFormat.
+func (v SnapshotFormat) Valid() bool {
+	for _, valid := range validSnapshotFormats {
+		if v == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// SnapshotFormatValues returns the known SnapshotFormat values.
+func SnapshotFormatValues() []string {
+	values := make([]string, len(validSnapshotFormats))
+	for i, v := range validSnapshotFormats {
+		values[i] = string(v)
+	}
+	return values
+}
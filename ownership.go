@@ -0,0 +1,29 @@
+package cloudcraft
+
+// OwnershipTransferRequest represents a request to reassign the owner of a
+// Blueprint or AwsAccount to another user or team. Exactly one of UserId and
+// TeamId must be set.
+type OwnershipTransferRequest struct {
+	UserId string `json:"userId,omitempty"`
+	TeamId string `json:"teamId,omitempty"`
+}
+
+func (d OwnershipTransferRequest) String() string {
+	return Stringify(d)
+}
+
+func (d *OwnershipTransferRequest) validate() error {
+	if d == nil {
+		return NewArgError("transferRequest", "cannot be nil")
+	}
+
+	if d.UserId == "" && d.TeamId == "" {
+		return NewArgError("transferRequest", "must set UserId or TeamId")
+	}
+
+	if d.UserId != "" && d.TeamId != "" {
+		return NewArgError("transferRequest", "cannot set both UserId and TeamId")
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package cloudcraft
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"unicode/utf16"
+)
+
+// PDFMetadata is the document information set on PDF exports, so archived
+// diagrams carry their provenance. Empty fields are left unset.
+type PDFMetadata struct {
+	Title   string
+	Author  string
+	Subject string
+}
+
+func (m PDFMetadata) String() string {
+	return Stringify(m)
+}
+
+var (
+	errInvalidPDF = errors.New("cloudcraft: invalid PDF")
+
+	pdfStartXref = regexp.MustCompile(`startxref\s+(\d+)\s+%%EOF\s*$`)
+	pdfSize      = regexp.MustCompile(`/Size\s+(\d+)`)
+	pdfRoot      = regexp.MustCompile(`/Root\s+(\d+\s+\d+\s+R)`)
+	pdfEncrypt   = regexp.MustCompile(`/Encrypt\b`)
+)
+
+// SetPDFMetadata returns pdf with its document information replaced by meta.
+// The information is appended as an incremental update, leaving the
+// original content untouched. Encrypted documents are not supported.
+func SetPDFMetadata(pdf []byte, meta *PDFMetadata) ([]byte, error) {
+	if meta == nil {
+		return nil, NewArgError("meta", "cannot be nil")
+	}
+
+	m := pdfStartXref.FindSubmatch(pdf)
+	if m == nil {
+		return nil, fmt.Errorf("%w: missing startxref", errInvalidPDF)
+	}
+	prev, err := strconv.Atoi(string(m[1]))
+	if err != nil || prev >= len(pdf) {
+		return nil, fmt.Errorf("%w: invalid startxref", errInvalidPDF)
+	}
+
+	// The trailer of a cross-reference table follows it, the one of a
+	// cross-reference stream is the dictionary of the stream.
+	section := pdf[prev:]
+	xrefStream := !bytes.HasPrefix(section, []byte("xref"))
+	var trailer []byte
+	if xrefStream {
+		end := bytes.Index(section, []byte("stream"))
+		if end < 0 {
+			return nil, fmt.Errorf("%w: invalid cross-reference stream", errInvalidPDF)
+		}
+		trailer = section[:end]
+	} else {
+		start := bytes.Index(section, []byte("trailer"))
+		if start < 0 {
+			return nil, fmt.Errorf("%w: missing trailer", errInvalidPDF)
+		}
+		trailer = section[start:]
+	}
+
+	if pdfEncrypt.Match(trailer) {
+		return nil, errors.New("cloudcraft: encrypted PDFs are not supported")
+	}
+	sizeMatch := pdfSize.FindSubmatch(trailer)
+	rootMatch := pdfRoot.FindSubmatch(trailer)
+	if sizeMatch == nil || rootMatch == nil {
+		return nil, fmt.Errorf("%w: incomplete trailer", errInvalidPDF)
+	}
+	size, err := strconv.Atoi(string(sizeMatch[1]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid trailer size", errInvalidPDF)
+	}
+	root := rootMatch[1]
+
+	out := bytes.NewBuffer(make([]byte, 0, len(pdf)+512))
+	out.Write(pdf)
+	if pdf[len(pdf)-1] != '\n' {
+		out.WriteByte('\n')
+	}
+
+	infoOffset := out.Len()
+	fmt.Fprintf(out, "%d 0 obj\n<<", size)
+	writePDFEntry(out, "Title", meta.Title)
+	writePDFEntry(out, "Author", meta.Author)
+	writePDFEntry(out, "Subject", meta.Subject)
+	out.WriteString(" >>\nendobj\n")
+
+	xrefOffset := out.Len()
+	if xrefStream {
+		// Entries of the info dictionary and of the stream itself, as
+		// type 1 entries with 4-byte offsets and 2-byte generations.
+		var rows bytes.Buffer
+		for _, offset := range []int{infoOffset, xrefOffset} {
+			rows.WriteByte(1)
+			binary.Write(&rows, binary.BigEndian, uint32(offset))
+			binary.Write(&rows, binary.BigEndian, uint16(0))
+		}
+
+		fmt.Fprintf(out, "%d 0 obj\n<< /Type /XRef /Size %d /W [1 4 2] /Index [%d 2] /Root %s /Info %d 0 R /Prev %d /Length %d >>\nstream\n",
+			size+1, size+2, size, root, size, prev, rows.Len())
+		out.Write(rows.Bytes())
+		out.WriteString("\nendstream\nendobj\n")
+	} else {
+		fmt.Fprintf(out, "xref\n%d 1\n%010d 00000 n \ntrailer\n<< /Size %d /Root %s /Info %d 0 R /Prev %d >>\n",
+			size, infoOffset, size+1, root, size, prev)
+	}
+	fmt.Fprintf(out, "startxref\n%d\n%%%%EOF\n", xrefOffset)
+
+	return out.Bytes(), nil
+}
+
+// writePDFEntry writes the dictionary entry of a text string, if not empty.
+func writePDFEntry(buf *bytes.Buffer, key, value string) {
+	if value == "" {
+		return
+	}
+
+	fmt.Fprintf(buf, " /%s ", key)
+
+	ascii := true
+	for i := 0; i < len(value); i++ {
+		if value[i] < 0x20 || value[i] > 0x7e {
+			ascii = false
+			break
+		}
+	}
+
+	if !ascii {
+		// Text strings outside of ASCII are written in UTF-16BE with a
+		// byte order mark.
+		buf.WriteString("<FEFF")
+		for _, u := range utf16.Encode([]rune(value)) {
+			fmt.Fprintf(buf, "%04X", u)
+		}
+		buf.WriteByte('>')
+		return
+	}
+
+	buf.WriteByte('(')
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '\\', '(', ')':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte(')')
+}
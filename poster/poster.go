@@ -0,0 +1,127 @@
+// Package poster composes several snapshots, such as the PNG snapshots of
+// the regions or AWS accounts of an estate, into a single tiled image with
+// a caption under each tile, ready to be printed as a poster.
+package poster
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+)
+
+const (
+	defaultPadding      = 32
+	defaultCaptionScale = 4
+)
+
+// Tile is an image placed on the poster with its caption.
+type Tile struct {
+	Image   image.Image
+	Caption string
+}
+
+// ReadTile decodes the PNG image read from r, e.g. the Content of an
+// AwsAccountSnapshot, into a Tile.
+func ReadTile(r io.Reader, caption string) (Tile, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return Tile{}, fmt.Errorf("poster: decoding %q: %w", caption, err)
+	}
+	return Tile{Image: img, Caption: caption}, nil
+}
+
+// Options specifies the optional parameters of Compose.
+type Options struct {
+	// Number of tiles per row, the smallest square grid holding them if
+	// zero.
+	Columns int
+
+	// Space in pixels around the tiles, 32 if zero.
+	Padding int
+
+	// Size factor of the caption font, each character being 5x7 pixels at
+	// scale 1. 4 if zero.
+	CaptionScale int
+
+	// Colors of the background and the captions, white and black if nil.
+	Background  color.Color
+	CaptionText color.Color
+}
+
+// Compose lays tiles out on a grid, in order from left to right and top to
+// bottom. Every cell is as large as the largest tile, smaller tiles being
+// centered in their cell, and has its caption centered under it.
+func Compose(tiles []Tile, opt *Options) (*image.RGBA, error) {
+	if len(tiles) == 0 {
+		return nil, errors.New("poster: no tiles")
+	}
+	if opt == nil {
+		opt = &Options{}
+	}
+
+	columns := opt.Columns
+	if columns <= 0 {
+		columns = int(math.Ceil(math.Sqrt(float64(len(tiles)))))
+	}
+	rows := (len(tiles) + columns - 1) / columns
+
+	padding := opt.Padding
+	if padding <= 0 {
+		padding = defaultPadding
+	}
+	scale := opt.CaptionScale
+	if scale <= 0 {
+		scale = defaultCaptionScale
+	}
+	background := opt.Background
+	if background == nil {
+		background = color.White
+	}
+	text := opt.CaptionText
+	if text == nil {
+		text = color.Black
+	}
+
+	var cellWidth, imageHeight int
+	for i, tile := range tiles {
+		if tile.Image == nil {
+			return nil, fmt.Errorf("poster: tile %d has no image", i)
+		}
+
+		b := tile.Image.Bounds()
+		if b.Dx() > cellWidth {
+			cellWidth = b.Dx()
+		}
+		if b.Dy() > imageHeight {
+			imageHeight = b.Dy()
+		}
+		if w := textWidth(tile.Caption, scale); w > cellWidth {
+			cellWidth = w
+		}
+	}
+	cellHeight := imageHeight + padding/2 + textHeight(scale)
+
+	poster := image.NewRGBA(image.Rect(0, 0,
+		columns*cellWidth+(columns+1)*padding,
+		rows*cellHeight+(rows+1)*padding))
+	draw.Draw(poster, poster.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	for i, tile := range tiles {
+		x := padding + (i%columns)*(cellWidth+padding)
+		y := padding + (i/columns)*(cellHeight+padding)
+
+		b := tile.Image.Bounds()
+		at := image.Pt(x+(cellWidth-b.Dx())/2, y+(imageHeight-b.Dy())/2)
+		draw.Draw(poster, image.Rectangle{Min: at, Max: at.Add(b.Size())}, tile.Image, b.Min, draw.Over)
+
+		captionAt := image.Pt(x+(cellWidth-textWidth(tile.Caption, scale))/2, y+imageHeight+padding/2)
+		drawText(poster, captionAt, tile.Caption, text, scale)
+	}
+
+	return poster, nil
+}
@@ -0,0 +1,190 @@
+package cloudcraft
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Names of the built-in presets.
+const (
+	// PresetPrintA3Landscape is a PDF on landscape A3 paper, with labels.
+	PresetPrintA3Landscape = "PrintA3Landscape"
+
+	// PresetWebThumbnail is a small PNG with a transparent background, for
+	// lists of diagrams on web pages.
+	PresetWebThumbnail = "WebThumbnail"
+
+	// PresetHighResPoster is a large PNG with labels, for large format
+	// printing.
+	PresetHighResPoster = "HighResPoster"
+)
+
+// Preset is a named set of output settings shared by the exports of
+// Blueprints and the snapshots of AWS accounts, so that scripts render
+// diagrams consistently. The zero value of a setting leaves it to the API.
+type Preset struct {
+	Name string
+
+	// Format of the export or snapshot, see ExportFormat and SnapshotFormat.
+	Format string
+
+	Width       int
+	Height      int
+	Scale       float32
+	PaperSize   PaperSize
+	Landscape   bool
+	Grid        bool
+	Label       bool
+	Transparent bool
+	Projection  Projection
+
+	// Theme and Background only apply to the exports of Blueprints.
+	Theme      ExportTheme
+	Background string
+}
+
+func (p Preset) String() string {
+	return Stringify(p)
+}
+
+// ExportRequest returns a request exporting a Blueprint with the settings of
+// the preset.
+func (p Preset) ExportRequest() *BlueprintExportRequest {
+	return &BlueprintExportRequest{Format: p.Format, ExportParameters: p.ExportParameters()}
+}
+
+// ExportParameters returns the export parameters of the preset.
+func (p Preset) ExportParameters() *BlueprintExportParameters {
+	return &BlueprintExportParameters{
+		Grid:        p.Grid,
+		Height:      p.Height,
+		Landscape:   p.Landscape,
+		PaperSize:   string(p.PaperSize),
+		Scale:       p.Scale,
+		Transparent: p.Transparent,
+		Width:       p.Width,
+		Theme:       p.Theme,
+		Background:  p.Background,
+		Projection:  p.Projection,
+		Label:       p.Label,
+	}
+}
+
+// SnapshotRequest returns a request snapshotting region with the settings of
+// the preset.
+func (p Preset) SnapshotRequest(region string) *AwsAccountSnapshotRequest {
+	return &AwsAccountSnapshotRequest{Region: region, Format: p.Format, SnapshotParameters: p.SnapshotParameters()}
+}
+
+// SnapshotParameters returns the snapshot parameters of the preset.
+func (p Preset) SnapshotParameters() *AwsAccountSnapshotParameters {
+	return &AwsAccountSnapshotParameters{
+		Grid:        p.Grid,
+		Height:      p.Height,
+		Label:       p.Label,
+		Landscape:   p.Landscape,
+		PaperSize:   string(p.PaperSize),
+		Projection:  string(p.Projection),
+		Scale:       p.Scale,
+		Transparent: p.Transparent,
+		Width:       p.Width,
+	}
+}
+
+// validate checks the preset with the checks of exports and snapshots.
+func (p *Preset) validate() error {
+	if p.Name == "" {
+		return NewArgError("preset.Name", "cannot be empty")
+	}
+	if !SnapshotFormat(p.Format).Valid() {
+		return NewArgError("preset.Format", oneOf(SnapshotFormatValues()))
+	}
+
+	const prefix = "preset."
+	if err := validateImageSize(prefix, p.Width, p.Height, p.Scale); err != nil {
+		return err
+	}
+	if err := validatePaperSize(prefix, p.Format, string(p.PaperSize)); err != nil {
+		return err
+	}
+
+	if p.Theme != "" && !p.Theme.Valid() {
+		return NewArgError(prefix+"Theme", oneOf(ExportThemeValues()))
+	}
+	if p.Projection != "" && !p.Projection.Valid() {
+		return NewArgError(prefix+"Projection", oneOf(ProjectionValues()))
+	}
+	if p.Background != "" && !isHexColor(p.Background) {
+		return NewArgError(prefix+"Background", "must be a hex color such as #ffffff")
+	}
+	return nil
+}
+
+var presets = struct {
+	sync.RWMutex
+	m map[string]Preset
+}{m: map[string]Preset{
+	PresetPrintA3Landscape: {
+		Name:      PresetPrintA3Landscape,
+		Format:    string(ExportFormatPdf),
+		PaperSize: PaperSizeA3,
+		Landscape: true,
+		Label:     true,
+	},
+	PresetWebThumbnail: {
+		Name:        PresetWebThumbnail,
+		Format:      string(ExportFormatPng),
+		Width:       480,
+		Height:      270,
+		Transparent: true,
+	},
+	PresetHighResPoster: {
+		Name:   PresetHighResPoster,
+		Format: string(ExportFormatPng),
+		Width:  7680,
+		Height: 4320,
+		Label:  true,
+	},
+}}
+
+// RegisterPreset registers p under its name, for LookupPreset. It fails if
+// p is invalid or its name is already registered. It is safe for concurrent
+// use, though presets are usually registered at initialization.
+func RegisterPreset(p Preset) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+
+	presets.Lock()
+	defer presets.Unlock()
+
+	if _, ok := presets.m[p.Name]; ok {
+		return NewArgError("preset.Name", fmt.Sprintf("%s is already registered", p.Name))
+	}
+	presets.m[p.Name] = p
+	return nil
+}
+
+// LookupPreset returns the preset registered under name, built-in or
+// registered with RegisterPreset, and whether there is one.
+func LookupPreset(name string) (Preset, bool) {
+	presets.RLock()
+	defer presets.RUnlock()
+
+	p, ok := presets.m[name]
+	return p, ok
+}
+
+// PresetNames returns the names of the registered presets, sorted.
+func PresetNames() []string {
+	presets.RLock()
+	defer presets.RUnlock()
+
+	names := make([]string, 0, len(presets.m))
+	for name := range presets.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
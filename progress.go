@@ -0,0 +1,47 @@
+package cloudcraft
+
+import (
+	"io"
+	"net/http"
+)
+
+// responseWriter is an io.Writer Do informs of the response before writing
+// its body.
+type responseWriter interface {
+	io.Writer
+	begin(resp *http.Response)
+}
+
+// ProgressFunc reports the progress of a download with the number of bytes
+// received so far and the total size of the content, -1 when unknown.
+type ProgressFunc func(received, total int64)
+
+// progressWriter is an io.Writer reporting the bytes written to w to fn. Do
+// sets total from the Content-Length of the response.
+type progressWriter struct {
+	w        io.Writer
+	fn       ProgressFunc
+	received int64
+	total    int64
+}
+
+// withProgress returns w reporting its progress to fn, or w itself if fn is
+// nil.
+func withProgress(w io.Writer, fn ProgressFunc) io.Writer {
+	if fn == nil {
+		return w
+	}
+	return &progressWriter{w: w, fn: fn, total: -1}
+}
+
+func (p *progressWriter) begin(resp *http.Response) {
+	p.total = resp.ContentLength
+	p.fn(p.received, p.total)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.received += int64(n)
+	p.fn(p.received, p.total)
+	return n, err
+}
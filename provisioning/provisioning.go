@@ -0,0 +1,135 @@
+// Package provisioning implements a client for the user-management endpoints
+// of the Cloudcraft API available on enterprise plans. It allows creating,
+// updating and deactivating users so Cloudcraft accounts can follow the
+// lifecycle of an identity provider.
+package provisioning
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/updater/cloudcraft-go"
+)
+
+const provisioningBasePath = "provisioning/user"
+
+// Service is an interface for interfacing with the user provisioning
+// endpoints of the Cloudcraft API
+type Service interface {
+	Create(context.Context, *UserRequest, ...cloudcraft.RequestOpt) (*User, *cloudcraft.Response, error)
+	Update(context.Context, string, *UserRequest, ...cloudcraft.RequestOpt) (*User, *cloudcraft.Response, error)
+	Deactivate(context.Context, string, ...cloudcraft.RequestOpt) (*User, *cloudcraft.Response, error)
+}
+
+// ServiceOp handles communication with the user provisioning related methods
+// of the Cloudcraft API.
+type ServiceOp struct {
+	client *cloudcraft.Client
+}
+
+var _ Service = &ServiceOp{}
+
+// New returns a provisioning Service sending its requests through client.
+func New(client *cloudcraft.Client) *ServiceOp {
+	return &ServiceOp{client: client}
+}
+
+// User represents a provisioned Cloudcraft user
+type User struct {
+	Id         string               `json:"id,omitempty"`
+	ExternalId string               `json:"externalId,omitempty"`
+	Email      string               `json:"email,omitempty"`
+	Name       string               `json:"name,omitempty"`
+	Role       cloudcraft.Role      `json:"role,omitempty"`
+	TeamIds    []string             `json:"teamIds,omitempty"`
+	Active     bool                 `json:"active"`
+	CreatedAt  cloudcraft.Timestamp `json:"createdAt,omitempty"`
+	UpdatedAt  cloudcraft.Timestamp `json:"updatedAt,omitempty"`
+}
+
+// Convert User to a string
+func (d User) String() string {
+	return cloudcraft.Stringify(d)
+}
+
+// UserRequest represents a request to create or update a provisioned user.
+// ExternalId is the identifier of the user in the identity provider.
+type UserRequest struct {
+	ExternalId string          `json:"externalId,omitempty"`
+	Email      string          `json:"email"`
+	Name       string          `json:"name,omitempty"`
+	Role       cloudcraft.Role `json:"role,omitempty"`
+	TeamIds    []string        `json:"teamIds,omitempty"`
+	Active     *bool           `json:"active,omitempty"`
+}
+
+func (d UserRequest) String() string {
+	return cloudcraft.Stringify(d)
+}
+
+// Create provisions a new User.
+func (s *ServiceOp) Create(ctx context.Context, createRequest *UserRequest, opts ...cloudcraft.RequestOpt) (*User, *cloudcraft.Response, error) {
+	if createRequest == nil {
+		return nil, nil, cloudcraft.NewArgError("createRequest", "cannot be nil")
+	}
+
+	if createRequest.Email == "" {
+		return nil, nil, cloudcraft.NewArgError("createRequest.Email", "cannot be empty")
+	}
+
+	if err := cloudcraft.ValidateRole("createRequest.Role", createRequest.Role); err != nil {
+		return nil, nil, err
+	}
+
+	return s.do(ctx, http.MethodPost, provisioningBasePath, createRequest, opts)
+}
+
+// Update a provisioned User.
+func (s *ServiceOp) Update(ctx context.Context, userID string, updateRequest *UserRequest, opts ...cloudcraft.RequestOpt) (*User, *cloudcraft.Response, error) {
+	if userID == "" {
+		return nil, nil, cloudcraft.NewArgError("userID", "cannot be empty")
+	}
+
+	if updateRequest == nil {
+		return nil, nil, cloudcraft.NewArgError("updateRequest", "cannot be nil")
+	}
+
+	if err := cloudcraft.ValidateRole("updateRequest.Role", updateRequest.Role); err != nil {
+		return nil, nil, err
+	}
+
+	path := fmt.Sprintf("%s/%s", provisioningBasePath, userID)
+
+	return s.do(ctx, http.MethodPut, path, updateRequest, opts)
+}
+
+// Deactivate a provisioned User, revoking their access without deleting the
+// blueprints they own.
+func (s *ServiceOp) Deactivate(ctx context.Context, userID string, opts ...cloudcraft.RequestOpt) (*User, *cloudcraft.Response, error) {
+	if userID == "" {
+		return nil, nil, cloudcraft.NewArgError("userID", "cannot be empty")
+	}
+
+	path := fmt.Sprintf("%s/%s/deactivate", provisioningBasePath, userID)
+
+	return s.do(ctx, http.MethodPost, path, nil, opts)
+}
+
+func (s *ServiceOp) do(ctx context.Context, method, path string, body interface{}, opts []cloudcraft.RequestOpt) (*User, *cloudcraft.Response, error) {
+	ctx, cancel := cloudcraft.ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	req, err := s.client.NewRequest(ctx, method, path, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user := new(User)
+	resp, err := s.client.Do(ctx, req, user)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return user, resp, err
+}
@@ -0,0 +1,177 @@
+package cloudcraft
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// queryEncoder is implemented by the parameters sent in the query of a
+// request. Encode must handle a nil receiver by returning no values.
+type queryEncoder interface {
+	Encode() url.Values
+}
+
+// addQuery adds the parameters of opt to the query of the URL s.
+func addQuery(s string, opt queryEncoder) (string, error) {
+	values := opt.Encode()
+	if len(values) == 0 {
+		return s, nil
+	}
+
+	origURL, err := url.Parse(s)
+	if err != nil {
+		return s, fmt.Errorf("cloudcraft: parsing URL %q: %w", s, err)
+	}
+
+	origValues := origURL.Query()
+	for k, v := range values {
+		origValues[k] = v
+	}
+
+	origURL.RawQuery = origValues.Encode()
+	return origURL.String(), nil
+}
+
+// queryWriter writes typed values to url.Values, omitting empty values
+// unless told otherwise.
+type queryWriter url.Values
+
+func (w queryWriter) bool(key string, b, omitEmpty bool) {
+	if b || !omitEmpty {
+		w[key] = []string{strconv.FormatBool(b)}
+	}
+}
+
+func (w queryWriter) int(key string, i int, omitEmpty bool) {
+	if i != 0 || !omitEmpty {
+		w[key] = []string{strconv.Itoa(i)}
+	}
+}
+
+func (w queryWriter) float32(key string, f float32, omitEmpty bool) {
+	if f != 0 || !omitEmpty {
+		w[key] = []string{strconv.FormatFloat(float64(f), 'g', -1, 32)}
+	}
+}
+
+func (w queryWriter) string(key, s string, omitEmpty bool) {
+	if s != "" || !omitEmpty {
+		w[key] = []string{s}
+	}
+}
+
+func (w queryWriter) strings(key string, s []string) {
+	if len(s) > 0 {
+		w[key] = []string{strings.Join(s, ",")}
+	}
+}
+
+func (w queryWriter) time(key string, t time.Time) {
+	if !t.IsZero() {
+		w[key] = []string{t.Format(time.RFC3339)}
+	}
+}
+
+// Encode returns the query parameters of o.
+func (o *ListOptions) Encode() url.Values {
+	if o == nil {
+		return nil
+	}
+
+	v := url.Values{}
+	w := queryWriter(v)
+	w.int("page", o.Page, true)
+	w.int("perPage", o.PerPage, true)
+	return v
+}
+
+// Encode returns the query parameters of o.
+func (o *AuditListOptions) Encode() url.Values {
+	if o == nil {
+		return nil
+	}
+
+	v := o.ListOptions.Encode()
+	w := queryWriter(v)
+	w.time("since", o.Since)
+	w.time("until", o.Until)
+	w.string("action", o.Action, true)
+	w.string("actorId", o.ActorId, true)
+	return v
+}
+
+// Encode returns the query parameters of o.
+func (o *UserListOptions) Encode() url.Values {
+	if o == nil {
+		return nil
+	}
+
+	v := o.ListOptions.Encode()
+	w := queryWriter(v)
+	w.string("role", string(o.Role), true)
+	w.string("teamId", o.TeamId, true)
+	w.time("activeSince", o.ActiveSince)
+	return v
+}
+
+// Encode returns the query parameters of p.
+func (p *BlueprintExportParameters) Encode() url.Values {
+	if p == nil {
+		return nil
+	}
+
+	v := url.Values{}
+	w := queryWriter(v)
+	w.bool("grid", p.Grid, false)
+	w.int("height", p.Height, false)
+	w.bool("landscape", p.Landscape, false)
+	w.string("paperSize", p.PaperSize, false)
+	w.float32("scale", p.Scale, false)
+	w.bool("transparent", p.Transparent, false)
+	w.int("width", p.Width, false)
+	w.string("theme", string(p.Theme), true)
+	w.string("background", p.Background, true)
+	w.string("projection", string(p.Projection), true)
+	w.bool("label", p.Label, true)
+	return v
+}
+
+// Encode returns the query parameters of p.
+func (p *BlueprintBudgetParameters) Encode() url.Values {
+	if p == nil {
+		return nil
+	}
+
+	v := url.Values{}
+	w := queryWriter(v)
+	w.string("currency", p.Currency, true)
+	w.string("period", p.Period, true)
+	w.string("rate", p.Rate, true)
+	return v
+}
+
+// Encode returns the query parameters of p.
+func (p *AwsAccountSnapshotParameters) Encode() url.Values {
+	if p == nil {
+		return nil
+	}
+
+	v := url.Values{}
+	w := queryWriter(v)
+	w.bool("autoconnect", p.Autoconnect, true)
+	w.strings("exclude", p.Exclude)
+	w.string("filter", p.Filter, true)
+	w.bool("grid", p.Grid, true)
+	w.int("height", p.Height, true)
+	w.bool("label", p.Label, true)
+	w.bool("landscape", p.Landscape, true)
+	w.string("paperSize", p.PaperSize, true)
+	w.string("projection", p.Projection, true)
+	w.float32("scale", p.Scale, true)
+	w.bool("transparent", p.Transparent, true)
+	w.int("width", p.Width, true)
+	return v
+}
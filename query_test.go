@@ -0,0 +1,75 @@
+package cloudcraft
+
+import "testing"
+
+// The expected queries are those go-querystring encoded the parameters to.
+func TestBlueprintExportParametersEncode(t *testing.T) {
+	tests := []struct {
+		name   string
+		params *BlueprintExportParameters
+		want   string
+	}{
+		{"nil", nil, ""},
+		{"zero", &BlueprintExportParameters{}, "grid=false&height=0&landscape=false&paperSize=&scale=0&transparent=false&width=0"},
+		{
+			"all",
+			&BlueprintExportParameters{
+				Grid: true, Height: 768, Landscape: true, PaperSize: "A4", Scale: 1.5, Transparent: true, Width: 1024,
+				Theme: ExportThemeDark, Background: "#ffffff", Projection: Projection2D, Label: true,
+			},
+			"background=%23ffffff&grid=true&height=768&label=true&landscape=true&paperSize=A4&projection=2d&scale=1.5&theme=dark&transparent=true&width=1024",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.params.Encode().Encode(); got != tt.want {
+				t.Errorf("Encode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAwsAccountSnapshotParametersEncode(t *testing.T) {
+	tests := []struct {
+		name   string
+		params *AwsAccountSnapshotParameters
+		want   string
+	}{
+		{"nil", nil, ""},
+		{"zero", &AwsAccountSnapshotParameters{}, ""},
+		{
+			"all",
+			&AwsAccountSnapshotParameters{
+				Autoconnect: true, Exclude: []string{"ebs", "vpc"}, Filter: "env=prod", Grid: true, Height: 768,
+				Label: true, Landscape: true, PaperSize: "Letter", Projection: "isometric", Scale: 0.25,
+				Transparent: true, Width: 1024,
+			},
+			"autoconnect=true&exclude=ebs%2Cvpc&filter=env%3Dprod&grid=true&height=768&label=true&landscape=true&paperSize=Letter&projection=isometric&scale=0.25&transparent=true&width=1024",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.params.Encode().Encode(); got != tt.want {
+				t.Errorf("Encode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkBlueprintExportParametersEncode(b *testing.B) {
+	params := &BlueprintExportParameters{Grid: true, Height: 768, PaperSize: "A4", Scale: 1.5, Width: 1024, Theme: ExportThemeDark}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		params.Encode()
+	}
+}
+
+func BenchmarkAwsAccountSnapshotParametersEncode(b *testing.B) {
+	params := &AwsAccountSnapshotParameters{Autoconnect: true, Exclude: []string{"ebs", "vpc"}, Filter: "env=prod", Scale: 0.25, Width: 1024}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		params.Encode()
+	}
+}
@@ -0,0 +1,145 @@
+package cloudcraft
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RequestOpt is an option of the requests sent with a context returned by
+// WithRequestOpts, overriding the options of the client.
+type RequestOpt func(*requestOptions)
+
+// requestOptions are the options of a single request.
+type requestOptions struct {
+	returnAccepted bool
+	requestID      string
+	accept         string
+	contentType    string
+	headers        http.Header
+	retryPolicy    RetryPolicy
+	timeout        time.Duration
+	actor          string
+	namingScopes   []string
+}
+
+type requestOptsKey struct{}
+
+// WithRequestOpts returns a copy of ctx carrying opts, in addition to the
+// options already carried by ctx. The requests made with the returned
+// context are sent with those options.
+func WithRequestOpts(ctx context.Context, opts ...RequestOpt) context.Context {
+	prev, _ := ctx.Value(requestOptsKey{}).([]RequestOpt)
+
+	all := make([]RequestOpt, 0, len(prev)+len(opts))
+	all = append(all, prev...)
+	all = append(all, opts...)
+	return context.WithValue(ctx, requestOptsKey{}, all)
+}
+
+// ApplyRequestOpts returns a copy of ctx carrying opts, like WithRequestOpts,
+// bounded by the Timeout among opts if any. It is called by the service
+// methods taking per-call options, and by services built on a Client outside
+// of this package. The cancel function must be called once the call returns.
+func ApplyRequestOpts(ctx context.Context, opts ...RequestOpt) (context.Context, context.CancelFunc) {
+	if len(opts) == 0 {
+		return ctx, func() {}
+	}
+
+	var o requestOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx = WithRequestOpts(ctx, opts...)
+	if o.timeout > 0 {
+		return context.WithTimeout(ctx, o.timeout)
+	}
+	return ctx, func() {}
+}
+
+// requestOptionsOf returns the options of a request sent by c with ctx.
+func (c *Client) requestOptionsOf(ctx context.Context) *requestOptions {
+	o := &requestOptions{
+		returnAccepted: c.returnAccepted,
+	}
+
+	opts, _ := ctx.Value(requestOptsKey{}).([]RequestOpt)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// ReturnAccepted is a request option returning 202 Accepted responses to
+// the caller instead of sending the request again until it completes. The
+// caller must check the status of the response, the body of a 202 being
+// decoded into the result like any other, if not empty.
+func ReturnAccepted() RequestOpt {
+	return func(o *requestOptions) {
+		o.returnAccepted = true
+	}
+}
+
+// SetReturnAccepted is a client option applying ReturnAccepted to all the
+// requests of the client.
+func SetReturnAccepted() ClientOpt {
+	return func(c *Client) error {
+		c.returnAccepted = true
+		return nil
+	}
+}
+
+// RequestID is a request option sending id as the X-Request-ID header of
+// the request, instead of a random UUID. The id ends up in the errors of the
+// request, correlating them with logs of the caller and of Cloudcraft.
+func RequestID(id string) RequestOpt {
+	return func(o *requestOptions) {
+		o.requestID = id
+	}
+}
+
+// Accept is a request option replacing the Accept header of the request,
+// e.g. with application/xml for mxGraph exports.
+func Accept(mediaType string) RequestOpt {
+	return func(o *requestOptions) {
+		o.accept = mediaType
+	}
+}
+
+// ContentType is a request option replacing the Content-Type header of a
+// request with a body, e.g. for uploads given to NewRequest as an io.Reader.
+func ContentType(mediaType string) RequestOpt {
+	return func(o *requestOptions) {
+		o.contentType = mediaType
+	}
+}
+
+// Timeout is a request option bounding a call, including its retries and
+// the wait for a 202 Accepted to complete, to d. It only applies to the
+// service methods given the option, not to contexts of WithRequestOpts,
+// which are bounded with context.WithTimeout instead.
+func Timeout(d time.Duration) RequestOpt {
+	return func(o *requestOptions) {
+		o.timeout = d
+	}
+}
+
+// Header is a request option adding the header key with value to the
+// request, replacing the values set by the client for that key.
+func Header(key, value string) RequestOpt {
+	return func(o *requestOptions) {
+		if o.headers == nil {
+			o.headers = make(http.Header)
+		}
+		o.headers.Add(key, value)
+	}
+}
+
+// Retry is a request option retrying the request according to policy
+// instead of the retry policy of the client, e.g. NoRetry.
+func Retry(policy RetryPolicy) RequestOpt {
+	return func(o *requestOptions) {
+		o.retryPolicy = policy
+	}
+}
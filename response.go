@@ -0,0 +1,103 @@
+package cloudcraft
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Rate is the rate limit of the API as reported by the RateLimit headers of
+// a response.
+type Rate struct {
+	// Number of requests allowed in the current window.
+	Limit int
+
+	// Number of requests left in the current window.
+	Remaining int
+
+	// Time the current window ends, zero if unknown.
+	Reset time.Time
+}
+
+func (r Rate) String() string {
+	return Stringify(r)
+}
+
+// responseHeaders are the headers of a response parsed by newResponse.
+type responseHeaders struct {
+	requestID     string
+	rate          Rate
+	hasRate       bool
+	retryAfter    time.Duration
+	hasRetryAfter bool
+}
+
+// parseResponseHeaders parses the headers of r described by Response.
+func parseResponseHeaders(r *http.Response) responseHeaders {
+	var h responseHeaders
+
+	h.requestID = r.Header.Get(headerRequestID)
+	if h.requestID == "" && r.Request != nil {
+		h.requestID = r.Request.Header.Get(headerRequestID)
+	}
+
+	if limit, err := strconv.Atoi(r.Header.Get(headerRateLimit)); err == nil {
+		h.hasRate = true
+		h.rate.Limit = limit
+		h.rate.Remaining, _ = strconv.Atoi(r.Header.Get(headerRateRemaining))
+		if reset, err := strconv.ParseInt(r.Header.Get(headerRateReset), 10, 64); err == nil {
+			// The reset is either a number of seconds or a Unix time.
+			if reset > 1e9 {
+				h.rate.Reset = time.Unix(reset, 0)
+			} else {
+				h.rate.Reset = time.Now().Add(time.Duration(reset) * time.Second)
+			}
+		}
+	}
+
+	h.retryAfter, h.hasRetryAfter = parseRetryAfter(r.Header.Get("Retry-After"))
+
+	return h
+}
+
+// parseRetryAfter parses a Retry-After header holding either a number of
+// seconds or an HTTP date.
+func parseRetryAfter(s string) (time.Duration, bool) {
+	if s == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(s); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if t, err := http.ParseTime(s); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// RequestID returns the id of the request, as echoed by the API or as sent
+// in the X-Request-ID header.
+func (r *Response) RequestID() string {
+	return r.headers.requestID
+}
+
+// RateLimit returns the rate limit reported by the response, and whether it
+// reported one.
+func (r *Response) RateLimit() (Rate, bool) {
+	return r.headers.rate, r.headers.hasRate
+}
+
+// RetryAfter returns the delay the API asks to wait before sending another
+// request, and whether it asked for one.
+func (r *Response) RetryAfter() (time.Duration, bool) {
+	return r.headers.retryAfter, r.headers.hasRetryAfter
+}
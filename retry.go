@@ -0,0 +1,434 @@
+package cloudcraft
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRetryWaitMin = 1 * time.Second
+	defaultRetryWaitMax = 30 * time.Second
+)
+
+// RetryBudget limits the retries of all the requests sharing it, in the
+// manner of gRPC retry throttling. Every failed attempt costs one token and
+// every successful one earns tokenRatio tokens back. Retries are only made
+// while more than half of the tokens are left, so a Client shared by many
+// goroutines stops amplifying the load once the API is consistently failing.
+type RetryBudget struct {
+	mu         sync.Mutex
+	maxTokens  float64
+	tokenRatio float64
+	tokens     float64
+}
+
+// NewRetryBudget returns a RetryBudget holding maxTokens tokens, earning
+// tokenRatio tokens back on each success.
+func NewRetryBudget(maxTokens, tokenRatio float64) *RetryBudget {
+	return &RetryBudget{maxTokens: maxTokens, tokenRatio: tokenRatio, tokens: maxTokens}
+}
+
+func (b *RetryBudget) onSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens += b.tokenRatio
+	if b.tokens > b.maxTokens {
+		b.tokens = b.maxTokens
+	}
+}
+
+func (b *RetryBudget) onFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens--
+	if b.tokens < 0 {
+		b.tokens = 0
+	}
+}
+
+// allowRetry reports whether the budget permits another retry.
+func (b *RetryBudget) allowRetry() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.tokens > b.maxTokens/2
+}
+
+// RetryPolicy decides whether a failed attempt of a request is retried.
+//
+// ShouldRetry is called after every attempt that did not succeed, with
+// either its response or its error, and the zero-based index of that
+// attempt. It returns the delay before the next attempt and whether
+// to make it. Requests whose body cannot be sent again are never retried,
+// and neither are requests whose context is done.
+type RetryPolicy interface {
+	ShouldRetry(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool)
+}
+
+// RetryPolicyFunc is a function implementing RetryPolicy.
+type RetryPolicyFunc func(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool)
+
+// ShouldRetry calls f(req, resp, err, attempt).
+func (f RetryPolicyFunc) ShouldRetry(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	return f(req, resp, err, attempt)
+}
+
+// NoRetry is a RetryPolicy never retrying requests.
+var NoRetry RetryPolicy = RetryPolicyFunc(func(*http.Request, *http.Response, error, int) (time.Duration, bool) {
+	return 0, false
+})
+
+// BackoffPolicy is the RetryPolicy used by default. It retries idempotent
+// requests failing with a network error, 429 or 5xx status up to MaxRetries
+// times, waiting exponentially longer between WaitMin and WaitMax, or as
+// long as a Retry-After header asks within WaitMax.
+//
+// MaxElapsedTime, if set, bounds the time spent retrying a request: no retry
+// is made whose delay would end past MaxElapsedTime from the first attempt.
+// Jitter randomizes the exponential delays so clients failing together do
+// not retry together.
+type BackoffPolicy struct {
+	MaxRetries     int
+	WaitMin        time.Duration
+	WaitMax        time.Duration
+	MaxElapsedTime time.Duration
+	Jitter         Jitter
+}
+
+// Jitter is a strategy randomizing the delays between retries.
+type Jitter int
+
+const (
+	// NoJitter waits the exponential delay itself.
+	NoJitter Jitter = iota
+
+	// FullJitter waits a random delay between zero and the exponential
+	// delay.
+	FullJitter
+
+	// EqualJitter waits half the exponential delay plus a random delay up
+	// to the other half.
+	EqualJitter
+)
+
+// jitterRand is the source of jitter, seeded per process so processes
+// started together do not share their delays.
+var jitterRand = struct {
+	sync.Mutex
+	*rand.Rand
+}{Rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+// apply returns the delay d randomized by j.
+func (j Jitter) apply(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+
+	jitterRand.Lock()
+	defer jitterRand.Unlock()
+
+	switch j {
+	case FullJitter:
+		return time.Duration(jitterRand.Int63n(int64(d) + 1))
+	case EqualJitter:
+		return d/2 + time.Duration(jitterRand.Int63n(int64(d-d/2)+1))
+	}
+	return d
+}
+
+type retryStartKey struct{}
+
+// RetryElapsed returns the time elapsed since the first attempt of req, for
+// the RetryPolicy deciding on its retries.
+func RetryElapsed(req *http.Request) time.Duration {
+	start, ok := req.Context().Value(retryStartKey{}).(time.Time)
+	if !ok {
+		return 0
+	}
+	return time.Since(start)
+}
+
+var _ RetryPolicy = &BackoffPolicy{}
+
+// ShouldRetry implements RetryPolicy.
+func (p *BackoffPolicy) ShouldRetry(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= p.MaxRetries || !isIdempotent(req) || !isRetryableFailure(resp, err) {
+		return 0, false
+	}
+
+	wait := p.wait(attempt, resp)
+	if p.MaxElapsedTime > 0 && RetryElapsed(req)+wait > p.MaxElapsedTime {
+		return 0, false
+	}
+	return wait, true
+}
+
+// wait returns the delay before the retry following attempt, honoring the
+// Retry-After header of throttled responses.
+func (p *BackoffPolicy) wait(attempt int, resp *http.Response) time.Duration {
+	min, max := p.WaitMin, p.WaitMax
+	if min <= 0 {
+		min = defaultRetryWaitMin
+	}
+	if max <= 0 {
+		max = defaultRetryWaitMax
+	}
+
+	if resp != nil {
+		if wait := retryAfter(resp, -1); wait >= 0 {
+			if wait < max {
+				return wait
+			}
+			return max
+		}
+	}
+
+	wait := min << uint(attempt)
+	if wait <= 0 || wait > max {
+		wait = max
+	}
+	return p.Jitter.apply(wait)
+}
+
+// acceptedWait returns the delay before polling again the request answered
+// with the 202 Accepted resp, after attempt polls: the Retry-After of resp
+// within the maximum wait of the backoff, or else the backoff itself.
+func (c *Client) acceptedWait(attempt int, resp *http.Response) time.Duration {
+	backoff := c.backoffPolicy()
+	if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		max := backoff.WaitMax
+		if max <= 0 {
+			max = defaultRetryWaitMax
+		}
+		if wait > max {
+			return max
+		}
+		return wait
+	}
+	return backoff.wait(attempt, nil)
+}
+
+// sleep waits for d, or until ctx is done, returning its error.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// SetRetryMax is a client option for retrying idempotent requests up to n
+// times when they fail with a network error, 429 or 5xx status. It has no
+// effect on a policy set with SetRetryPolicy.
+func SetRetryMax(n int) ClientOpt {
+	return func(c *Client) error {
+		if n < 0 {
+			return NewArgError("n", "cannot be negative")
+		}
+
+		c.retryMax = n
+		return nil
+	}
+}
+
+// SetRetryWait is a client option for the bounds of the exponential backoff
+// between retries. It has no effect on a policy set with SetRetryPolicy.
+func SetRetryWait(min, max time.Duration) ClientOpt {
+	return func(c *Client) error {
+		if min <= 0 || max < min {
+			return NewArgError("min", "must be positive and not exceed max")
+		}
+
+		c.retryWaitMin = min
+		c.retryWaitMax = max
+		return nil
+	}
+}
+
+// SetRetryMaxElapsedTime is a client option bounding the time spent
+// retrying a request, from its first attempt. It has no effect on a policy
+// set with SetRetryPolicy.
+func SetRetryMaxElapsedTime(d time.Duration) ClientOpt {
+	return func(c *Client) error {
+		if d < 0 {
+			return NewArgError("d", "cannot be negative")
+		}
+
+		c.retryMaxElapsed = d
+		return nil
+	}
+}
+
+// SetRetryJitter is a client option randomizing the delays between retries.
+// It has no effect on a policy set with SetRetryPolicy.
+func SetRetryJitter(j Jitter) ClientOpt {
+	return func(c *Client) error {
+		if j < NoJitter || j > EqualJitter {
+			return NewArgError("j", "is not a known jitter strategy")
+		}
+
+		c.retryJitter = j
+		return nil
+	}
+}
+
+// SetRetryPolicy is a client option replacing the default BackoffPolicy
+// configured by SetRetryMax and SetRetryWait.
+func SetRetryPolicy(p RetryPolicy) ClientOpt {
+	return func(c *Client) error {
+		if p == nil {
+			return NewArgError("p", "cannot be nil")
+		}
+
+		c.retryPolicy = p
+		return nil
+	}
+}
+
+// SetRetryBudget is a client option sharing a RetryBudget between all the
+// requests of the client. The same budget may be shared by several clients.
+func SetRetryBudget(b *RetryBudget) ClientOpt {
+	return func(c *Client) error {
+		c.retryBudget = b
+		return nil
+	}
+}
+
+// retryPolicyOf returns the policy retrying the requests of c.
+func (c *Client) retryPolicyOf() RetryPolicy {
+	if c.retryPolicy != nil {
+		return c.retryPolicy
+	}
+	return c.backoffPolicy()
+}
+
+// backoffPolicy returns the BackoffPolicy configured by the client options.
+func (c *Client) backoffPolicy() *BackoffPolicy {
+	return &BackoffPolicy{
+		MaxRetries:     c.retryMax,
+		WaitMin:        c.retryWaitMin,
+		WaitMax:        c.retryWaitMax,
+		MaxElapsedTime: c.retryMaxElapsed,
+		Jitter:         c.retryJitter,
+	}
+}
+
+// send submits req, retrying it according to the retry policy of the
+// request, or else of the client.
+func (c *Client) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	policy := c.requestOptionsOf(ctx).retryPolicy
+	if policy == nil {
+		policy = c.retryPolicyOf()
+	}
+	start := time.Now()
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if err := rewindBody(req); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := DoRequestWithClient(ctx, c.client, req)
+		if ctx.Err() != nil {
+			return resp, err
+		}
+
+		failed := isRetryableFailure(resp, err)
+		if c.retryBudget != nil {
+			if failed {
+				c.retryBudget.onFailure()
+			} else {
+				c.retryBudget.onSuccess()
+			}
+		}
+
+		if err == nil && resp.StatusCode < http.StatusBadRequest {
+			return resp, err
+		}
+
+		if !isRewindable(req) {
+			return resp, err
+		}
+
+		policyReq := req.WithContext(context.WithValue(req.Context(), retryStartKey{}, start))
+		wait, retry := policy.ShouldRetry(policyReq, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+
+		if c.retryBudget != nil && !c.retryBudget.allowRetry() {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.CopyN(ioutil.Discard, resp.Body, 2<<10)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// isRetryableFailure reports whether an attempt failed in a way a retry may
+// fix.
+func isRetryableFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isIdempotent reports whether req may be sent several times with the same
+// effect.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isRewindable reports whether the body of req can be sent again.
+func isRewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewindBody resets the body of req so it can be sent again.
+func rewindBody(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return &EncodeError{Op: requestOp(req), Err: fmt.Errorf("rewinding body: %w", err)}
+	}
+
+	req.Body = body
+	return nil
+}
@@ -0,0 +1,94 @@
+package cloudcraft
+
+// Role is the role of a user or API key within an organization.
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleEditor   Role = "editor"
+	RoleReadOnly Role = "readonly"
+)
+
+var validRoles = []Role{RoleAdmin, RoleEditor, RoleReadOnly}
+
+// Permission is an access level granted on shared resources, e.g. the
+// blueprints of a team.
+type Permission string
+
+const (
+	PermissionRead   Permission = "read"
+	PermissionWrite  Permission = "write"
+	PermissionManage Permission = "manage"
+)
+
+var validPermissions = []Permission{PermissionRead, PermissionWrite, PermissionManage}
+
+// Valid reports whether r is a known Role.
+func (r Role) Valid() bool {
+	for _, v := range validRoles {
+		if r == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions returns the Permissions granted by r.
+func (r Role) Permissions() []Permission {
+	switch r {
+	case RoleAdmin:
+		return []Permission{PermissionRead, PermissionWrite, PermissionManage}
+	case RoleEditor:
+		return []Permission{PermissionRead, PermissionWrite}
+	case RoleReadOnly:
+		return []Permission{PermissionRead}
+	}
+	return nil
+}
+
+// Valid reports whether p is a known Permission.
+func (p Permission) Valid() bool {
+	for _, v := range validPermissions {
+		if p == v {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleValues returns the known Role values.
+func RoleValues() []string {
+	values := make([]string, len(validRoles))
+	for i, v := range validRoles {
+		values[i] = string(v)
+	}
+	return values
+}
+
+// ValidateRole returns an ArgError naming arg if r is set but not a known
+// Role, for the services built on a Client outside of this package.
+func ValidateRole(arg string, r Role) error {
+	if r == "" || r.Valid() {
+		return nil
+	}
+	return NewArgError(arg, oneOf(RoleValues()))
+}
+
+// PermissionValues returns the known Permission values.
+func PermissionValues() []string {
+	values := make([]string, len(validPermissions))
+	for i, v := range validPermissions {
+		values[i] = string(v)
+	}
+	return values
+}
+
+// validatePermission returns an ArgError naming arg if p is set but not a
+// known Permission.
+func validatePermission(arg string, p Permission) error {
+	if p == "" || p.Valid() {
+		return nil
+	}
+
+	return NewArgError(arg, oneOf(PermissionValues()))
+}
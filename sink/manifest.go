@@ -0,0 +1,129 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// manifestVersion is the version of the manifest format.
+const manifestVersion = 1
+
+// Source identifies what an artifact was produced from: a blueprint export,
+// or the snapshot of a region of an AWS account.
+type Source struct {
+	// Kind is "blueprint" or "awsAccount".
+	Kind   string `json:"kind"`
+	Id     string `json:"id"`
+	Region string `json:"region,omitempty"`
+}
+
+// Artifact describes a stored artifact, so downstream pipelines can verify
+// and index it.
+type Artifact struct {
+	Name        string    `json:"name"`
+	ContentType string    `json:"contentType,omitempty"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	Source      *Source   `json:"source,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+
+	// Parameters are the export or snapshot parameters the artifact was
+	// produced with, encoded as JSON.
+	Parameters interface{} `json:"parameters,omitempty"`
+}
+
+// Manifest lists the artifacts stored by a ManifestSink.
+type Manifest struct {
+	Version     int        `json:"version"`
+	GeneratedAt time.Time  `json:"generatedAt"`
+	Artifacts   []Artifact `json:"artifacts"`
+}
+
+// ManifestSink is a Sink recording the checksum, size and content type of
+// the artifacts it stores into another Sink, for a manifest written once
+// all the artifacts are stored.
+type ManifestSink struct {
+	Sink Sink
+
+	mu        sync.Mutex
+	artifacts []Artifact
+}
+
+var _ Sink = &ManifestSink{}
+
+// NewManifestSink returns a ManifestSink storing artifacts into s.
+func NewManifestSink(s Sink) *ManifestSink {
+	return &ManifestSink{Sink: s}
+}
+
+// Put stores content into the underlying Sink and records it without
+// source nor parameters.
+func (m *ManifestSink) Put(ctx context.Context, name, contentType string, content io.Reader) error {
+	return m.PutArtifact(ctx, &Artifact{Name: name, ContentType: contentType}, content)
+}
+
+// PutArtifact stores content as the artifact described by a, whose Name,
+// ContentType, Source and Parameters are set by the caller. The size,
+// checksum and creation time are computed from content.
+func (m *ManifestSink) PutArtifact(ctx context.Context, a *Artifact, content io.Reader) error {
+	artifact := *a
+	artifact.CreatedAt = time.Now().UTC()
+
+	h := sha256.New()
+	counter := &countingWriter{}
+	tee := io.TeeReader(content, io.MultiWriter(h, counter))
+
+	if err := m.Sink.Put(ctx, artifact.Name, artifact.ContentType, tee); err != nil {
+		return err
+	}
+
+	artifact.Size = counter.n
+	artifact.SHA256 = hex.EncodeToString(h.Sum(nil))
+
+	m.mu.Lock()
+	m.artifacts = append(m.artifacts, artifact)
+	m.mu.Unlock()
+
+	return nil
+}
+
+// Manifest returns the manifest of the artifacts stored so far, in the
+// order they were stored.
+func (m *ManifestSink) Manifest() *Manifest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	manifest := &Manifest{
+		Version:     manifestVersion,
+		GeneratedAt: time.Now().UTC(),
+		Artifacts:   make([]Artifact, len(m.artifacts)),
+	}
+	copy(manifest.Artifacts, m.artifacts)
+	return manifest
+}
+
+// WriteManifest stores the manifest of the artifacts stored so far as the
+// JSON artifact name of the underlying Sink, e.g. "manifest.json".
+func (m *ManifestSink) WriteManifest(ctx context.Context, name string) error {
+	data, err := json.MarshalIndent(m.Manifest(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return m.Sink.Put(ctx, name, "application/json", bytes.NewReader(append(data, '\n')))
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,264 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	s3Service       = "s3"
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	s3DefaultRegion = "us-east-1"
+	amzDateLayout   = "20060102T150405Z"
+)
+
+// S3Credentials are the AWS credentials used to sign S3 requests.
+type S3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// S3 is a Sink uploading artifacts as objects of an S3 bucket, below an
+// optional key prefix. Requests are signed with AWS Signature Version 4.
+type S3 struct {
+	Bucket      string
+	Prefix      string
+	Region      string
+	Credentials S3Credentials
+
+	// Endpoint overrides the S3 endpoint, e.g. for S3 compatible storage.
+	// Objects are then addressed in path style.
+	Endpoint string
+
+	// HTTP client used to upload objects, http.DefaultClient if nil.
+	HTTPClient *http.Client
+
+	now func() time.Time
+}
+
+var _ Sink = &S3{}
+
+// NewS3FromEnv returns an S3 Sink for a "bucket/prefix" target, reading the
+// region and credentials from the standard AWS environment variables.
+func NewS3FromEnv(target string) (*S3, error) {
+	bucket, prefix := target, ""
+	if i := strings.Index(target, "/"); i >= 0 {
+		bucket, prefix = target[:i], strings.Trim(target[i+1:], "/")
+	}
+
+	if bucket == "" {
+		return nil, fmt.Errorf("s3 target %q has no bucket", target)
+	}
+
+	s := &S3{
+		Bucket: bucket,
+		Prefix: prefix,
+		Region: os.Getenv("AWS_REGION"),
+		Credentials: S3Credentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		},
+		Endpoint: os.Getenv("AWS_ENDPOINT_URL_S3"),
+	}
+
+	if s.Region == "" {
+		s.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+
+	if s.Credentials.AccessKeyID == "" || s.Credentials.SecretAccessKey == "" {
+		return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set to upload to S3")
+	}
+
+	return s, nil
+}
+
+// Put uploads content as the object Prefix/name. The content is read in
+// memory first, as the request is signed with its SHA-256 and S3 requires
+// the length of uploads.
+func (s *S3) Put(ctx context.Context, name, contentType string, content io.Reader) error {
+	body, err := ioutil.ReadAll(content)
+	if err != nil {
+		return err
+	}
+
+	u, err := s.objectURL(path.Join(s.Prefix, name))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, body)
+
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("s3 put %s: %s: %s", u, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
+
+func (s *S3) region() string {
+	if s.Region == "" {
+		return s3DefaultRegion
+	}
+	return s.Region
+}
+
+// objectURL returns the URL of the object key, virtual host style unless an
+// Endpoint is configured.
+func (s *S3) objectURL(key string) (*url.URL, error) {
+	if s.Endpoint != "" {
+		u, err := url.Parse(s.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		u.Path = "/" + s.Bucket + "/" + key
+		u.RawPath = "/" + s.Bucket + "/" + s3EscapePath(key)
+		return u, nil
+	}
+
+	return &url.URL{
+		Scheme:  "https",
+		Host:    fmt.Sprintf("%s.s3.%s.amazonaws.com", s.Bucket, s.region()),
+		Path:    "/" + key,
+		RawPath: "/" + s3EscapePath(key),
+	}, nil
+}
+
+// sign adds the AWS Signature Version 4 headers to req.
+func (s *S3) sign(req *http.Request, body []byte) {
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	t := now().UTC()
+	amzDate := t.Format(amzDateLayout)
+	date := t.Format("20060102")
+
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.Credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.Credentials.SessionToken)
+	}
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		headers["content-type"] = ct
+	}
+	if s.Credentials.SessionToken != "" {
+		headers["x-amz-security-token"] = s.Credentials.SessionToken
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, s.region(), s3Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := signingKey(s.Credentials.SecretAccessKey, date, s.region(), s3Service)
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.Credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// signingKey derives the Signature Version 4 key of a date, region and
+// service from a secret access key.
+func signingKey(secret, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+// s3EscapePath URI encodes each segment of an object key as required by
+// Signature Version 4, keeping the slashes.
+func s3EscapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		var b strings.Builder
+		for _, c := range []byte(segment) {
+			if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+				c == '-' || c == '_' || c == '.' || c == '~' {
+				b.WriteByte(c)
+			} else {
+				fmt.Fprintf(&b, "%%%02X", c)
+			}
+		}
+		segments[i] = b.String()
+	}
+	return strings.Join(segments, "/")
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
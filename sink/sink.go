@@ -0,0 +1,53 @@
+// Package sink provides destinations for the artifacts produced by the
+// Cloudcraft API, such as account snapshots and blueprint exports.
+package sink
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Sink stores named artifacts. Names use forward slashes as separators.
+type Sink interface {
+	Put(ctx context.Context, name, contentType string, content io.Reader) error
+}
+
+// Dir is a Sink writing artifacts as files below a local directory.
+type Dir struct {
+	Path string
+}
+
+var _ Sink = &Dir{}
+
+// NewDir returns a Sink writing artifacts below path.
+func NewDir(path string) *Dir {
+	return &Dir{Path: path}
+}
+
+// Put writes content to the file name below the directory, creating parent
+// directories as needed. The file is written atomically.
+func (d *Dir) Put(ctx context.Context, name, contentType string, content io.Reader) error {
+	path := filepath.Join(d.Path, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, content); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
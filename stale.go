@@ -0,0 +1,260 @@
+package cloudcraft
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CachedResponse is the last successful response of a GET request stored by
+// a ResponseCache.
+type CachedResponse struct {
+	StatusCode  int       `json:"statusCode"`
+	ContentType string    `json:"contentType,omitempty"`
+	Body        []byte    `json:"body"`
+	StoredAt    time.Time `json:"storedAt"`
+}
+
+// ResponseCache stores the last successful response of the GET requests of
+// a client, keyed by credentials, URL and accepted media type, see
+// SetStaleOnError. The credentials are part of the key as a hash of the
+// Authorization header, so clients of different organizations sharing a
+// cache never get the results of one another. Get
+// returns a nil entry for keys not cached. Errors of the cache never fail
+// requests, they are treated as cache misses.
+type ResponseCache interface {
+	Get(key string) (*CachedResponse, error)
+	Put(key string, entry *CachedResponse) error
+}
+
+// Staleness describes a Response holding a cached result, returned in place
+// of a request failing during an outage, see SetStaleOnError.
+type Staleness struct {
+	// StoredAt is the time the cached result was received, zero if unknown.
+	StoredAt time.Time
+
+	// Err is the error of the failed request.
+	Err error
+}
+
+// Stale returns the staleness of the response, and whether it is stale. A
+// stale response comes with a nil error: callers wanting fresh results only
+// must check it.
+func (r *Response) Stale() (Staleness, bool) {
+	if r.stale == nil {
+		return Staleness{}, false
+	}
+	return *r.stale, true
+}
+
+// SetStaleOnError is a client option degrading gracefully during Cloudcraft
+// outages: the GET requests failing with a 5xx status or a network error
+// return the last successful result of the same request, stored in cache,
+// with a stale Response. Results older than maxAge are not returned, whatever
+// their age if maxAge is zero.
+//
+// Only the results decoded into values, such as those of the Get and List
+// methods, are cached, not the content of exports and snapshots. The
+// Blueprints of Blueprints.Get are returned from the BlueprintCache of the
+// client instead, if set. Requests whose context is canceled fail as usual.
+func SetStaleOnError(cache ResponseCache, maxAge time.Duration) ClientOpt {
+	return func(c *Client) error {
+		if cache == nil {
+			return NewArgError("cache", "cannot be nil")
+		}
+		if maxAge < 0 {
+			return NewArgError("maxAge", "cannot be negative")
+		}
+
+		c.staleCache = cache
+		c.staleMaxAge = maxAge
+		return nil
+	}
+}
+
+// degradesGracefully reports whether the failure of a request sent with ctx
+// may be answered with a stale result.
+func degradesGracefully(ctx context.Context, err error) bool {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return false
+	}
+
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return true
+	}
+
+	var errResp *ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response.StatusCode >= 500
+}
+
+// tooOld reports whether a result stored at storedAt is too old to be
+// returned stale.
+func (c *Client) tooOld(storedAt time.Time) bool {
+	if c.staleMaxAge == 0 {
+		return false
+	}
+	return storedAt.IsZero() || time.Since(storedAt) > c.staleMaxAge
+}
+
+// doStale sends a GET request like do, caching its result in the response
+// cache of the client and returning the cached result if it fails during an
+// outage.
+func (c *Client) doStale(ctx context.Context, req *http.Request, v interface{}) (*Response, error) {
+	key := responseKey(req)
+
+	body := new(bytes.Buffer)
+	resp, err := c.do(ctx, req, body)
+	if err == nil {
+		contentType := resp.Header.Get("Content-Type")
+		err = c.codecFor(contentType).Decode(bytes.NewReader(body.Bytes()), v)
+		if err == io.EOF && resp.StatusCode == http.StatusAccepted {
+			return resp, nil
+		}
+		if err != nil {
+			return nil, &DecodeError{Op: requestOp(req), Err: err}
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			_ = c.staleCache.Put(key, &CachedResponse{
+				StatusCode:  resp.StatusCode,
+				ContentType: contentType,
+				Body:        body.Bytes(),
+				StoredAt:    time.Now().UTC(),
+			})
+		}
+		return resp, nil
+	}
+
+	if !degradesGracefully(ctx, err) {
+		return resp, err
+	}
+	entry, cacheErr := c.staleCache.Get(key)
+	if cacheErr != nil || entry == nil || c.tooOld(entry.StoredAt) {
+		return resp, err
+	}
+	if c.codecFor(entry.ContentType).Decode(bytes.NewReader(entry.Body), v) != nil {
+		return resp, err
+	}
+
+	return staleResponse(req, entry.StatusCode, entry.ContentType, len(entry.Body), &Staleness{StoredAt: entry.StoredAt, Err: err}), nil
+}
+
+// responseKey returns the key of the result of req in the response cache.
+func responseKey(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+	return hex.EncodeToString(sum[:8]) + " " + req.Header.Get("Accept") + " " + req.URL.String()
+}
+
+// staleResponse returns the Response of a cached result of req.
+func staleResponse(req *http.Request, statusCode int, contentType string, size int, stale *Staleness) *Response {
+	r := &http.Response{
+		Status:        strconv.Itoa(statusCode) + " " + http.StatusText(statusCode),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		Body:          ioutil.NopCloser(http.NoBody),
+		ContentLength: int64(size),
+		Request:       req,
+	}
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+
+	resp := newResponse(r)
+	resp.stale = stale
+	return resp
+}
+
+// DefaultMemoryResponseCacheEntries is the number of entries of a
+// MemoryResponseCache created with no limit.
+const DefaultMemoryResponseCacheEntries = 1024
+
+// MemoryResponseCache is a ResponseCache held in memory, keeping the most
+// recently used entries up to a limit. It is safe for concurrent use.
+type MemoryResponseCache struct {
+	maxEntries int
+	maxAge     time.Duration
+
+	mu      sync.Mutex
+	lru     *list.List // of *memoryResponse, most recently used first
+	entries map[string]*list.Element
+}
+
+type memoryResponse struct {
+	key   string
+	entry *CachedResponse
+}
+
+var _ ResponseCache = &MemoryResponseCache{}
+
+// NewMemoryResponseCache returns an empty MemoryResponseCache holding up to
+// maxEntries entries, DefaultMemoryResponseCacheEntries if zero, and
+// dropping those stored more than maxAge ago, typically the maxAge of
+// SetStaleOnError, none if zero.
+func NewMemoryResponseCache(maxEntries int, maxAge time.Duration) *MemoryResponseCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMemoryResponseCacheEntries
+	}
+	return &MemoryResponseCache{
+		maxEntries: maxEntries,
+		maxAge:     maxAge,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get implements ResponseCache.
+func (m *MemoryResponseCache) Get(key string) (*CachedResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, nil
+	}
+
+	if m.expired(e) {
+		m.lru.Remove(e)
+		delete(m.entries, key)
+		return nil, nil
+	}
+
+	m.lru.MoveToFront(e)
+	return e.Value.(*memoryResponse).entry, nil
+}
+
+// Put implements ResponseCache.
+func (m *MemoryResponseCache) Put(key string, entry *CachedResponse) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if e, ok := m.entries[key]; ok {
+		e.Value.(*memoryResponse).entry = entry
+		m.lru.MoveToFront(e)
+		return nil
+	}
+
+	m.entries[key] = m.lru.PushFront(&memoryResponse{key: key, entry: entry})
+	for oldest := m.lru.Back(); oldest != nil && (m.lru.Len() > m.maxEntries || m.expired(oldest)); oldest = m.lru.Back() {
+		m.lru.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryResponse).key)
+	}
+	return nil
+}
+
+// expired reports whether the entry of e is older than the maximum age.
+func (m *MemoryResponseCache) expired(e *list.Element) bool {
+	return m.maxAge > 0 && time.Since(e.Value.(*memoryResponse).entry.StoredAt) > m.maxAge
+}
@@ -0,0 +1,66 @@
+package cloudcraft
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStaleOnErrorSeparatesCredentials(t *testing.T) {
+	down := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", mediaType)
+		w.Write([]byte(`{"id": "` + r.Header.Get("Authorization") + `"}`))
+	}))
+	defer server.Close()
+
+	cache := NewMemoryResponseCache(0, 0)
+	newClient := func(token string) *Client {
+		client, err := New(nil, SetBaseURL(server.URL+"/"), SetRequestHeaders(map[string]string{"Authorization": token}),
+			SetStaleOnError(cache, 0), SetRetryPolicy(NoRetry))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+	a, b := newClient("a"), newClient("b")
+
+	if _, _, err := a.Users.Get(context.Background(), "me"); err != nil {
+		t.Fatal(err)
+	}
+
+	down = true
+	if user, _, err := a.Users.Get(context.Background(), "me"); err != nil || user.ID != "a" {
+		t.Errorf("stale Get() = %v, %v, want the cached user a", user, err)
+	}
+	if user, _, err := b.Users.Get(context.Background(), "me"); err == nil {
+		t.Errorf("Get() of another API key = %v, want an error", user)
+	}
+}
+
+func TestMemoryResponseCacheEviction(t *testing.T) {
+	cache := NewMemoryResponseCache(2, time.Hour)
+	now := time.Now()
+
+	cache.Put("a", &CachedResponse{StoredAt: now})
+	cache.Put("b", &CachedResponse{StoredAt: now})
+	cache.Get("a")
+	cache.Put("c", &CachedResponse{StoredAt: now})
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if entry, _ := cache.Get(key); (entry != nil) != want {
+			t.Errorf("Get(%q) = %v, want cached %t", key, entry, want)
+		}
+	}
+
+	cache.Put("old", &CachedResponse{StoredAt: now.Add(-2 * time.Hour)})
+	if entry, _ := cache.Get("old"); entry != nil {
+		t.Errorf("Get(old) = %v, want expired", entry)
+	}
+}
@@ -0,0 +1,105 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/updater/cloudcraft-go"
+)
+
+// FileStore is a Store keeping one file per key in a directory. Files are
+// replaced atomically, so a crash never leaves a partial value.
+type FileStore struct {
+	dir string
+}
+
+var _ Store = &FileStore{}
+
+// NewFileStore returns a FileStore keeping its files in dir, which is
+// created if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, cloudcraft.NewArgError("dir", "cannot be empty")
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("state: creating directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// path returns the file of key. Keys are escaped into file names, and a
+// leading dot too: the names of the keys never start with one, unlike ".",
+// ".." and the temporary files of Put.
+func (f *FileStore) path(key string) (string, error) {
+	if key == "" {
+		return "", cloudcraft.NewArgError("key", "cannot be empty")
+	}
+
+	name := url.PathEscape(key)
+	if strings.HasPrefix(name, ".") {
+		name = "%2E" + name[1:]
+	}
+	return filepath.Join(f.dir, name), nil
+}
+
+// Get implements Store.
+func (f *FileStore) Get(_ context.Context, key string) ([]byte, error) {
+	path, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+// Put implements Store.
+func (f *FileStore) Put(_ context.Context, key string, value []byte) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(f.dir, ".state-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete implements Store. Deleting a key without value is not an error.
+func (f *FileStore) Delete(_ context.Context, key string) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,52 @@
+package state
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+)
+
+func TestFileStoreKeys(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []string{".", "..", ".state-123", "%2E", "a/../b", "job"}
+	for _, key := range keys {
+		if err := store.Put(ctx, key, []byte(key)); err != nil {
+			t.Fatalf("Put(%q) returned error: %v", key, err)
+		}
+	}
+	for _, key := range keys {
+		value, err := store.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("Get(%q) returned error: %v", key, err)
+		}
+		if string(value) != key {
+			t.Errorf("Get(%q) = %q, want %q", key, value, key)
+		}
+	}
+
+	files, err := ioutil.ReadDir(store.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(keys) {
+		t.Errorf("%d files for %d keys", len(files), len(keys))
+	}
+
+	if err := store.Put(ctx, "", nil); err == nil {
+		t.Error("Put with an empty key returned no error")
+	}
+}
+
+func TestSQLiteStoreTable(t *testing.T) {
+	for _, table := range []string{"state; DROP TABLE users", "a.b", "1state", `"state"`} {
+		store := &SQLiteStore{Table: table}
+		if err := store.Init(context.Background()); err == nil {
+			t.Errorf("Init with table %q returned no error", table)
+		}
+	}
+}
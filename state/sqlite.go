@@ -0,0 +1,95 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/updater/cloudcraft-go"
+)
+
+// SQLiteStore is a Store keeping values in a table of a SQLite database,
+// opened by the caller with the driver of their choice, such as
+// modernc.org/sqlite or github.com/mattn/go-sqlite3. The table is created by
+// Init. Put relies on the upsert syntax of SQLite 3.24 and later.
+type SQLiteStore struct {
+	DB *sql.DB
+
+	// Table is the name of the table, cloudcraft_state if empty. It is
+	// written into the statements, so it must be an identifier of letters,
+	// digits and underscores.
+	Table string
+}
+
+var _ Store = &SQLiteStore{}
+
+// Init creates the table of the store if it does not exist.
+func (s *SQLiteStore) Init(ctx context.Context) error {
+	table, err := s.table()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.DB.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	key TEXT PRIMARY KEY,
+	value BLOB NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+)`, table))
+	return err
+}
+
+// Get implements Store.
+func (s *SQLiteStore) Get(ctx context.Context, key string) ([]byte, error) {
+	table, err := s.table()
+	if err != nil {
+		return nil, err
+	}
+
+	var value []byte
+	err = s.DB.QueryRowContext(ctx, fmt.Sprintf(`SELECT value FROM %s WHERE key = ?`, table), key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+// Put implements Store.
+func (s *SQLiteStore) Put(ctx context.Context, key string, value []byte) error {
+	table, err := s.table()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.DB.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (key, value, updated_at) VALUES (?, ?, ?)
+	ON CONFLICT (key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`, table),
+		key, value, time.Now().UTC())
+	return err
+}
+
+// Delete implements Store. Deleting a key without value is not an error.
+func (s *SQLiteStore) Delete(ctx context.Context, key string) error {
+	table, err := s.table()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.DB.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE key = ?`, table), key)
+	return err
+}
+
+// identifier matches the table names written into the statements.
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// table returns the name of the table, if it is a valid identifier.
+func (s *SQLiteStore) table() (string, error) {
+	if s.Table == "" {
+		return "cloudcraft_state", nil
+	}
+	if !identifier.MatchString(s.Table) {
+		return "", cloudcraft.NewArgError("Table", "must be an identifier of letters, digits and underscores")
+	}
+	return s.Table, nil
+}
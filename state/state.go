@@ -0,0 +1,74 @@
+// Package state persists the state of long running subsystems, such as the
+// time a snapshot scheduler last ran or the last blueprint revision a syncer
+// has seen, so that they resume where they left off after a restart.
+//
+// A Store holds opaque values by key. FileStore keeps them in a directory,
+// and SQLiteStore in a table of a database opened with the driver of the
+// caller. Checkpoints are the usual values of the subsystems.
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/updater/cloudcraft-go"
+)
+
+// ErrNotFound is returned by Store.Get for keys without value.
+var ErrNotFound = errors.New("state: key not found")
+
+// Store stores values by key. Implementations are safe for concurrent use.
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(ctx context.Context, key string, value []byte) error
+	Delete(ctx context.Context, key string) error
+}
+
+// Checkpoint is the progress of a recurring job, such as the snapshots of an
+// AWS account or the sync of a blueprint.
+type Checkpoint struct {
+	// LastRun is the time the job last completed.
+	LastRun time.Time `json:"lastRun"`
+
+	// Revision is the last revision the job has seen, such as the updatedAt
+	// of a blueprint, empty if none.
+	Revision string `json:"revision,omitempty"`
+}
+
+func (d Checkpoint) String() string {
+	return cloudcraft.Stringify(d)
+}
+
+// LoadCheckpoint returns the checkpoint stored under key, the zero
+// checkpoint if there is none.
+func LoadCheckpoint(ctx context.Context, s Store, key string) (*Checkpoint, error) {
+	value, err := s.Get(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return &Checkpoint{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := new(Checkpoint)
+	if err := json.Unmarshal(value, c); err != nil {
+		return nil, fmt.Errorf("state: decoding checkpoint %s: %w", key, err)
+	}
+	return c, nil
+}
+
+// SaveCheckpoint stores c under key.
+func SaveCheckpoint(ctx context.Context, s Store, key string, c *Checkpoint) error {
+	if c == nil {
+		return cloudcraft.NewArgError("c", "cannot be nil")
+	}
+
+	value, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, key, value)
+}
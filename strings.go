@@ -3,11 +3,27 @@ package cloudcraft
 import (
 	"bytes"
 	"fmt"
-	"io"
 	"reflect"
+	"strconv"
+	"sync"
+	"time"
 )
 
-var timestampType = reflect.TypeOf(Timestamp{})
+var (
+	timestampType = reflect.TypeOf(Timestamp{})
+	timeType      = reflect.TypeOf(time.Time{})
+	stringerType  = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// stringifyBuffers pools the buffers of Stringify, which is called by the
+// String method of every model.
+var stringifyBuffers = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// maxPooledBuffer is the capacity past which the buffers of Stringify, grown
+// by a large model, are left to the garbage collector rather than pooled.
+const maxPooledBuffer = 64 << 10
 
 // ResourceWithURN is an interface for interfacing with the types
 // that implement the URN method.
@@ -17,61 +33,102 @@ type ResourceWithURN interface {
 
 // Stringify attempts to create a string representation of Cloudcraft types
 func Stringify(message interface{}) string {
-	var buf bytes.Buffer
-	v := reflect.ValueOf(message)
-	stringifyValue(&buf, v)
-	return buf.String()
+	buf := stringifyBuffers.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	stringifyValue(buf, reflect.ValueOf(message))
+	s := buf.String()
+
+	if buf.Cap() <= maxPooledBuffer {
+		stringifyBuffers.Put(buf)
+	}
+	return s
 }
 
 // stringifyValue was graciously cargoculted from the goprotubuf library
-func stringifyValue(w io.Writer, val reflect.Value) {
-	if val.Kind() == reflect.Ptr && val.IsNil() {
-		_, _ = w.Write([]byte("<nil>"))
-		return
+func stringifyValue(w *bytes.Buffer, val reflect.Value) {
+	v := val
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			w.WriteString("<nil>")
+			return
+		}
+		v = v.Elem()
 	}
 
-	v := reflect.Indirect(val)
-
 	switch v.Kind() {
 	case reflect.String:
-		fmt.Fprintf(w, `"%s"`, v)
+		w.WriteByte('"')
+		w.WriteString(v.String())
+		w.WriteByte('"')
+		return
 	case reflect.Slice:
 		stringifySlice(w, v)
 		return
 	case reflect.Struct:
 		stringifyStruct(w, v)
+		return
+	case reflect.Invalid:
+		w.WriteString("<nil>")
+		return
+	}
+
+	if !v.CanInterface() {
+		return
+	}
+
+	if v.Type().Implements(stringerType) {
+		fmt.Fprint(w, v.Interface())
+		return
+	}
+
+	var scratch [64]byte
+	switch v.Kind() {
+	case reflect.Bool:
+		w.Write(strconv.AppendBool(scratch[:0], v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		w.Write(strconv.AppendInt(scratch[:0], v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		w.Write(strconv.AppendUint(scratch[:0], v.Uint(), 10))
+	case reflect.Float32:
+		w.Write(strconv.AppendFloat(scratch[:0], v.Float(), 'g', -1, 32))
+	case reflect.Float64:
+		w.Write(strconv.AppendFloat(scratch[:0], v.Float(), 'g', -1, 64))
 	default:
-		if v.CanInterface() {
-			fmt.Fprint(w, v.Interface())
-		}
+		fmt.Fprint(w, v.Interface())
 	}
 }
 
-func stringifySlice(w io.Writer, v reflect.Value) {
-	_, _ = w.Write([]byte{'['})
+func stringifySlice(w *bytes.Buffer, v reflect.Value) {
+	w.WriteByte('[')
 	for i := 0; i < v.Len(); i++ {
 		if i > 0 {
-			_, _ = w.Write([]byte{' '})
+			w.WriteByte(' ')
 		}
 
 		stringifyValue(w, v.Index(i))
 	}
 
-	_, _ = w.Write([]byte{']'})
+	w.WriteByte(']')
 }
 
-func stringifyStruct(w io.Writer, v reflect.Value) {
-	if v.Type().Name() != "" {
-		_, _ = w.Write([]byte(v.Type().String()))
+func stringifyStruct(w *bytes.Buffer, v reflect.Value) {
+	t := v.Type()
+	if t.Name() != "" {
+		w.WriteString(t.String())
 	}
 
-	// special handling of Timestamp values
-	if v.Type() == timestampType {
-		fmt.Fprintf(w, "{%s}", v.Interface())
+	// special handling of Timestamp and time.Time values
+	if t == timestampType || t == timeType {
+		w.WriteByte('{')
+		if v.CanInterface() {
+			fmt.Fprint(w, v.Interface())
+		}
+		w.WriteByte('}')
 		return
 	}
 
-	_, _ = w.Write([]byte{'{'})
+	w.WriteByte('{')
 
 	var sep bool
 	for i := 0; i < v.NumField(); i++ {
@@ -84,15 +141,15 @@ func stringifyStruct(w io.Writer, v reflect.Value) {
 		}
 
 		if sep {
-			_, _ = w.Write([]byte(", "))
+			w.WriteString(", ")
 		} else {
 			sep = true
 		}
 
-		_, _ = w.Write([]byte(v.Type().Field(i).Name))
-		_, _ = w.Write([]byte{':'})
+		w.WriteString(t.Field(i).Name)
+		w.WriteByte(':')
 		stringifyValue(w, fv)
 	}
 
-	_, _ = w.Write([]byte{'}'})
+	w.WriteByte('}')
 }
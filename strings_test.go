@@ -0,0 +1,73 @@
+package cloudcraft
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStringify(t *testing.T) {
+	type inner struct {
+		Name *string
+	}
+	type outer struct {
+		Inner  **inner
+		Nil    *inner
+		Tags   []string
+		Budget float32
+	}
+	type event struct {
+		At *Timestamp
+	}
+
+	name := "web"
+	in := &inner{Name: &name}
+	at := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		in   interface{}
+		want string
+	}{
+		{"nil", nil, "<nil>"},
+		{"nil pointer", (*Blueprint)(nil), "<nil>"},
+		{"string", "a", `"a"`},
+		{"pointer to pointer", &in, `cloudcraft.inner{Name:"web"}`},
+		{"nested pointers", outer{Inner: &in, Tags: []string{"a", "b"}, Budget: 1.5}, `cloudcraft.outer{Inner:cloudcraft.inner{Name:"web"}, Tags:["a" "b"], Budget:1.5}`},
+		{"time", at, "time.Time{2021-03-04 05:06:07 +0000 UTC}"},
+		{"timestamp", Timestamp{at}, "cloudcraft.Timestamp{2021-03-04 05:06:07 +0000 UTC}"},
+		{"timestamp field", event{At: &Timestamp{at}}, "cloudcraft.event{At:cloudcraft.Timestamp{2021-03-04 05:06:07 +0000 UTC}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Stringify(tt.in); got != tt.want {
+				t.Errorf("Stringify() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringifyLargeBufferNotPooled(t *testing.T) {
+	large := strings.Repeat("x", 2*maxPooledBuffer)
+	if got := Stringify(large); len(got) != len(large)+2 {
+		t.Fatalf("Stringify() length = %d, want %d", len(got), len(large)+2)
+	}
+
+	// Any buffer left in the pool must be within the cap.
+	for i := 0; i < 8; i++ {
+		buf := stringifyBuffers.Get().(interface{ Cap() int })
+		if buf.Cap() > maxPooledBuffer {
+			t.Fatalf("pooled buffer capacity = %d, want <= %d", buf.Cap(), maxPooledBuffer)
+		}
+	}
+}
+
+func BenchmarkStringify(b *testing.B) {
+	name := "web"
+	blueprint := Blueprint{Id: "0f1e2d3c", Name: name, CreatedAt: Timestamp{time.Now()}, Data: &BlueprintData{Name: name}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Stringify(blueprint)
+	}
+}
@@ -0,0 +1,134 @@
+package cloudcraft
+
+import (
+	"context"
+	"sort"
+)
+
+// GroupSummary is the inventory of a group of a blueprint, such as a VPC or
+// a subnet.
+type GroupSummary struct {
+	Id    string `json:"id"`
+	Type  string `json:"type,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Nodes int    `json:"nodes"`
+}
+
+func (d GroupSummary) String() string {
+	return Stringify(d)
+}
+
+// BlueprintSummary is the inventory of a blueprint, for tracking the
+// complexity of an architecture over time.
+type BlueprintSummary struct {
+	Nodes       int            `json:"nodes"`
+	NodesByType map[string]int `json:"nodesByType"`
+
+	Groups       []GroupSummary `json:"groups"`
+	GroupsByType map[string]int `json:"groupsByType"`
+
+	Edges         int `json:"edges"`
+	DanglingEdges int `json:"danglingEdges"`
+
+	// OrphanedNodes are the ids of the nodes no edge connects to, and
+	// UngroupedNodes of the nodes inside no group.
+	OrphanedNodes  []string `json:"orphanedNodes"`
+	UngroupedNodes []string `json:"ungroupedNodes"`
+}
+
+func (d BlueprintSummary) String() string {
+	return Stringify(d)
+}
+
+// Summarize returns the inventory of data. Edges are dangling when either
+// end is missing from the blueprint. A nil BlueprintData is treated as
+// empty.
+func Summarize(data *BlueprintData) *BlueprintSummary {
+	if data == nil {
+		data = &BlueprintData{}
+	}
+
+	summary := &BlueprintSummary{
+		NodesByType:    make(map[string]int),
+		Groups:         []GroupSummary{},
+		GroupsByType:   make(map[string]int),
+		OrphanedNodes:  []string{},
+		UngroupedNodes: []string{},
+	}
+
+	ids := make(map[string]bool)
+	for _, c := range blueprintCollections(data) {
+		for _, element := range c.elements {
+			if id := elementString(element, "id"); id != "" {
+				ids[id] = true
+			}
+		}
+	}
+
+	connected := make(map[string]bool)
+	for _, edge := range data.Edges {
+		summary.Edges++
+
+		from, to := elementString(edge, "from"), elementString(edge, "to")
+		if !ids[from] || !ids[to] {
+			summary.DanglingEdges++
+		}
+		connected[from] = true
+		connected[to] = true
+	}
+
+	grouped := make(map[string]bool)
+	for _, group := range data.Groups {
+		g := GroupSummary{
+			Id:   elementString(group, "id"),
+			Type: elementString(group, "type"),
+			Name: elementString(group, "name"),
+		}
+
+		members, _ := group["nodes"].([]interface{})
+		for _, member := range members {
+			if id, ok := member.(string); ok {
+				grouped[id] = true
+				g.Nodes++
+			}
+		}
+
+		summary.Groups = append(summary.Groups, g)
+		summary.GroupsByType[g.Type]++
+	}
+	sort.SliceStable(summary.Groups, func(i, j int) bool {
+		return summary.Groups[i].Nodes > summary.Groups[j].Nodes
+	})
+
+	for _, node := range data.Nodes {
+		summary.Nodes++
+		summary.NodesByType[elementString(node, "type")]++
+
+		id := elementString(node, "id")
+		if !connected[id] {
+			summary.OrphanedNodes = append(summary.OrphanedNodes, id)
+		}
+		if !grouped[id] {
+			summary.UngroupedNodes = append(summary.UngroupedNodes, id)
+		}
+	}
+
+	return summary
+}
+
+// Summary gets a Blueprint and returns its inventory, see Summarize.
+func (s *BlueprintsServiceOp) Summary(ctx context.Context, blueprintId string, opts ...RequestOpt) (*BlueprintSummary, *Response, error) {
+	blueprint, resp, err := s.Get(ctx, blueprintId, opts...)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return Summarize(blueprint.Data), resp, nil
+}
+
+// elementString returns the string property key of a blueprint element, or
+// an empty string.
+func elementString(element map[string]interface{}, key string) string {
+	s, _ := element[key].(string)
+	return s
+}
@@ -0,0 +1,186 @@
+package cloudcraft
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+const teamBasePath = "team"
+
+// TeamsService is an interface for interfacing with the Teams
+// endpoints of the Cloudcraft API
+type TeamsService interface {
+	List(context.Context, ...RequestOpt) ([]Team, *Response, error)
+	Get(context.Context, string, ...RequestOpt) (*Team, *Response, error)
+	Create(context.Context, *TeamCreateOrUpdateRequest, ...RequestOpt) (*Team, *Response, error)
+	Update(context.Context, string, *TeamCreateOrUpdateRequest, ...RequestOpt) (*Team, *Response, error)
+	Delete(context.Context, string, ...RequestOpt) (*Response, error)
+}
+
+// TeamsServiceOp handles communication with the Team related methods of the
+// Cloudcraft API.
+type TeamsServiceOp struct {
+	client *Client
+}
+
+var _ TeamsService = &TeamsServiceOp{}
+
+// Team represents a Cloudcraft Team
+type Team struct {
+	Id         string     `json:"id,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	Permission Permission `json:"permission,omitempty"`
+	CreatedAt  Timestamp  `json:"createdAt,omitempty"`
+	UpdatedAt  Timestamp  `json:"updatedAt,omitempty"`
+	CreatorId  string     `json:"CreatorId,omitempty"`
+	MemberIds  []string   `json:"memberIds,omitempty"`
+}
+
+// Convert Team to a string
+func (d Team) String() string {
+	return Stringify(d)
+}
+
+type TeamsRoot struct {
+	Teams []Team `json:"teams"`
+}
+
+// TeamCreateOrUpdateRequest represents a request to create or update a Team.
+// Permission is the access level members have on the blueprints shared with
+// the Team.
+type TeamCreateOrUpdateRequest struct {
+	Name       string     `json:"name"`
+	Permission Permission `json:"permission,omitempty"`
+	MemberIds  []string   `json:"memberIds,omitempty"`
+}
+
+func (d TeamCreateOrUpdateRequest) String() string {
+	return Stringify(d)
+}
+
+// List all Teams.
+func (s *TeamsServiceOp) List(ctx context.Context, opts ...RequestOpt) ([]Team, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, teamBasePath, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := new(TeamsRoot)
+	resp, err := s.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, resp, err
+	}
+	return root.Teams, resp, err
+}
+
+// Get individual Team.
+func (s *TeamsServiceOp) Get(ctx context.Context, teamID string, opts ...RequestOpt) (*Team, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if teamID == "" {
+		return nil, nil, NewArgError("teamID", "cannot be empty")
+	}
+
+	path := fmt.Sprintf("%s/%s", teamBasePath, teamID)
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	team := new(Team)
+	resp, err := s.client.Do(ctx, req, team)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return team, resp, err
+}
+
+// Create Team
+func (s *TeamsServiceOp) Create(ctx context.Context, createRequest *TeamCreateOrUpdateRequest, opts ...RequestOpt) (*Team, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if createRequest == nil {
+		return nil, nil, NewArgError("createRequest", "cannot be nil")
+	}
+
+	if err := validatePermission("createRequest.Permission", createRequest.Permission); err != nil {
+		return nil, nil, err
+	}
+
+	path := teamBasePath
+
+	req, err := s.client.NewRequest(ctx, http.MethodPost, path, createRequest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	team := new(Team)
+	resp, err := s.client.Do(ctx, req, team)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return team, resp, err
+}
+
+// Update Team
+func (s *TeamsServiceOp) Update(ctx context.Context, teamID string, updateRequest *TeamCreateOrUpdateRequest, opts ...RequestOpt) (*Team, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if teamID == "" {
+		return nil, nil, NewArgError("teamID", "cannot be empty")
+	}
+
+	if updateRequest == nil {
+		return nil, nil, NewArgError("updateRequest", "cannot be nil")
+	}
+
+	if err := validatePermission("updateRequest.Permission", updateRequest.Permission); err != nil {
+		return nil, nil, err
+	}
+
+	path := fmt.Sprintf("%s/%s", teamBasePath, teamID)
+
+	req, err := s.client.NewRequest(ctx, http.MethodPut, path, updateRequest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	team := new(Team)
+	resp, err := s.client.Do(ctx, req, team)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return team, resp, err
+}
+
+// Delete Team.
+func (s *TeamsServiceOp) Delete(ctx context.Context, teamID string, opts ...RequestOpt) (*Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if teamID == "" {
+		return nil, NewArgError("teamID", "cannot be empty")
+	}
+
+	path := fmt.Sprintf("%s/%s", teamBasePath, teamID)
+
+	req, err := s.client.NewRequest(ctx, http.MethodDelete, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+
+	return resp, err
+}
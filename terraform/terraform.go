@@ -0,0 +1,233 @@
+// Package terraform implements the resource plumbing of a Terraform provider
+// for Cloudcraft on top of cloudcraft-go, independently of the Terraform
+// plugin SDK. A provider maps its schema to the models of this package and
+// delegates the create, read, update, delete and import operations to the
+// resources, which handle normalization and drift detection.
+package terraform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/updater/cloudcraft-go"
+)
+
+// AwsAccountModel is the state of a cloudcraft_aws_account resource.
+type AwsAccountModel struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	RoleArn    string `json:"role_arn"`
+	ExternalID string `json:"external_id"`
+}
+
+// AwsAccountResource manages cloudcraft_aws_account resources.
+type AwsAccountResource struct {
+	Client *cloudcraft.Client
+}
+
+// Create registers the AWS account described by model.
+func (r *AwsAccountResource) Create(ctx context.Context, model *AwsAccountModel) (*AwsAccountModel, error) {
+	account, _, err := r.Client.AwsAccounts.Create(ctx, &cloudcraft.AwsAccountCreateOrUpdateRequest{
+		Name:    model.Name,
+		RoleArn: model.RoleArn,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return awsAccountModel(account), nil
+}
+
+// Read returns the current state of the AWS account, or nil if it no longer
+// exists and must be removed from the Terraform state.
+func (r *AwsAccountResource) Read(ctx context.Context, id string) (*AwsAccountModel, error) {
+	account, _, err := r.Client.AwsAccounts.Get(ctx, id)
+	if cloudcraft.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return awsAccountModel(account), nil
+}
+
+// Update changes the AWS account to match model.
+func (r *AwsAccountResource) Update(ctx context.Context, model *AwsAccountModel) (*AwsAccountModel, error) {
+	account, _, err := r.Client.AwsAccounts.Update(ctx, model.ID, &cloudcraft.AwsAccountCreateOrUpdateRequest{
+		Name:    model.Name,
+		RoleArn: model.RoleArn,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return awsAccountModel(account), nil
+}
+
+// Delete removes the AWS account. Deleting an account that no longer exists
+// is not an error.
+func (r *AwsAccountResource) Delete(ctx context.Context, id string) error {
+	_, err := r.Client.AwsAccounts.Delete(ctx, id)
+	if cloudcraft.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// Import returns the state of an existing AWS account, failing if it does
+// not exist.
+func (r *AwsAccountResource) Import(ctx context.Context, id string) (*AwsAccountModel, error) {
+	account, _, err := r.Client.AwsAccounts.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return awsAccountModel(account), nil
+}
+
+func awsAccountModel(account *cloudcraft.AwsAccount) *AwsAccountModel {
+	return &AwsAccountModel{
+		ID:         account.Id,
+		Name:       account.Name,
+		RoleArn:    account.RoleArn,
+		ExternalID: account.ExternalId,
+	}
+}
+
+// BlueprintModel is the state of a cloudcraft_blueprint resource. Data holds
+// the blueprint data as normalized JSON, see NormalizeJSON.
+type BlueprintModel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Data string `json:"data"`
+}
+
+// BlueprintResource manages cloudcraft_blueprint resources.
+type BlueprintResource struct {
+	Client *cloudcraft.Client
+}
+
+// Create creates the blueprint described by model.
+func (r *BlueprintResource) Create(ctx context.Context, model *BlueprintModel) (*BlueprintModel, error) {
+	data, err := blueprintData(model)
+	if err != nil {
+		return nil, err
+	}
+
+	blueprint, _, err := r.Client.Blueprints.Create(ctx, &cloudcraft.BlueprintCreateRequest{Data: data})
+	if err != nil {
+		return nil, err
+	}
+
+	return blueprintModel(blueprint)
+}
+
+// Read returns the current state of the blueprint, or nil if it no longer
+// exists and must be removed from the Terraform state.
+func (r *BlueprintResource) Read(ctx context.Context, id string) (*BlueprintModel, error) {
+	blueprint, _, err := r.Client.Blueprints.Get(ctx, id)
+	if cloudcraft.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return blueprintModel(blueprint)
+}
+
+// Update changes the blueprint to match model.
+func (r *BlueprintResource) Update(ctx context.Context, model *BlueprintModel) (*BlueprintModel, error) {
+	data, err := blueprintData(model)
+	if err != nil {
+		return nil, err
+	}
+
+	blueprint, _, err := r.Client.Blueprints.Update(ctx, model.ID, &cloudcraft.BlueprintUpdateRequest{Data: data})
+	if err != nil {
+		return nil, err
+	}
+
+	return blueprintModel(blueprint)
+}
+
+// Delete removes the blueprint. Deleting a blueprint that no longer exists is
+// not an error.
+func (r *BlueprintResource) Delete(ctx context.Context, id string) error {
+	_, err := r.Client.Blueprints.Delete(ctx, id)
+	if cloudcraft.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// Import returns the state of an existing blueprint, failing if it does not
+// exist.
+func (r *BlueprintResource) Import(ctx context.Context, id string) (*BlueprintModel, error) {
+	blueprint, _, err := r.Client.Blueprints.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return blueprintModel(blueprint)
+}
+
+// blueprintData decodes the data of model, using its Name unless the data
+// sets one.
+func blueprintData(model *BlueprintModel) (*cloudcraft.BlueprintData, error) {
+	data := new(cloudcraft.BlueprintData)
+	if model.Data != "" {
+		if err := json.Unmarshal([]byte(model.Data), data); err != nil {
+			return nil, cloudcraft.NewArgError("data", "must be valid JSON")
+		}
+	}
+
+	if data.Name == "" {
+		data.Name = model.Name
+	}
+
+	return data, nil
+}
+
+func blueprintModel(blueprint *cloudcraft.Blueprint) (*BlueprintModel, error) {
+	model := &BlueprintModel{ID: blueprint.Id, Name: blueprint.Name}
+	if blueprint.Data == nil {
+		return model, nil
+	}
+
+	b, err := json.Marshal(blueprint.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	if model.Data, err = NormalizeJSON(string(b)); err != nil {
+		return nil, err
+	}
+
+	if model.Name == "" {
+		model.Name = blueprint.Data.Name
+	}
+
+	return model, nil
+}
+
+// NormalizeJSON returns s re-encoded with sorted object keys and without
+// insignificant whitespace, so that semantically equal documents compare
+// equal. Providers use it to suppress spurious diffs of the data attribute.
+func NormalizeJSON(s string) (string, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader([]byte(s)))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
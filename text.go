@@ -0,0 +1,389 @@
+package cloudcraft
+
+import (
+	"fmt"
+)
+
+// MapPos is the position of a text or connector on the canvas: either a
+// point of the grid, or an offset from another element of the blueprint,
+// RelTo, which the text or connector follows when it is moved.
+type MapPos struct {
+	// X and Y are the point of the grid, when RelTo is empty.
+	X float64
+	Y float64
+
+	RelTo  string
+	Offset [2]float64
+}
+
+func (p MapPos) String() string {
+	return Stringify(p)
+}
+
+// value returns the mapPos of an element at p.
+func (p MapPos) value() interface{} {
+	if p.RelTo == "" {
+		return []interface{}{p.X, p.Y}
+	}
+	return map[string]interface{}{
+		"relTo":  p.RelTo,
+		"offset": []interface{}{p.Offset[0], p.Offset[1]},
+	}
+}
+
+// parseMapPos parses the mapPos of an element.
+func parseMapPos(v interface{}) MapPos {
+	var p MapPos
+	switch v := v.(type) {
+	case []interface{}:
+		if len(v) == 2 {
+			p.X, _ = v[0].(float64)
+			p.Y, _ = v[1].(float64)
+		}
+	case map[string]interface{}:
+		p.RelTo, _ = v["relTo"].(string)
+		if offset, ok := v["offset"].([]interface{}); ok && len(offset) == 2 {
+			p.Offset[0], _ = offset[0].(float64)
+			p.Offset[1], _ = offset[1].(float64)
+		}
+	}
+	return p
+}
+
+// validate checks p is a position in data.
+func (p MapPos) validate(arg string, data *BlueprintData) error {
+	if p.RelTo == "" {
+		if p.Offset != [2]float64{} {
+			return NewArgError(arg+".Offset", "requires RelTo")
+		}
+		return nil
+	}
+	if !data.hasElement(p.RelTo) {
+		return NewArgError(arg+".RelTo", fmt.Sprintf("%s is not an element of the blueprint", p.RelTo))
+	}
+	return nil
+}
+
+// At returns the position of the point (x, y) of the grid.
+func At(x, y float64) MapPos {
+	return MapPos{X: x, Y: y}
+}
+
+// RelativeTo returns the position offset by (dx, dy) from the element of the
+// given id.
+func RelativeTo(id string, dx, dy float64) MapPos {
+	return MapPos{RelTo: id, Offset: [2]float64{dx, dy}}
+}
+
+// Text is a text annotation of a blueprint, such as a title or the label of
+// a node, see NewText.
+type Text struct {
+	Id   string
+	Text string
+
+	// Color of the text, a hex color such as "#000000", and size of its
+	// font, the defaults of the editor if empty.
+	Color    string
+	TextSize int
+
+	// Direction and Isometric set how the text is laid on the isometric
+	// canvas, drawn flat on it if Isometric is set.
+	Direction string
+	Isometric bool
+
+	MapPos MapPos
+}
+
+func (d Text) String() string {
+	return Stringify(d)
+}
+
+// NewText returns a Text at the point (0, 0) of the grid.
+func NewText(text string) *Text {
+	return &Text{Text: text}
+}
+
+// At moves the text to pos and returns it.
+func (t *Text) At(pos MapPos) *Text {
+	t.MapPos = pos
+	return t
+}
+
+// WithColor sets the color and size of the text and returns it. A zero size
+// leaves the size unchanged.
+func (t *Text) WithColor(color string, size int) *Text {
+	t.Color = color
+	if size != 0 {
+		t.TextSize = size
+	}
+	return t
+}
+
+// Flat lays the text flat on the isometric canvas in direction and returns
+// it.
+func (t *Text) Flat(direction string) *Text {
+	t.Isometric = true
+	t.Direction = direction
+	return t
+}
+
+// validate checks the Text can be added to data.
+func (t *Text) validate(data *BlueprintData) error {
+	if t == nil {
+		return NewArgError("text", "cannot be nil")
+	}
+
+	if t.Text == "" {
+		return NewArgError("text.Text", "cannot be empty")
+	}
+	if t.Color != "" && !isHexColor(t.Color) {
+		return NewArgError("text.Color", "must be a hex color such as #000000")
+	}
+	if t.TextSize < 0 {
+		return NewArgError("text.TextSize", "cannot be negative")
+	}
+	return t.MapPos.validate("text.MapPos", data)
+}
+
+// element returns the text element of the blueprint data.
+func (t *Text) element() map[string]interface{} {
+	e := map[string]interface{}{
+		"id":     t.Id,
+		"type":   "isotext",
+		"text":   t.Text,
+		"mapPos": t.MapPos.value(),
+	}
+	if t.Color != "" {
+		e["color"] = t.Color
+	}
+	if t.TextSize > 0 {
+		e["textSize"] = t.TextSize
+	}
+	if t.Direction != "" {
+		e["direction"] = t.Direction
+	}
+	if t.Isometric {
+		e["isometric"] = true
+	}
+	return e
+}
+
+// ArrowStyle is the arrowheads of a Connector.
+type ArrowStyle string
+
+const (
+	ArrowNone  ArrowStyle = "none"
+	ArrowStart ArrowStyle = "start"
+	ArrowEnd   ArrowStyle = "end"
+	ArrowBoth  ArrowStyle = "both"
+)
+
+// ArrowStyleValues returns the known ArrowStyle values.
+func ArrowStyleValues() []string {
+	return []string{string(ArrowNone), string(ArrowStart), string(ArrowEnd), string(ArrowBoth)}
+}
+
+// Valid reports whether s is a known ArrowStyle.
+func (s ArrowStyle) Valid() bool {
+	switch s {
+	case ArrowNone, ArrowStart, ArrowEnd, ArrowBoth:
+		return true
+	}
+	return false
+}
+
+// Connector is a connection point of a blueprint, which edges are drawn to
+// and from like nodes, see NewConnector. A connector relative to a node
+// moves with it.
+type Connector struct {
+	Id string
+
+	// Color of the connector, a hex color such as "#000000", the default
+	// of the editor if empty.
+	Color string
+
+	// Arrow sets the arrowheads of the edges drawn to the connector, none
+	// if empty.
+	Arrow ArrowStyle
+
+	MapPos MapPos
+}
+
+func (d Connector) String() string {
+	return Stringify(d)
+}
+
+// NewConnector returns a Connector at pos.
+func NewConnector(pos MapPos) *Connector {
+	return &Connector{MapPos: pos}
+}
+
+// WithArrow sets the arrowheads of the connector and returns it.
+func (c *Connector) WithArrow(style ArrowStyle) *Connector {
+	c.Arrow = style
+	return c
+}
+
+// validate checks the Connector can be added to data.
+func (c *Connector) validate(data *BlueprintData) error {
+	if c == nil {
+		return NewArgError("connector", "cannot be nil")
+	}
+
+	if c.Color != "" && !isHexColor(c.Color) {
+		return NewArgError("connector.Color", "must be a hex color such as #000000")
+	}
+	if c.Arrow != "" && !c.Arrow.Valid() {
+		return NewArgError("connector.Arrow", oneOf(ArrowStyleValues()))
+	}
+	if c.MapPos.RelTo != "" && c.MapPos.RelTo == c.Id {
+		return NewArgError("connector.MapPos.RelTo", "cannot be the connector itself")
+	}
+	return c.MapPos.validate("connector.MapPos", data)
+}
+
+// element returns the connector element of the blueprint data.
+func (c *Connector) element() map[string]interface{} {
+	e := map[string]interface{}{
+		"id":     c.Id,
+		"type":   "connector",
+		"mapPos": c.MapPos.value(),
+	}
+	if c.Color != "" {
+		e["color"] = c.Color
+	}
+	if c.Arrow != "" && c.Arrow != ArrowNone {
+		e["arrow"] = string(c.Arrow)
+	}
+	return e
+}
+
+// hasElement reports whether an element of the blueprint has the given id.
+func (d *BlueprintData) hasElement(id string) bool {
+	for _, collection := range [][]map[string]interface{}{
+		d.Nodes, d.Groups, d.Text, d.Icons, d.Images, d.Surfaces, d.Connectors,
+	} {
+		for _, element := range collection {
+			if element["id"] == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// upsert replaces the element of the same id in collection, or appends it.
+func upsert(collection []map[string]interface{}, e map[string]interface{}) []map[string]interface{} {
+	for i, existing := range collection {
+		if existing["id"] == e["id"] {
+			collection[i] = e
+			return collection
+		}
+	}
+	return append(collection, e)
+}
+
+// remove removes the element of the given id from collection, reporting
+// whether it existed.
+func remove(collection []map[string]interface{}, id string) ([]map[string]interface{}, bool) {
+	for i, existing := range collection {
+		if existing["id"] == id {
+			return append(collection[:i], collection[i+1:]...), true
+		}
+	}
+	return collection, false
+}
+
+// AddText adds the text to the blueprint, or replaces the text of the same
+// id. An id is generated for texts without one. The element a text is
+// relative to must exist. It returns the id of the text.
+func (d *BlueprintData) AddText(text *Text) (string, error) {
+	if err := text.validate(d); err != nil {
+		return "", err
+	}
+
+	t := *text
+	if t.Id == "" {
+		id, err := newUUID()
+		if err != nil {
+			return "", err
+		}
+		t.Id = id
+	}
+
+	d.Text = upsert(d.Text, t.element())
+	return t.Id, nil
+}
+
+// RemoveText removes the text of the given id. It reports whether the text
+// existed.
+func (d *BlueprintData) RemoveText(id string) bool {
+	var ok bool
+	d.Text, ok = remove(d.Text, id)
+	return ok
+}
+
+// Texts returns the texts of the blueprint.
+func (d *BlueprintData) Texts() []Text {
+	texts := make([]Text, 0, len(d.Text))
+	for _, e := range d.Text {
+		t := Text{MapPos: parseMapPos(e["mapPos"])}
+		t.Id, _ = e["id"].(string)
+		t.Text, _ = e["text"].(string)
+		t.Color, _ = e["color"].(string)
+		t.Direction, _ = e["direction"].(string)
+		t.Isometric, _ = e["isometric"].(bool)
+		if size, ok := e["textSize"].(float64); ok {
+			t.TextSize = int(size)
+		} else if size, ok := e["textSize"].(int); ok {
+			t.TextSize = size
+		}
+		texts = append(texts, t)
+	}
+	return texts
+}
+
+// AddConnector adds the connector to the blueprint, or replaces the
+// connector of the same id. An id is generated for connectors without one.
+// The element a connector is relative to must exist. It returns the id of
+// the connector.
+func (d *BlueprintData) AddConnector(connector *Connector) (string, error) {
+	if err := connector.validate(d); err != nil {
+		return "", err
+	}
+
+	c := *connector
+	if c.Id == "" {
+		id, err := newUUID()
+		if err != nil {
+			return "", err
+		}
+		c.Id = id
+	}
+
+	d.Connectors = upsert(d.Connectors, c.element())
+	return c.Id, nil
+}
+
+// RemoveConnector removes the connector of the given id. It reports whether
+// the connector existed. The edges drawn to it are left to the caller.
+func (d *BlueprintData) RemoveConnector(id string) bool {
+	var ok bool
+	d.Connectors, ok = remove(d.Connectors, id)
+	return ok
+}
+
+// ConnectorElements returns the connectors of the blueprint.
+func (d *BlueprintData) ConnectorElements() []Connector {
+	connectors := make([]Connector, 0, len(d.Connectors))
+	for _, e := range d.Connectors {
+		c := Connector{MapPos: parseMapPos(e["mapPos"])}
+		c.Id, _ = e["id"].(string)
+		c.Color, _ = e["color"].(string)
+		if arrow, ok := e["arrow"].(string); ok {
+			c.Arrow = ArrowStyle(arrow)
+		}
+		connectors = append(connectors, c)
+	}
+	return connectors
+}
@@ -1,13 +1,35 @@
 package cloudcraft
 
 import (
+	"bytes"
+	"fmt"
+	"math"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// timestampLayouts are the string formats accepted by Timestamp, tried in
+// order.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// unixMillisThreshold separates Unix timestamps in seconds from timestamps in
+// milliseconds: 1e11 seconds is in the year 5138.
+const unixMillisThreshold = 1e11
+
 // Timestamp represents a time that can be unmarshalled from a JSON string
 // formatted as either an RFC3339 or Unix timestamp. All
 // exported methods of time.Time can be called on Timestamp.
+//
+// Unmarshalling is tolerant to the formats returned by the API: RFC 3339
+// with or without fractional seconds or time zone, Unix timestamps in seconds
+// or milliseconds, as numbers or strings. null and "" leave the zero time.
 type Timestamp struct {
 	time.Time
 }
@@ -19,14 +41,62 @@ func (t Timestamp) String() string {
 // UnmarshalJSON implements the json.Unmarshaler interface.
 // Time is expected in RFC3339 or Unix format.
 func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		t.Time = time.Time{}
+		return nil
+	}
+
 	str := string(data)
-	i, err := strconv.ParseInt(str, 10, 64)
-	if err == nil {
-		t.Time = time.Unix(i, 0)
-	} else {
-		t.Time, err = time.Parse(`"`+time.RFC3339+`"`, str)
+	if len(str) >= 2 && str[0] == '"' && str[len(str)-1] == '"' {
+		unquoted, err := strconv.Unquote(str)
+		if err != nil {
+			return fmt.Errorf("cloudcraft: invalid timestamp %s: %w", str, err)
+		}
+		str = strings.TrimSpace(unquoted)
 	}
-	return err
+
+	if str == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+
+	if f, err := strconv.ParseFloat(str, 64); err == nil {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("cloudcraft: invalid timestamp %s", string(data))
+		}
+		t.Time = unixTime(f)
+		return nil
+	}
+
+	for _, layout := range timestampLayouts {
+		if parsed, err := time.Parse(layout, str); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+
+	return fmt.Errorf("cloudcraft: invalid timestamp %s", string(data))
+}
+
+// MarshalJSON implements the json.Marshaler interface. The zero time is
+// marshalled as null, other times in RFC3339 format.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(`"` + t.Time.Format(time.RFC3339Nano) + `"`), nil
+}
+
+// unixTime converts a Unix timestamp in seconds or milliseconds to a time.
+func unixTime(f float64) time.Time {
+	if f >= unixMillisThreshold || f <= -unixMillisThreshold {
+		ms := int64(f)
+		return time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond))
+	}
+
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*float64(time.Second)))
 }
 
 // Equal reports whether t and u are equal based on time.Equal
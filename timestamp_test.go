@@ -0,0 +1,122 @@
+package cloudcraft
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimestampUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want time.Time
+	}{
+		{"RFC3339", `"2021-03-04T05:06:07Z"`, time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)},
+		{"RFC3339 with offset", `"2021-03-04T07:06:07+02:00"`, time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)},
+		{"fractional seconds", `"2021-03-04T05:06:07.123Z"`, time.Date(2021, 3, 4, 5, 6, 7, 123000000, time.UTC)},
+		{"no zone", `"2021-03-04T05:06:07"`, time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)},
+		{"no zone with fractional seconds", `"2021-03-04T05:06:07.5"`, time.Date(2021, 3, 4, 5, 6, 7, 500000000, time.UTC)},
+		{"space separator", `"2021-03-04 05:06:07"`, time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)},
+		{"date", `"2021-03-04"`, time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{"unix seconds", `1614834367`, time.Unix(1614834367, 0)},
+		{"unix seconds with fraction", `1614834367.25`, time.Unix(1614834367, 250000000)},
+		{"unix milliseconds", `1614834367123`, time.Unix(1614834367, 123000000)},
+		{"unix seconds as string", `"1614834367"`, time.Unix(1614834367, 0)},
+		{"unix milliseconds as string", `"1614834367123"`, time.Unix(1614834367, 123000000)},
+		{"null", `null`, time.Time{}},
+		{"empty string", `""`, time.Time{}},
+		{"blank string", `"  "`, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := Timestamp{Time: time.Now()}
+			if err := json.Unmarshal([]byte(tt.json), &ts); err != nil {
+				t.Fatalf("Unmarshal(%s) returned error: %v", tt.json, err)
+			}
+			if !ts.Time.Equal(tt.want) {
+				t.Errorf("Unmarshal(%s) = %v, want %v", tt.json, ts.Time, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimestampUnmarshalJSONInvalid(t *testing.T) {
+	for _, data := range []string{
+		`"yesterday"`,
+		`"NaN"`,
+		`"Inf"`,
+		`"-Infinity"`,
+		`"2021-13-45T00:00:00Z"`,
+		`true`,
+		`{}`,
+	} {
+		var ts Timestamp
+		if err := json.Unmarshal([]byte(data), &ts); err == nil {
+			t.Errorf("Unmarshal(%s) = %v, want error", data, ts.Time)
+		}
+	}
+}
+
+func TestTimestampMarshalJSON(t *testing.T) {
+	tests := []struct {
+		ts   Timestamp
+		want string
+	}{
+		{Timestamp{}, `null`},
+		{Timestamp{time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)}, `"2021-03-04T05:06:07Z"`},
+	}
+
+	for _, tt := range tests {
+		got, err := json.Marshal(tt.ts)
+		if err != nil {
+			t.Fatalf("Marshal(%v) returned error: %v", tt.ts, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("Marshal(%v) = %s, want %s", tt.ts, got, tt.want)
+		}
+	}
+}
+
+var (
+	fixtureCreatedAt = time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	fixtureUpdatedAt = time.Date(2022, 6, 7, 8, 9, 10, 0, time.UTC)
+)
+
+const fixtureTimestamps = `"createdAt": "2021-01-02T03:04:05Z", "updatedAt": 1654589350`
+
+func checkTimestamps(t *testing.T, kind string, createdAt, updatedAt Timestamp) {
+	t.Helper()
+
+	if !createdAt.Equal(Timestamp{fixtureCreatedAt}) {
+		t.Errorf("%s.CreatedAt = %v, want %v", kind, createdAt, fixtureCreatedAt)
+	}
+	if !updatedAt.Equal(Timestamp{fixtureUpdatedAt}) {
+		t.Errorf("%s.UpdatedAt = %v, want %v", kind, updatedAt, fixtureUpdatedAt)
+	}
+}
+
+func TestAwsAccountTimestamps(t *testing.T) {
+	var account AwsAccount
+	if err := json.Unmarshal([]byte(`{"id": "a", `+fixtureTimestamps+`}`), &account); err != nil {
+		t.Fatal(err)
+	}
+	checkTimestamps(t, "AwsAccount", account.CreatedAt, account.UpdatedAt)
+}
+
+func TestBlueprintTimestamps(t *testing.T) {
+	var blueprint Blueprint
+	if err := json.Unmarshal([]byte(`{"id": "b", `+fixtureTimestamps+`}`), &blueprint); err != nil {
+		t.Fatal(err)
+	}
+	checkTimestamps(t, "Blueprint", blueprint.CreatedAt, blueprint.UpdatedAt)
+}
+
+func TestUserTimestamps(t *testing.T) {
+	var user User
+	if err := json.Unmarshal([]byte(`{"id": "u", `+fixtureTimestamps+`}`), &user); err != nil {
+		t.Fatal(err)
+	}
+	checkTimestamps(t, "User", user.CreatedAt, user.UpdatedAt)
+}
@@ -0,0 +1,138 @@
+package cloudcraft
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// transport returns the http.Transport of the client, ready to be
+// configured. The transport and http.Client given to NewClient or used by
+// default are cloned rather than modified, as they may be shared. Other
+// Doers set with SetDoer have no transport to configure.
+func (c *Client) transport() (*http.Transport, error) {
+	if c.ownTransport != nil {
+		return c.ownTransport, nil
+	}
+
+	hc, ok := c.client.(*http.Client)
+	if !ok {
+		return nil, errors.New("cloudcraft: transport options require an *http.Client")
+	}
+
+	var t *http.Transport
+	switch rt := hc.Transport.(type) {
+	case nil:
+		t = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		t = rt.Clone()
+	default:
+		return nil, errors.New("cloudcraft: transport options require an *http.Transport")
+	}
+
+	client := *hc
+	client.Transport = t
+	c.client = &client
+	c.ownTransport = t
+
+	return t, nil
+}
+
+// SetTLSConfig is a client option for the TLS configuration of the
+// connections to the API.
+func SetTLSConfig(cfg *tls.Config) ClientOpt {
+	return func(c *Client) error {
+		if cfg == nil {
+			return NewArgError("cfg", "cannot be nil")
+		}
+
+		t, err := c.transport()
+		if err != nil {
+			return err
+		}
+
+		t.TLSClientConfig = cfg.Clone()
+		return nil
+	}
+}
+
+// SetRootCAs is a client option trusting the PEM encoded certificates of
+// pemCerts, such as a corporate CA bundle, in addition to the system ones.
+func SetRootCAs(pemCerts []byte) ClientOpt {
+	return func(c *Client) error {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pemCerts) {
+			return NewArgError("pemCerts", "contains no certificate")
+		}
+
+		t, err := c.transport()
+		if err != nil {
+			return err
+		}
+
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		t.TLSClientConfig.RootCAs = pool
+		return nil
+	}
+}
+
+// SetDialTimeout is a client option limiting the time spent establishing a
+// connection to the API.
+func SetDialTimeout(d time.Duration) ClientOpt {
+	return func(c *Client) error {
+		if d <= 0 {
+			return NewArgError("d", "must be positive")
+		}
+
+		t, err := c.transport()
+		if err != nil {
+			return err
+		}
+
+		dialer := &net.Dialer{Timeout: d, KeepAlive: 30 * time.Second}
+		t.DialContext = dialer.DialContext
+		return nil
+	}
+}
+
+// SetProxy is a client option sending the requests through the proxy at
+// proxyURL instead of the one configured by the environment.
+func SetProxy(proxyURL string) ClientOpt {
+	return func(c *Client) error {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return err
+		}
+
+		t, err := c.transport()
+		if err != nil {
+			return err
+		}
+
+		t.Proxy = http.ProxyURL(u)
+		return nil
+	}
+}
+
+// SetForceHTTP2 is a client option attempting HTTP/2 even when a custom TLS
+// configuration or dialer is set, which otherwise disables it.
+func SetForceHTTP2() ClientOpt {
+	return func(c *Client) error {
+		t, err := c.transport()
+		if err != nil {
+			return err
+		}
+
+		t.ForceAttemptHTTP2 = true
+		return nil
+	}
+}
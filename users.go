@@ -4,22 +4,39 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 )
 
-const userBasePath = "user"
+const (
+	userBasePath         = "user"
+	organizationBasePath = "organization"
+	invitationBasePath   = "invitation"
+)
 
 // UsersService is an interface for interfacing with the Users
 // endpoints of the Cloudcraft API
 // See: https://developers.cloudcraft.co/#398fa0e6-3139-41e6-a5c2-3b9a31e15d6d
 type UsersService interface {
-	Me(context.Context) (*User, *Response, error)
+	List(context.Context, *UserListOptions, ...RequestOpt) ([]User, *Response, error)
+	Get(context.Context, string, ...RequestOpt) (*User, *Response, error)
+	Me(context.Context, ...RequestOpt) (*User, *Response, error)
+	RefreshMe(context.Context, ...RequestOpt) (*User, *Response, error)
+	Organization(context.Context, ...RequestOpt) (*Organization, *Response, error)
+	Invite(context.Context, string, Role, []string, ...RequestOpt) (*Invitation, *Response, error)
+	ListInvitations(context.Context, ...RequestOpt) ([]Invitation, *Response, error)
+	CancelInvitation(context.Context, string, ...RequestOpt) (*Response, error)
 }
 
 // UsersServiceOp handles communication with the User related methods of the
 // Cloudcraft API.
 type UsersServiceOp struct {
 	client *Client
+
+	// Cached result of Me, see SetMeCacheTTL.
+	meMu        sync.Mutex
+	me          *User
+	meFetchedAt time.Time
 }
 
 var _ UsersService = &UsersServiceOp{}
@@ -28,10 +45,16 @@ var _ UsersService = &UsersServiceOp{}
 type User struct {
 	ID         string    `json:"id,omitempty"`
 	Name       string    `json:"name,omitempty"`
-	CreatedAt  time.Time `json:"createdAt,omitempty"`
-	UpdatedAt  time.Time `json:"createdAt,omitempty"`
+	Role       Role      `json:"role,omitempty"`
+	CreatedAt  Timestamp `json:"createdAt,omitempty"`
+	UpdatedAt  Timestamp `json:"updatedAt,omitempty"`
 	CreatorId  string    `json:"CreatorId,omitempty"`
 	LastUserId string    `json:"LastUserId,omitempty"`
+
+	// Teams the user belongs to and time of their last activity, when
+	// returned by the API.
+	TeamIds      []string  `json:"teamIds,omitempty"`
+	LastActiveAt Timestamp `json:"lastActiveAt,omitempty"`
 }
 
 // Convert User to a string
@@ -39,13 +62,152 @@ func (d User) String() string {
 	return Stringify(d)
 }
 
-// Get an individual user. Currently only "me" supported.
-func (s *UsersServiceOp) Get(ctx context.Context, userID string) (*User, *Response, error) {
+// OrganizationSeats describes the seat usage of an Organization
+type OrganizationSeats struct {
+	Total int `json:"total"`
+	Used  int `json:"used"`
+}
+
+// Organization represents the Cloudcraft organization a User belongs to
+type Organization struct {
+	Id        string                 `json:"id,omitempty"`
+	Name      string                 `json:"name,omitempty"`
+	Plan      string                 `json:"plan,omitempty"`
+	Seats     OrganizationSeats      `json:"seats,omitempty"`
+	Settings  map[string]interface{} `json:"settings,omitempty"`
+	CreatedAt Timestamp              `json:"createdAt,omitempty"`
+	UpdatedAt Timestamp              `json:"updatedAt,omitempty"`
+}
+
+// Convert Organization to a string
+func (d Organization) String() string {
+	return Stringify(d)
+}
+
+// Invitation represents a pending invitation to join the organization
+type Invitation struct {
+	Id        string    `json:"id,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	Role      Role      `json:"role,omitempty"`
+	TeamIds   []string  `json:"teamIds,omitempty"`
+	CreatorId string    `json:"CreatorId,omitempty"`
+	CreatedAt Timestamp `json:"createdAt,omitempty"`
+	ExpiresAt Timestamp `json:"expiresAt,omitempty"`
+}
+
+// Convert Invitation to a string
+func (d Invitation) String() string {
+	return Stringify(d)
+}
+
+type InvitationsRoot struct {
+	Invitations []Invitation `json:"invitations"`
+}
+
+type invitationRequest struct {
+	Email   string   `json:"email"`
+	Role    Role     `json:"role,omitempty"`
+	TeamIds []string `json:"teamIds,omitempty"`
+}
+
+type UsersRoot struct {
+	Users []User `json:"users"`
+	Meta  *Meta  `json:"meta,omitempty"`
+}
+
+// UserListOptions specifies the optional parameters to UsersService.List.
+// The filters are sent to the API and applied again to the users it lists,
+// for the users carrying the filtered field, so filtering works whether or
+// not the API supports it. Pages may then hold fewer users than PerPage.
+type UserListOptions struct {
+	ListOptions
+
+	// Restrict users to a role.
+	Role Role
+
+	// Restrict users to the members of a team.
+	TeamId string
+
+	// Restrict users to those active since a time.
+	ActiveSince time.Time
+}
+
+// match reports whether user passes the filters of o.
+func (o *UserListOptions) match(user *User) bool {
+	if o.Role != "" && user.Role != "" && user.Role != o.Role {
+		return false
+	}
+
+	if o.TeamId != "" && user.TeamIds != nil {
+		member := false
+		for _, id := range user.TeamIds {
+			if id == o.TeamId {
+				member = true
+				break
+			}
+		}
+		if !member {
+			return false
+		}
+	}
+
+	if !o.ActiveSince.IsZero() && !user.LastActiveAt.IsZero() && user.LastActiveAt.Before(o.ActiveSince) {
+		return false
+	}
+	return true
+}
+
+// List the Users of the organization the API key belongs to.
+func (s *UsersServiceOp) List(ctx context.Context, opt *UserListOptions, opts ...RequestOpt) ([]User, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if opt != nil {
+		if err := ValidateRole("opt.Role", opt.Role); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	path, err := addQuery(userBasePath, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := new(UsersRoot)
+	resp, err := s.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, resp, err
+	}
+	resp.Meta = root.Meta
+
+	users := root.Users
+	if opt != nil {
+		users = users[:0]
+		for _, user := range root.Users {
+			if opt.match(&user) {
+				users = append(users, user)
+			}
+		}
+	}
+
+	return users, resp, err
+}
+
+// Get an individual user by id, "me" being the user the API key belongs to.
+func (s *UsersServiceOp) Get(ctx context.Context, userID string, opts ...RequestOpt) (*User, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
 	if userID == "" {
 		return nil, nil, NewArgError("userID", "cannot be empty")
 	}
 
-	path := fmt.Sprintf("%s/%d", userBasePath, userID)
+	path := fmt.Sprintf("%s/%s", userBasePath, userID)
 
 	req, err := s.client.NewRequest(ctx, http.MethodGet, path, nil)
 	if err != nil {
@@ -61,6 +223,134 @@ func (s *UsersServiceOp) Get(ctx context.Context, userID string) (*User, *Respon
 	return user, resp, err
 }
 
-func (s *UsersServiceOp) Me(ctx context.Context) (*User, *Response, error) {
-	return s.Get(ctx, "me")
+// Me gets the User the API key belongs to. If the client was created with
+// SetMeCacheTTL, the User is memoized for the configured duration and an
+// empty 200 OK Response is returned when it is served from the cache.
+func (s *UsersServiceOp) Me(ctx context.Context, opts ...RequestOpt) (*User, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	ttl := s.client.meCacheTTL
+	if ttl <= 0 {
+		return s.Get(ctx, "me")
+	}
+
+	s.meMu.Lock()
+	if s.me != nil && time.Since(s.meFetchedAt) < ttl {
+		user := *s.me
+		s.meMu.Unlock()
+		return &user, staleResponse(nil, http.StatusOK, "", 0, nil), nil
+	}
+	s.meMu.Unlock()
+
+	return s.RefreshMe(ctx)
+}
+
+// RefreshMe gets the User the API key belongs to, bypassing and updating the
+// cache used by Me.
+func (s *UsersServiceOp) RefreshMe(ctx context.Context, opts ...RequestOpt) (*User, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	user, resp, err := s.Get(ctx, "me")
+	if err != nil {
+		return nil, resp, err
+	}
+
+	cached := *user
+	s.meMu.Lock()
+	s.me = &cached
+	s.meFetchedAt = time.Now()
+	s.meMu.Unlock()
+
+	return user, resp, err
+}
+
+// Organization gets the Organization of the User the API key belongs to,
+// including its plan, seat counts and organization-level settings.
+func (s *UsersServiceOp) Organization(ctx context.Context, opts ...RequestOpt) (*Organization, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, organizationBasePath, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	organization := new(Organization)
+	resp, err := s.client.Do(ctx, req, organization)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return organization, resp, err
+}
+
+// Invite a new member to the organization by email, with the given role and
+// optional list of teams to join.
+func (s *UsersServiceOp) Invite(ctx context.Context, email string, role Role, teamIDs []string, opts ...RequestOpt) (*Invitation, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if email == "" {
+		return nil, nil, NewArgError("email", "cannot be empty")
+	}
+
+	if err := ValidateRole("role", role); err != nil {
+		return nil, nil, err
+	}
+
+	inviteRequest := &invitationRequest{Email: email, Role: role, TeamIds: teamIDs}
+
+	req, err := s.client.NewRequest(ctx, http.MethodPost, invitationBasePath, inviteRequest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	invitation := new(Invitation)
+	resp, err := s.client.Do(ctx, req, invitation)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return invitation, resp, err
+}
+
+// ListInvitations lists the pending invitations of the organization.
+func (s *UsersServiceOp) ListInvitations(ctx context.Context, opts ...RequestOpt) ([]Invitation, *Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	req, err := s.client.NewRequest(ctx, http.MethodGet, invitationBasePath, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	root := new(InvitationsRoot)
+	resp, err := s.client.Do(ctx, req, root)
+	if err != nil {
+		return nil, resp, err
+	}
+	return root.Invitations, resp, err
+}
+
+// CancelInvitation cancels a pending invitation.
+func (s *UsersServiceOp) CancelInvitation(ctx context.Context, invitationID string, opts ...RequestOpt) (*Response, error) {
+	ctx, cancel := ApplyRequestOpts(ctx, opts...)
+	defer cancel()
+
+	if invitationID == "" {
+		return nil, NewArgError("invitationID", "cannot be empty")
+	}
+
+	path := fmt.Sprintf("%s/%s", invitationBasePath, invitationID)
+
+	req, err := s.client.NewRequest(ctx, http.MethodDelete, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(ctx, req, nil)
+
+	return resp, err
 }
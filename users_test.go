@@ -0,0 +1,43 @@
+package cloudcraft
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUsersMeCached(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", mediaType)
+		w.Write([]byte(`{"id": "u", "name": "Ada"}`))
+	}))
+	defer server.Close()
+
+	client, err := New(nil, SetBaseURL(server.URL+"/"), SetMeCacheTTL(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		user, resp, err := client.Users.Me(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.ID != "u" {
+			t.Errorf("Me() user = %v, want u", user)
+		}
+		if resp == nil || resp.StatusCode != http.StatusOK {
+			t.Errorf("Me() response = %v, want 200 OK", resp)
+		}
+		if _, stale := resp.Stale(); stale {
+			t.Error("Me() response is stale")
+		}
+	}
+	if requests != 1 {
+		t.Errorf("%d requests, want 1", requests)
+	}
+}
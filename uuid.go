@@ -0,0 +1,19 @@
+package cloudcraft
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// newUUID returns a random version 4 UUID, identifying new elements of
+// blueprints and requests.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("cloudcraft: generating id: %w", err)
+	}
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
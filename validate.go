@@ -0,0 +1,138 @@
+package cloudcraft
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Bounds of the size parameters of exports and snapshots. Zero leaves the
+// size to the API.
+const (
+	maxImageDimension = 10000
+	maxImageScale     = 10
+)
+
+// oneOf returns the message of an ArgError listing the allowed values.
+func oneOf(values []string) string {
+	return "must be one of " + strings.Join(values, ", ")
+}
+
+// validate checks exportRequest before it is sent.
+func (r *BlueprintExportRequest) validate() error {
+	if !ExportFormat(r.Format).Valid() {
+		return NewArgError("exportRequest.Format", oneOf(ExportFormatValues()))
+	}
+
+	return r.ExportParameters.validate(r.Format)
+}
+
+// validate checks the parameters of an export in format.
+func (p *BlueprintExportParameters) validate(format string) error {
+	if p == nil {
+		return nil
+	}
+
+	const prefix = "exportRequest.ExportParameters."
+	if err := validateImageSize(prefix, p.Width, p.Height, p.Scale); err != nil {
+		return err
+	}
+	if err := validatePaperSize(prefix, format, p.PaperSize); err != nil {
+		return err
+	}
+
+	if p.Theme != "" && !p.Theme.Valid() {
+		return NewArgError(prefix+"Theme", oneOf(ExportThemeValues()))
+	}
+	if p.Projection != "" && !p.Projection.Valid() {
+		return NewArgError(prefix+"Projection", oneOf(ProjectionValues()))
+	}
+	if p.Background != "" && !isHexColor(p.Background) {
+		return NewArgError(prefix+"Background", "must be a hex color such as #ffffff")
+	}
+	return nil
+}
+
+// validate checks snapshotRequest before it is sent.
+func (r *AwsAccountSnapshotRequest) validate() error {
+	if !SnapshotFormat(r.Format).Valid() {
+		return NewArgError("snapshotRequest.Format", oneOf(SnapshotFormatValues()))
+	}
+
+	if r.Region == "" {
+		return NewArgError("snapshotRequest.Region", "cannot be empty")
+	}
+
+	p := r.SnapshotParameters
+	if p == nil {
+		return nil
+	}
+
+	const prefix = "snapshotRequest.SnapshotParameters."
+	if err := validateImageSize(prefix, p.Width, p.Height, p.Scale); err != nil {
+		return err
+	}
+	if err := validatePaperSize(prefix, r.Format, p.PaperSize); err != nil {
+		return err
+	}
+
+	if p.Projection != "" && !Projection(p.Projection).Valid() {
+		return NewArgError(prefix+"Projection", oneOf(ProjectionValues()))
+	}
+	return nil
+}
+
+// validate checks budgetRequest before it is sent.
+func (r *BlueprintBudgetRequest) validate() error {
+	if !BudgetFormat(r.Format).Valid() {
+		return NewArgError("budgetRequest.Format", oneOf(BudgetFormatValues()))
+	}
+
+	p := r.BudgetParameters
+	if p != nil && p.Period != "" && !BudgetPeriod(p.Period).Valid() {
+		return NewArgError("budgetRequest.BudgetParameters.Period", oneOf(BudgetPeriodValues()))
+	}
+	return nil
+}
+
+// validateImageSize checks the size parameters of an export or snapshot.
+func validateImageSize(prefix string, width, height int, scale float32) error {
+	if width < 0 || width > maxImageDimension {
+		return NewArgError(prefix+"Width", fmt.Sprintf("must be between 0 and %d pixels", maxImageDimension))
+	}
+	if height < 0 || height > maxImageDimension {
+		return NewArgError(prefix+"Height", fmt.Sprintf("must be between 0 and %d pixels", maxImageDimension))
+	}
+	if scale < 0 || scale > maxImageScale {
+		return NewArgError(prefix+"Scale", fmt.Sprintf("must be between 0 and %d", maxImageScale))
+	}
+	return nil
+}
+
+// validatePaperSize checks paperSize is a known size and format a PDF.
+func validatePaperSize(prefix, format, paperSize string) error {
+	if paperSize == "" {
+		return nil
+	}
+
+	if format != string(ExportFormatPdf) {
+		return NewArgError(prefix+"PaperSize", "only applies to the pdf format")
+	}
+	if !PaperSize(paperSize).Valid() {
+		return NewArgError(prefix+"PaperSize", oneOf(PaperSizeValues()))
+	}
+	return nil
+}
+
+// isHexColor reports whether s is a color in the #rgb or #rrggbb notation.
+func isHexColor(s string) bool {
+	if len(s) != 4 && len(s) != 7 || s[0] != '#' {
+		return false
+	}
+
+	for _, c := range s[1:] {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package cloudcraft
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"time"
+)
+
+// maxWatchBackoff bounds the interval between polls of a failing Watch.
+const maxWatchBackoff = 5 * time.Minute
+
+// BlueprintWatchEvent is sent by Blueprints.Watch when the watched Blueprint
+// changes or cannot be polled.
+type BlueprintWatchEvent struct {
+	// The new revision of the Blueprint, nil if Deleted or Err is set.
+	Blueprint *Blueprint
+
+	// Deleted reports the Blueprint no longer exists. It is the last event.
+	Deleted bool
+
+	// Err is the error of a failed poll. Polling goes on with a backoff.
+	Err error
+}
+
+// Watch polls a Blueprint every interval and sends an event each time it
+// changes, starting from its state when Watch is called. Revisions are told
+// apart by their updatedAt, or their content when the API omits it, so the
+// same revision is never sent twice. Failed polls are sent as events with
+// Err set and retried with an exponential backoff. The channel is closed
+// when ctx is done or the Blueprint is deleted.
+func (s *BlueprintsServiceOp) Watch(ctx context.Context, blueprintId string, interval time.Duration, opts ...RequestOpt) (<-chan BlueprintWatchEvent, error) {
+	if blueprintId == "" {
+		return nil, NewArgError("blueprintId", "cannot be empty")
+	}
+
+	if interval <= 0 {
+		return nil, NewArgError("interval", "must be positive")
+	}
+
+	blueprint, _, err := s.Get(ctx, blueprintId, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan BlueprintWatchEvent)
+	go s.watch(ctx, blueprintId, interval, revisionOf(blueprint), events, opts)
+	return events, nil
+}
+
+func (s *BlueprintsServiceOp) watch(ctx context.Context, blueprintId string, interval time.Duration, revision string, events chan<- BlueprintWatchEvent, opts []RequestOpt) {
+	defer close(events)
+
+	send := func(event BlueprintWatchEvent) bool {
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	wait := interval
+	for {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		blueprint, _, err := s.Get(ctx, blueprintId, opts...)
+		switch {
+		case ctx.Err() != nil:
+			return
+
+		case IsNotFound(err):
+			send(BlueprintWatchEvent{Deleted: true})
+			return
+
+		case err != nil:
+			if !send(BlueprintWatchEvent{Err: err}) {
+				return
+			}
+			if wait *= 2; wait > maxWatchBackoff {
+				wait = maxWatchBackoff
+			}
+			if wait < interval {
+				wait = interval
+			}
+			continue
+		}
+
+		wait = interval
+		if r := revisionOf(blueprint); r != revision {
+			revision = r
+			if !send(BlueprintWatchEvent{Blueprint: blueprint}) {
+				return
+			}
+		}
+	}
+}
+
+// revisionOf returns a key identifying the revision of blueprint.
+func revisionOf(blueprint *Blueprint) string {
+	if !blueprint.UpdatedAt.IsZero() {
+		return blueprint.UpdatedAt.String()
+	}
+
+	content, _ := json.Marshal(blueprint.Data)
+	sum := sha256.Sum256(content)
+	return string(sum[:])
+}
@@ -0,0 +1,193 @@
+// Package webhook parses and authenticates the event notifications Cloudcraft
+// delivers to webhook receivers for changes of blueprints and AWS accounts.
+//
+// Deliveries are JSON encoded Events sent with POST. Their body is signed
+// with HMAC-SHA256 using the secret of the webhook, the hex encoded
+// signature being sent in the X-Cloudcraft-Signature header as
+// "sha256=<signature>".
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/updater/cloudcraft-go"
+)
+
+// SignatureHeader is the header holding the signature of a delivery.
+const SignatureHeader = "X-Cloudcraft-Signature"
+
+// maxPayloadSize is the size of the largest delivery accepted by Handler.
+const maxPayloadSize = 5 << 20
+
+// ErrInvalidSignature is returned when a delivery is not signed with the
+// secret of the webhook.
+var ErrInvalidSignature = errors.New("webhook: invalid signature")
+
+// ErrEmptySecret is returned when one of the secrets a delivery is verified
+// with is empty, as when read from an unset environment variable: anyone
+// can sign with an empty secret.
+var ErrEmptySecret = errors.New("webhook: secret cannot be empty")
+
+// EventType identifies what an Event notifies.
+type EventType string
+
+const (
+	BlueprintCreated  EventType = "blueprint.created"
+	BlueprintUpdated  EventType = "blueprint.updated"
+	BlueprintDeleted  EventType = "blueprint.deleted"
+	AwsAccountCreated EventType = "awsAccount.created"
+	AwsAccountUpdated EventType = "awsAccount.updated"
+	AwsAccountDeleted EventType = "awsAccount.deleted"
+)
+
+// Event is a notification delivered to a webhook. Data holds the resource
+// the event is about, decoded with Blueprint or AwsAccount depending on
+// Type.
+type Event struct {
+	Id        string               `json:"id"`
+	Type      EventType            `json:"type"`
+	CreatedAt cloudcraft.Timestamp `json:"createdAt,omitempty"`
+	ActorId   string               `json:"actorId,omitempty"`
+	Data      json.RawMessage      `json:"data"`
+}
+
+func (e Event) String() string {
+	return cloudcraft.Stringify(e)
+}
+
+// Blueprint decodes the blueprint of a blueprint event.
+func (e *Event) Blueprint() (*cloudcraft.Blueprint, error) {
+	if !strings.HasPrefix(string(e.Type), "blueprint.") {
+		return nil, fmt.Errorf("webhook: %s event holds no blueprint", e.Type)
+	}
+
+	blueprint := new(cloudcraft.Blueprint)
+	if err := json.Unmarshal(e.Data, blueprint); err != nil {
+		return nil, fmt.Errorf("webhook: decoding blueprint: %w", err)
+	}
+	return blueprint, nil
+}
+
+// AwsAccount decodes the account of an AWS account event.
+func (e *Event) AwsAccount() (*cloudcraft.AwsAccount, error) {
+	if !strings.HasPrefix(string(e.Type), "awsAccount.") {
+		return nil, fmt.Errorf("webhook: %s event holds no AWS account", e.Type)
+	}
+
+	account := new(cloudcraft.AwsAccount)
+	if err := json.Unmarshal(e.Data, account); err != nil {
+		return nil, fmt.Errorf("webhook: decoding AWS account: %w", err)
+	}
+	return account, nil
+}
+
+// Sign returns the signature of payload for the given secret, as sent in
+// SignatureHeader.
+func Sign(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks signature is the signature of payload for one of secrets,
+// several secrets allowing their rotation. It returns ErrInvalidSignature
+// otherwise, and ErrEmptySecret if one of secrets is empty.
+func Verify(payload []byte, signature string, secrets ...[]byte) error {
+	for _, secret := range secrets {
+		if len(secret) == 0 {
+			return ErrEmptySecret
+		}
+	}
+
+	hexSum := strings.TrimPrefix(signature, "sha256=")
+	if hexSum == signature {
+		return ErrInvalidSignature
+	}
+
+	sum, err := hex.DecodeString(hexSum)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	for _, secret := range secrets {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(payload)
+		if hmac.Equal(sum, mac.Sum(nil)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// Parse verifies and decodes a delivery read from r.
+func Parse(r io.Reader, signature string, secrets ...[]byte) (*Event, error) {
+	payload, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: reading payload: %w", err)
+	}
+
+	if err := Verify(payload, signature, secrets...); err != nil {
+		return nil, err
+	}
+
+	event := new(Event)
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, fmt.Errorf("webhook: decoding event: %w", err)
+	}
+	return event, nil
+}
+
+// Handler is an http.Handler receiving the deliveries of a webhook. It
+// answers 401 to deliveries not signed with one of Secrets, 400 to malformed
+// ones, and 500 when OnEvent fails so the delivery is attempted again, or
+// when the Handler is misconfigured, with an empty secret or no OnEvent.
+type Handler struct {
+	Secrets [][]byte
+	OnEvent func(context.Context, *Event) error
+}
+
+var _ http.Handler = &Handler{}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.OnEvent == nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	event, err := Parse(http.MaxBytesReader(w, r.Body, maxPayloadSize), r.Header.Get(SignatureHeader), h.Secrets...)
+	switch {
+	case errors.Is(err, ErrEmptySecret):
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	case errors.Is(err, ErrInvalidSignature):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.OnEvent(r.Context(), event); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const payload = `{"id": "e", "type": "blueprint.updated", "data": {"id": "b"}}`
+
+func TestSignVerify(t *testing.T) {
+	secret := []byte("secret")
+	signature := Sign(secret, []byte(payload))
+
+	if !strings.HasPrefix(signature, "sha256=") {
+		t.Errorf("Sign() = %q, want a sha256= prefix", signature)
+	}
+	if err := Verify([]byte(payload), signature, secret); err != nil {
+		t.Errorf("Verify() of a signed payload returned %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		payload   string
+		signature string
+		secrets   [][]byte
+		want      error
+	}{
+		{"rotated secret", payload, signature, [][]byte{[]byte("new"), secret}, nil},
+		{"other secret", payload, signature, [][]byte{[]byte("other")}, ErrInvalidSignature},
+		{"no secret", payload, signature, nil, ErrInvalidSignature},
+		{"modified payload", payload + " ", signature, [][]byte{secret}, ErrInvalidSignature},
+		{"missing prefix", payload, strings.TrimPrefix(signature, "sha256="), [][]byte{secret}, ErrInvalidSignature},
+		{"not hex", payload, "sha256=zz", [][]byte{secret}, ErrInvalidSignature},
+		{"empty signature", payload, "", [][]byte{secret}, ErrInvalidSignature},
+		{"empty secret", payload, Sign(nil, []byte(payload)), [][]byte{nil}, ErrEmptySecret},
+		{"empty rotated secret", payload, signature, [][]byte{secret, {}}, ErrEmptySecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify([]byte(tt.payload), tt.signature, tt.secrets...); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	secret := []byte("secret")
+	event, err := Parse(strings.NewReader(payload), Sign(secret, []byte(payload)), secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blueprint, err := event.Blueprint()
+	if err != nil || blueprint.Id != "b" {
+		t.Errorf("Blueprint() = %v, %v, want blueprint b", blueprint, err)
+	}
+	if _, err := event.AwsAccount(); err == nil {
+		t.Error("AwsAccount() of a blueprint event returned no error")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	secret := []byte("secret")
+	onEvent := func(context.Context, *Event) error { return nil }
+	failing := func(context.Context, *Event) error { return errors.New("failed") }
+
+	tests := []struct {
+		name      string
+		handler   *Handler
+		method    string
+		body      string
+		signature string
+		want      int
+	}{
+		{"delivered", &Handler{Secrets: [][]byte{secret}, OnEvent: onEvent}, http.MethodPost, payload, Sign(secret, []byte(payload)), http.StatusNoContent},
+		{"GET", &Handler{Secrets: [][]byte{secret}, OnEvent: onEvent}, http.MethodGet, "", "", http.StatusMethodNotAllowed},
+		{"unsigned", &Handler{Secrets: [][]byte{secret}, OnEvent: onEvent}, http.MethodPost, payload, "", http.StatusUnauthorized},
+		{"wrong secret", &Handler{Secrets: [][]byte{secret}, OnEvent: onEvent}, http.MethodPost, payload, Sign([]byte("other"), []byte(payload)), http.StatusUnauthorized},
+		{"malformed", &Handler{Secrets: [][]byte{secret}, OnEvent: onEvent}, http.MethodPost, "{", Sign(secret, []byte("{")), http.StatusBadRequest},
+		{"failing OnEvent", &Handler{Secrets: [][]byte{secret}, OnEvent: failing}, http.MethodPost, payload, Sign(secret, []byte(payload)), http.StatusInternalServerError},
+		{"nil OnEvent", &Handler{Secrets: [][]byte{secret}}, http.MethodPost, payload, Sign(secret, []byte(payload)), http.StatusInternalServerError},
+		{"empty secret", &Handler{Secrets: [][]byte{nil}, OnEvent: onEvent}, http.MethodPost, payload, Sign(nil, []byte(payload)), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			if tt.signature != "" {
+				req.Header.Set(SignatureHeader, tt.signature)
+			}
+
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}